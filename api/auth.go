@@ -0,0 +1,262 @@
+package api
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/guregu/null.v3"
+
+	v1 "go.k6.io/k6/api/v1"
+	"go.k6.io/k6/lib"
+)
+
+// Config is the access control configuration of the REST API server. The
+// zero value disables it, so the API is served over plain HTTP to everyone.
+type Config struct {
+	// Token is the bearer token required for all the requests.
+	Token string
+	// ReadOnlyToken is a bearer token which only grants access to reading the
+	// state of the test, the requests that change it are rejected.
+	ReadOnlyToken string
+
+	// TLSCert and TLSKey are the paths of the PEM-encoded certificate and key
+	// of the server, TLSKeyPassword is the optional password of the key.
+	TLSCert        string
+	TLSKey         string
+	TLSKeyPassword null.String
+	// TLSClientCA is the path of the PEM-encoded certificates of the CAs
+	// used for verifying the client certificates, which are then required.
+	TLSClientCA string
+}
+
+// NewConfig creates the Config from the environment variables.
+func NewConfig(env map[string]string) Config {
+	conf := Config{
+		Token:         env["K6_API_TOKEN"],
+		ReadOnlyToken: env["K6_API_READ_TOKEN"],
+		TLSCert:       env["K6_API_TLS_CERT"],
+		TLSKey:        env["K6_API_TLS_KEY"],
+		TLSClientCA:   env["K6_API_TLS_CLIENT_CA"],
+	}
+	if val, ok := env["K6_API_TLS_KEY_PASSWORD"]; ok {
+		conf.TLSKeyPassword = null.StringFrom(val)
+	}
+	return conf
+}
+
+// TLSConfig returns the TLS configuration of the server, or nil if TLS isn't
+// enabled. The certificate is loaded in the same way as the client
+// certificates in the tlsAuth option.
+func (c Config) TLSConfig() (*tls.Config, error) {
+	if c.TLSCert == "" && c.TLSKey == "" {
+		if c.TLSClientCA != "" {
+			return nil, errors.New("the client certificates can't be verified without the server certificate and key")
+		}
+		return nil, nil //nolint:nilnil
+	}
+	if c.TLSCert == "" || c.TLSKey == "" {
+		return nil, errors.New("both the server certificate and key are required for enabling TLS")
+	}
+
+	cert, err := loadCertificate("server", c.TLSCert, c.TLSKey, c.TLSKeyPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{*cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if c.TLSClientCA != "" {
+		pool, err := loadCertPool("client CA", c.TLSClientCA)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+// ClientConfig is the TLS configuration of the clients of the REST API, e.g.
+// the k6 pause and k6 resume commands. The zero value uses the system CAs
+// and no client certificate.
+type ClientConfig struct {
+	// TLSCA is the path of the PEM-encoded certificates of the CAs used for
+	// verifying the server certificate, e.g. a self-signed one.
+	TLSCA string
+
+	// TLSCert and TLSKey are the paths of the PEM-encoded client certificate
+	// and key, required by a server with TLSClientCA, TLSKeyPassword is the
+	// optional password of the key.
+	TLSCert        string
+	TLSKey         string
+	TLSKeyPassword null.String
+}
+
+// NewClientConfig creates the ClientConfig from the environment variables.
+func NewClientConfig(env map[string]string) ClientConfig {
+	conf := ClientConfig{
+		TLSCA:   env["K6_API_TLS_CA"],
+		TLSCert: env["K6_API_TLS_CLIENT_CERT"],
+		TLSKey:  env["K6_API_TLS_CLIENT_KEY"],
+	}
+	if val, ok := env["K6_API_TLS_CLIENT_KEY_PASSWORD"]; ok {
+		conf.TLSKeyPassword = null.StringFrom(val)
+	}
+	return conf
+}
+
+// TLSConfig returns the TLS configuration of the client, or nil if none of
+// the options are set. The client certificate is loaded in the same way as
+// the server one.
+func (c ClientConfig) TLSConfig() (*tls.Config, error) {
+	if c.TLSCA == "" && c.TLSCert == "" && c.TLSKey == "" {
+		return nil, nil //nolint:nilnil
+	}
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return nil, errors.New("both the client certificate and key are required")
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.TLSCA != "" {
+		pool, err := loadCertPool("CA", c.TLSCA)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.TLSCert != "" {
+		cert, err := loadCertificate("client", c.TLSCert, c.TLSKey, c.TLSKeyPassword)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// loadCertificate loads the certificate in the same way as the client
+// certificates in the tlsAuth option, the kind is used in the errors.
+func loadCertificate(kind, certPath, keyPath string, password null.String) (*tls.Certificate, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the %s certificate: %w", kind, err)
+	}
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the %s key: %w", kind, err)
+	}
+	tlsAuth := &lib.TLSAuth{TLSAuthFields: lib.TLSAuthFields{
+		Cert:     string(certPEM),
+		Key:      string(keyPEM),
+		Password: password,
+	}}
+	cert, err := tlsAuth.Certificate()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load the %s certificate: %w", kind, err)
+	}
+	return cert, nil
+}
+
+func loadCertPool(kind, path string) (*x509.CertPool, error) {
+	caPEM, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the %s certificates: %w", kind, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no valid %s certificates were found", kind)
+	}
+	return pool, nil
+}
+
+// scope is what a request is allowed to do.
+type scope int
+
+const (
+	scopeNone scope = iota
+	scopeRead
+	scopeControl
+)
+
+// authenticate returns the scope granted by the bearer token of the request.
+// Without any token configured, everything is allowed.
+func (c Config) authenticate(r *http.Request) scope {
+	if c.Token == "" && c.ReadOnlyToken == "" {
+		return scopeControl
+	}
+
+	token, ok := bearerToken(r)
+	switch {
+	case !ok:
+		return scopeNone
+	case c.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(c.Token)) == 1:
+		return scopeControl
+	case c.ReadOnlyToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(c.ReadOnlyToken)) == 1:
+		return scopeRead
+	default:
+		return scopeNone
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	const prefix = "bearer "
+	header := r.Header.Get("Authorization")
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	return header[len(prefix):], true
+}
+
+// withAuthHandler returns the middleware which rejects the requests that
+// aren't allowed by their token. The requests that don't change anything
+// require the read scope, the rest and the profiling require the control one.
+// The ping is always allowed, for the health checks.
+func withAuthHandler(conf Config, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ping" {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		required := scopeControl
+		if (r.Method == http.MethodGet || r.Method == http.MethodHead) &&
+			!strings.HasPrefix(r.URL.Path, "/debug/pprof/") {
+			required = scopeRead
+		}
+
+		granted := conf.authenticate(r)
+		switch {
+		case granted == scopeNone:
+			rw.Header().Set("WWW-Authenticate", `Bearer realm="k6"`)
+			authError(rw, "Unauthorized", "A valid bearer token is required", http.StatusUnauthorized)
+		case granted < required:
+			authError(rw, "Forbidden", "The token doesn't allow controlling the test", http.StatusForbidden)
+		default:
+			next.ServeHTTP(rw, r)
+		}
+	})
+}
+
+func authError(rw http.ResponseWriter, title, detail string, status int) {
+	data, err := json.Marshal(v1.ErrorResponse{
+		Errors: []v1.Error{{Status: strconv.Itoa(status), Title: title, Detail: detail}},
+	})
+	if err != nil {
+		panic(err)
+	}
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	rw.WriteHeader(status)
+	_, _ = rw.Write(data)
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestNewConfig(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, Config{}, NewConfig(map[string]string{}))
+	assert.Equal(t, Config{
+		Token:          "control",
+		ReadOnlyToken:  "read",
+		TLSCert:        "cert.pem",
+		TLSKey:         "key.pem",
+		TLSKeyPassword: null.StringFrom(""),
+		TLSClientCA:    "ca.pem",
+	}, NewConfig(map[string]string{
+		"K6_API_TOKEN":            "control",
+		"K6_API_READ_TOKEN":       "read",
+		"K6_API_TLS_CERT":         "cert.pem",
+		"K6_API_TLS_KEY":          "key.pem",
+		"K6_API_TLS_KEY_PASSWORD": "",
+		"K6_API_TLS_CLIENT_CA":    "ca.pem",
+	}))
+}
+
+func TestAuth(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		conf           Config
+		method, path   string
+		token          string
+		expectedStatus int
+	}{
+		{"no tokens", Config{}, http.MethodPatch, "/v1/status", "", http.StatusOK},
+		{"missing token", Config{Token: "control"}, http.MethodGet, "/v1/status", "", http.StatusUnauthorized},
+		{"wrong token", Config{Token: "control"}, http.MethodGet, "/v1/status", "nope", http.StatusUnauthorized},
+		{"control token", Config{Token: "control"}, http.MethodPatch, "/v1/status", "control", http.StatusOK},
+		{"read token reads", Config{Token: "control", ReadOnlyToken: "read"}, http.MethodGet, "/v1/status", "read", http.StatusOK},
+		{"read token controls", Config{Token: "control", ReadOnlyToken: "read"}, http.MethodPatch, "/v1/status", "read", http.StatusForbidden},
+		{"read token profiles", Config{ReadOnlyToken: "read"}, http.MethodGet, "/debug/pprof/", "read", http.StatusForbidden},
+		{"control token profiles", Config{Token: "control"}, http.MethodGet, "/debug/pprof/", "control", http.StatusOK},
+		{"public ping", Config{Token: "control"}, http.MethodGet, "/ping", "", http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			withAuthHandler(tc.conf, http.HandlerFunc(testHTTPHandler)).ServeHTTP(rw, r)
+
+			assert.Equal(t, tc.expectedStatus, rw.Code)
+			if tc.expectedStatus == http.StatusUnauthorized {
+				assert.Equal(t, `Bearer realm="k6"`, rw.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+// writeTestCert creates a certificate for localhost, signed by the parent
+// one or self-signed, and writes it and its key in the dir.
+func writeTestCert(
+	t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey,
+) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".pem"),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+"-key.pem"),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return cert, key
+}
+
+func TestTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ca, caKey := writeTestCert(t, dir, "ca", nil, nil)
+	serverCert, _ := writeTestCert(t, dir, "server", ca, caKey)
+	writeTestCert(t, dir, "client", ca, caKey)
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		tlsConfig, err := Config{}.TLSConfig()
+		require.NoError(t, err)
+		assert.Nil(t, tlsConfig)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Config{TLSCert: filepath.Join(dir, "server.pem")}.TLSConfig()
+		assert.ErrorContains(t, err, "both the server certificate and key are required")
+
+		_, err = Config{TLSClientCA: filepath.Join(dir, "ca.pem")}.TLSConfig()
+		assert.ErrorContains(t, err, "can't be verified without the server certificate")
+
+		_, err = Config{
+			TLSCert: filepath.Join(dir, "server.pem"),
+			TLSKey:  filepath.Join(dir, "client-key.pem"),
+		}.TLSConfig()
+		assert.ErrorContains(t, err, "couldn't load the server certificate")
+	})
+
+	t.Run("mTLS", func(t *testing.T) {
+		t.Parallel()
+
+		tlsConfig, err := Config{
+			TLSCert:     filepath.Join(dir, "server.pem"),
+			TLSKey:      filepath.Join(dir, "server-key.pem"),
+			TLSClientCA: filepath.Join(dir, "ca.pem"),
+		}.TLSConfig()
+		require.NoError(t, err)
+		require.Len(t, tlsConfig.Certificates, 1)
+		assert.Equal(t, serverCert.Raw, tlsConfig.Certificates[0].Certificate[0])
+		assert.Equal(t, tls.RequireAndVerifyClientCert, tlsConfig.ClientAuth)
+
+		srv := httptest.NewUnstartedServer(http.HandlerFunc(testHTTPHandler))
+		srv.TLS = tlsConfig
+		srv.StartTLS()
+		defer srv.Close()
+
+		roots := x509.NewCertPool()
+		roots.AddCert(ca)
+		clientCert, err := tls.LoadX509KeyPair(filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem"))
+		require.NoError(t, err)
+
+		get := func(certs ...tls.Certificate) error {
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+				RootCAs:      roots,
+				Certificates: certs,
+				MinVersion:   tls.VersionTLS12,
+			}}}
+			res, err := client.Get(srv.URL) //nolint:noctx
+			if err == nil {
+				_ = res.Body.Close()
+			}
+			return err
+		}
+		assert.Error(t, get())
+		assert.NoError(t, get(clientCert))
+	})
+}
+
+func TestClientTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ca, caKey := writeTestCert(t, dir, "ca", nil, nil)
+	writeTestCert(t, dir, "server", ca, caKey)
+	writeTestCert(t, dir, "client", ca, caKey)
+
+	conf := NewClientConfig(map[string]string{
+		"K6_API_TLS_CA":          filepath.Join(dir, "ca.pem"),
+		"K6_API_TLS_CLIENT_CERT": filepath.Join(dir, "client.pem"),
+		"K6_API_TLS_CLIENT_KEY":  filepath.Join(dir, "client-key.pem"),
+	})
+	assert.False(t, conf.TLSKeyPassword.Valid)
+
+	tlsConfig, err := ClientConfig{}.TLSConfig()
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+
+	_, err = ClientConfig{TLSCert: conf.TLSCert}.TLSConfig()
+	assert.ErrorContains(t, err, "both the client certificate and key are required")
+
+	_, err = ClientConfig{TLSCA: conf.TLSCert + ".missing"}.TLSConfig()
+	assert.ErrorContains(t, err, "couldn't read the CA certificates")
+
+	serverTLSConfig, err := Config{
+		TLSCert:     filepath.Join(dir, "server.pem"),
+		TLSKey:      filepath.Join(dir, "server-key.pem"),
+		TLSClientCA: filepath.Join(dir, "ca.pem"),
+	}.TLSConfig()
+	require.NoError(t, err)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(testHTTPHandler))
+	srv.TLS = serverTLSConfig
+	srv.StartTLS()
+	defer srv.Close()
+
+	get := func(conf ClientConfig) error {
+		tlsConfig, err := conf.TLSConfig()
+		require.NoError(t, err)
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+		res, err := client.Get(srv.URL) //nolint:noctx
+		if err == nil {
+			_ = res.Body.Close()
+		}
+		return err
+	}
+	assert.NoError(t, get(conf))
+	assert.Error(t, get(ClientConfig{TLSCA: conf.TLSCA}), "the client certificate is required")
+}
@@ -44,16 +44,24 @@ func injectProfilerHandler(mux *http.ServeMux, profilingEnabled bool) {
 	mux.Handle("/debug/pprof/", handler)
 }
 
-// GetServer returns a http.Server instance that can serve k6's REST API.
+// GetServer returns a http.Server instance that can serve k6's REST API. If
+// TLS is enabled by the config, the server has its TLSConfig set and it should
+// be started with ListenAndServeTLS.
 func GetServer(
 	runCtx context.Context,
 	addr string,
 	profilingEnabled bool,
+	conf Config,
 	runState *lib.TestRunState,
 	samples chan metrics.SampleContainer,
 	me *engine.MetricsEngine,
 	es *execution.Scheduler,
-) *http.Server {
+) (*http.Server, error) {
+	tlsConfig, err := conf.TLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid REST API TLS configuration: %w", err)
+	}
+
 	// TODO: reduce the control surface as much as possible? For example, if
 	// we refactor the Runner API, we won't need to send the Samples channel.
 	cs := &v1.ControlSurface{
@@ -64,8 +72,17 @@ func GetServer(
 		RunState:      runState,
 	}
 
-	mux := withLoggingHandler(runState.Logger, newHandler(cs, profilingEnabled))
-	return &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	mux := withLoggingHandler(runState.Logger, withAuthHandler(conf, newHandler(cs, profilingEnabled)))
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// the event streams don't end by themselves, they would delay the shutdown
+	cs.StartEventStreams()
+	srv.RegisterOnShutdown(cs.CloseEventStreams)
+	return srv, nil
 }
 
 type wrappedResponseWriter struct {
@@ -78,6 +95,13 @@ func (w wrappedResponseWriter) WriteHeader(status int) {
 	w.ResponseWriter.WriteHeader(status)
 }
 
+// Flush implements http.Flusher, for the streamed responses.
+func (w wrappedResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 // withLoggingHandler returns the middleware which logs response status for request.
 func withLoggingHandler(l logrus.FieldLogger, next http.Handler) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,21 @@
+package v1
+
+import (
+	"time"
+
+	"go.k6.io/k6/metrics"
+)
+
+// Annotation is a mark on the timeline of the test run, received by all the
+// outputs and included in the end-of-test summary data.
+type Annotation struct {
+	// Time is when the annotated event happened, if it's zero it's the time
+	// when the annotation is received.
+	Time time.Time         `json:"time" yaml:"time"`
+	Text string            `json:"text" yaml:"text"`
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+func (a Annotation) toSampleContainer() metrics.Annotation {
+	return metrics.Annotation{Time: a.Time, Text: a.Text, Tags: a.Tags}
+}
@@ -0,0 +1,28 @@
+package v1
+
+// AnnotationJSONAPI is JSON API envelop for an annotation
+type AnnotationJSONAPI struct {
+	Data annotationData `json:"data"`
+}
+
+type annotationData struct {
+	Type       string     `json:"type"`
+	ID         string     `json:"id"`
+	Attributes Annotation `json:"attributes"`
+}
+
+// NewAnnotationJSONAPI creates the JSON API annotation envelop
+func NewAnnotationJSONAPI(id string, a Annotation) AnnotationJSONAPI {
+	return AnnotationJSONAPI{
+		Data: annotationData{
+			Type:       "annotations",
+			ID:         id,
+			Attributes: a,
+		},
+	}
+}
+
+// Annotation extract the v1.Annotation from the JSON API envelop
+func (a AnnotationJSONAPI) Annotation() Annotation {
+	return a.Data.Attributes
+}
@@ -0,0 +1,52 @@
+package v1
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// handlePostAnnotation sends the annotation to the outputs, like the metric
+// samples of the test run.
+func handlePostAnnotation(cs *ControlSurface, rw http.ResponseWriter, r *http.Request) {
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		apiError(rw, "Couldn't read request", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var annotationEnvelop AnnotationJSONAPI
+	if err = json.Unmarshal(body, &annotationEnvelop); err != nil {
+		apiError(rw, "Invalid data", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	annotation := annotationEnvelop.Annotation()
+	if annotation.Text == "" {
+		apiError(rw, "Invalid data", "the text of the annotation is required", http.StatusBadRequest)
+		return
+	}
+	if annotation.Time.IsZero() {
+		annotation.Time = time.Now()
+	}
+
+	select {
+	case cs.Samples <- annotation.toSampleContainer():
+	case <-r.Context().Done():
+		return
+	}
+
+	id := strconv.FormatUint(atomic.AddUint64(&cs.lastAnnotationID, 1), 10)
+	data, err := json.Marshal(NewAnnotationJSONAPI(id, annotation))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusCreated)
+	_, _ = rw.Write(data)
+}
@@ -0,0 +1,71 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/minirunner"
+	"go.k6.io/k6/metrics"
+)
+
+func TestPostAnnotation(t *testing.T) {
+	t.Parallel()
+
+	cs := getControlSurface(t, getTestRunState(t, lib.Options{}, &minirunner.MiniRunner{}))
+
+	rw := httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/v1/annotations", bytes.NewBufferString(
+		`{"data":{"type":"annotations","attributes":{"text":"DB failover started","tags":{"team":"db"}}}}`,
+	)))
+	require.Equal(t, http.StatusCreated, rw.Code)
+
+	var doc AnnotationJSONAPI
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &doc))
+	assert.Equal(t, "annotations", doc.Data.Type)
+	assert.Equal(t, "1", doc.Data.ID)
+	annotation := doc.Annotation()
+	assert.Equal(t, "DB failover started", annotation.Text)
+	assert.WithinDuration(t, time.Now(), annotation.Time, time.Minute)
+
+	require.Len(t, cs.Samples, 1)
+	sc := <-cs.Samples
+	require.IsType(t, metrics.Annotation{}, sc)
+	assert.Equal(t, "DB failover started", sc.(metrics.Annotation).Text)           //nolint:forcetypeassert
+	assert.Equal(t, map[string]string{"team": "db"}, sc.(metrics.Annotation).Tags) //nolint:forcetypeassert
+	assert.Empty(t, sc.GetSamples())
+
+	// the time can be set explicitly
+	rw = httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/v1/annotations", bytes.NewBufferString(
+		`{"data":{"type":"annotations","attributes":{"text":"DB failover ended","time":"2023-10-01T10:00:00Z"}}}`,
+	)))
+	require.Equal(t, http.StatusCreated, rw.Code)
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &doc))
+	assert.Equal(t, "2", doc.Data.ID)
+	assert.Equal(t, time.Date(2023, 10, 1, 10, 0, 0, 0, time.UTC), (<-cs.Samples).(metrics.Annotation).Time) //nolint:forcetypeassert
+}
+
+func TestPostAnnotationInvalid(t *testing.T) {
+	t.Parallel()
+
+	cs := getControlSurface(t, getTestRunState(t, lib.Options{}, &minirunner.MiniRunner{}))
+
+	for _, body := range []string{`{"data":`, `{"data":{"type":"annotations","attributes":{"tags":{"a":"b"}}}}`} {
+		rw := httptest.NewRecorder()
+		NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/v1/annotations", bytes.NewBufferString(body)))
+		assert.Equal(t, http.StatusBadRequest, rw.Code)
+	}
+	assert.Empty(t, cs.Samples)
+
+	rw := httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/annotations", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+}
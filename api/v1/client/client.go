@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 
@@ -18,15 +19,20 @@ type Client struct {
 	BaseURL    *url.URL
 	httpClient *http.Client
 	logger     *logrus.Entry
+	token      string
 }
 
 // Option function are helpers that enable the flexible configuration of the
 // REST API client.
 type Option func(*Client)
 
-// New returns a newly configured REST API Client.
+// New returns a newly configured REST API Client. The base is the address of
+// the server, it's prefixed with http:// unless it has a scheme.
 func New(base string, options ...Option) (*Client, error) {
-	baseURL, err := url.Parse("http://" + base)
+	if !strings.Contains(base, "://") {
+		base = "http://" + base
+	}
+	baseURL, err := url.Parse(base)
 	if err != nil {
 		return nil, err
 	}
@@ -50,6 +56,13 @@ func WithHTTPClient(httpClient *http.Client) Option {
 	})
 }
 
+// WithToken sets the bearer token sent with the requests, if it isn't empty.
+func WithToken(token string) Option {
+	return Option(func(c *Client) {
+		c.token = token
+	})
+}
+
 // WithLogger sets the specified logger to the client.
 func WithLogger(logger *logrus.Entry) Option {
 	return Option(func(c *Client) {
@@ -89,9 +102,13 @@ func (c *Client) CallAPI(ctx context.Context, method string, rel *url.URL, body,
 	req := &http.Request{
 		Method: method,
 		URL:    c.BaseURL.ResolveReference(rel),
+		Header: make(http.Header),
 		Body:   bodyReader,
 	}
 	req = req.WithContext(ctx)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
 
 	res, err := c.httpClient.Do(req)
 	if err != nil {
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"context"
+	"sync"
 
 	"go.k6.io/k6/execution"
 	"go.k6.io/k6/lib"
@@ -17,4 +18,31 @@ type ControlSurface struct {
 	MetricsEngine *engine.MetricsEngine
 	Scheduler     *execution.Scheduler
 	RunState      *lib.TestRunState
+
+	eventsOnce sync.Once
+	events     *eventHub
+
+	// lastAnnotationID is the ID of the last annotation added with the API.
+	lastAnnotationID uint64
+}
+
+func (cs *ControlSurface) eventHub() *eventHub {
+	cs.eventsOnce.Do(func() {
+		cs.events = newEventHub(cs)
+	})
+	return cs.events
+}
+
+// StartEventStreams starts watching the test run for the /v1/events clients.
+// It's expected to be called when the server starts, so the events are kept
+// for the reconnecting clients even while none of them is connected.
+func (cs *ControlSurface) StartEventStreams() {
+	cs.eventHub().start()
+}
+
+// CloseEventStreams sends the final events to all the /v1/events clients, then
+// ends their streams and rejects the new ones. It's expected to be called when
+// the server is shutting down, since it waits for the active requests.
+func (cs *ControlSurface) CloseEventStreams() {
+	cs.eventHub().close()
 }
@@ -0,0 +1,314 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"go.k6.io/k6/event"
+	"go.k6.io/k6/execution"
+)
+
+const (
+	// eventsPollInterval is how often the status and the thresholds are
+	// checked for changes.
+	eventsPollInterval = 1 * time.Second
+	// eventsMetricsInterval is how often a snapshot of the metrics is sent.
+	eventsMetricsInterval = 5 * time.Second
+	// eventsHistorySize is the number of the most recent events kept for
+	// replaying them to the reconnecting clients.
+	eventsHistorySize = 1000
+	// eventsClientBuffer is the number of events that can be waiting to be
+	// written to a client, a slower client is disconnected.
+	eventsClientBuffer = 100
+)
+
+// The names of the events sent on the /v1/events stream.
+const (
+	streamEventStatus     = "status"
+	streamEventMetrics    = "metrics"
+	streamEventThresholds = "thresholds"
+	streamEventLifecycle  = "lifecycle"
+)
+
+var errEventHubClosed = errors.New("the events stream is closed")
+
+// streamEvent is an event sent to the /v1/events clients.
+type streamEvent struct {
+	ID   uint64
+	Name string
+	Data []byte
+}
+
+// thresholdsEvent is the data of the thresholds event, sent every time the
+// set of the metrics with breached thresholds changes.
+type thresholdsEvent struct {
+	Breached []string `json:"breached"`
+}
+
+// lifecycleEvent is the data of the lifecycle event, sent for the global
+// events of the test run.
+type lifecycleEvent struct {
+	Type  string `json:"type"`
+	Error string `json:"error,omitempty"`
+}
+
+// eventHub watches the test run from the start of the server until it's shut
+// down, and broadcasts its events to the /v1/events clients. The most recent
+// events are kept, so the clients can reconnect without missing any of them,
+// even if no other client was connected in the meantime.
+type eventHub struct {
+	cs              *ControlSurface
+	pollInterval    time.Duration
+	metricsInterval time.Duration
+
+	mu      sync.Mutex
+	lastID  uint64
+	history []streamEvent
+	clients map[chan streamEvent]struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	closed  bool
+}
+
+func newEventHub(cs *ControlSurface) *eventHub {
+	return &eventHub{
+		cs:              cs,
+		pollInterval:    eventsPollInterval,
+		metricsInterval: eventsMetricsInterval,
+		clients:         make(map[chan streamEvent]struct{}),
+	}
+}
+
+// subscribe adds a client, which receives the events following the one with
+// lastID, if it's still in the history, and then the new ones. The returned
+// channel is closed if the client is too slow or the hub is closed.
+func (h *eventHub) subscribe(lastID uint64) (<-chan streamEvent, []streamEvent, func(), error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, nil, nil, errEventHubClosed
+	}
+
+	var backlog []streamEvent
+	if lastID > 0 {
+		for _, evt := range h.history {
+			if evt.ID > lastID {
+				backlog = append(backlog, evt)
+			}
+		}
+	}
+
+	ch := make(chan streamEvent, eventsClientBuffer)
+	h.clients[ch] = struct{}{}
+
+	return ch, backlog, func() { h.unsubscribe(ch) }, nil
+}
+
+// start starts watching the test run until the hub is closed. The lifecycle
+// events are subscribed before returning, for not missing the ones emitted
+// right after it.
+func (h *eventHub) start() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.stop != nil || h.closed {
+		return
+	}
+	h.stop = make(chan struct{})
+	h.done = make(chan struct{})
+
+	var lifecycle <-chan *event.Event
+	unsubscribe := func() {}
+	if events := h.cs.RunState.Events; events != nil {
+		subID, ch := events.Subscribe(event.TestStart, event.TestEnd, event.Exit)
+		lifecycle = ch
+		unsubscribe = func() { events.Unsubscribe(subID) }
+	}
+
+	go func(stop <-chan struct{}, done chan<- struct{}) {
+		defer close(done)
+		h.watch(stop, lifecycle, unsubscribe)
+	}(h.stop, h.done)
+}
+
+func (h *eventHub) unsubscribe(ch chan streamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.clients[ch]; !ok {
+		return
+	}
+	h.removeClient(ch)
+}
+
+// removeClient must be called with the lock held.
+func (h *eventHub) removeClient(ch chan streamEvent) {
+	delete(h.clients, ch)
+	close(ch)
+}
+
+func (h *eventHub) hasClients() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients) > 0
+}
+
+// close stops watching the test run, after the final events are sent, and
+// disconnects all the clients.
+func (h *eventHub) close() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.closed = true
+	stop, done := h.stop, h.done
+	h.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.clients {
+		h.removeClient(ch)
+	}
+}
+
+// publish records the event and sends it to all the clients.
+func (h *eventHub) publish(name string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		h.cs.RunState.Logger.WithError(err).Warnf("Couldn't encode the %s event", name)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	evt := streamEvent{ID: h.lastID, Name: name, Data: data}
+	h.history = append(h.history, evt)
+	if len(h.history) > eventsHistorySize {
+		h.history = h.history[len(h.history)-eventsHistorySize:]
+	}
+
+	for ch := range h.clients {
+		select {
+		case ch <- evt:
+		default:
+			// the client can reconnect and get the missed events from the history
+			h.removeClient(ch)
+		}
+	}
+}
+
+// watch publishes the changes of the test run and the lifecycle events until
+// stop is closed, then it calls unsubscribe and publishes the final events.
+func (h *eventHub) watch(stop <-chan struct{}, lifecycle <-chan *event.Event, unsubscribe func()) {
+	pollTicker := time.NewTicker(h.pollInterval)
+	defer pollTicker.Stop()
+	metricsTicker := time.NewTicker(h.metricsInterval)
+	defer metricsTicker.Stop()
+
+	lastStatus := newStatus(h.cs)
+	lastBreached := h.breachedThresholds()
+	publishChanges := func() {
+		if status := newStatus(h.cs); status != lastStatus {
+			lastStatus = status
+			h.publish(streamEventStatus, NewStatusJSONAPI(status))
+		}
+		if breached := h.breachedThresholds(); !equalStrings(breached, lastBreached) {
+			lastBreached = breached
+			h.publish(streamEventThresholds, thresholdsEvent{Breached: breached})
+		}
+	}
+	for {
+		select {
+		case <-stop:
+			unsubscribe()
+			h.publishExit(publishChanges)
+			return
+		case evt, ok := <-lifecycle:
+			if !ok {
+				lifecycle = nil
+				continue
+			}
+			data := lifecycleEvent{Type: evt.Type.String()}
+			if exit, ok := evt.Data.(*event.ExitData); ok && exit.Error != nil {
+				data.Error = exit.Error.Error()
+			}
+			evt.Done()
+			h.publish(streamEventLifecycle, data)
+		case <-pollTicker.C:
+			publishChanges()
+		case <-metricsTicker.C:
+			// the snapshots are only useful to the connected clients, they
+			// would just push the other events out of the history
+			if h.hasClients() {
+				h.publish(streamEventMetrics, h.metricsSnapshot())
+			}
+		}
+	}
+}
+
+// publishExit publishes the last changes of the test run and the Exit event.
+// The server is shut down before the end-of-test processing, so the clients
+// can't receive the global Exit event; its error is the one that aborted the
+// test run, if any, the thresholds crossed at the end of the test aren't
+// evaluated yet.
+func (h *eventHub) publishExit(publishChanges func()) {
+	publishChanges()
+
+	data := lifecycleEvent{Type: event.Exit.String()}
+	if err := execution.GetCancelReasonIfTestAborted(h.cs.RunCtx); err != nil {
+		data.Error = err.Error()
+	}
+	h.publish(streamEventLifecycle, data)
+}
+
+// breachedThresholds returns the sorted names of the metrics with breached
+// thresholds, as of their last evaluation.
+func (h *eventHub) breachedThresholds() []string {
+	me := h.cs.MetricsEngine
+	me.MetricsLock.Lock()
+	defer me.MetricsLock.Unlock()
+
+	breached := make([]string, 0)
+	for name, m := range me.ObservedMetrics {
+		if m.Tainted.Bool {
+			breached = append(breached, name)
+		}
+	}
+	sort.Strings(breached)
+	return breached
+}
+
+func (h *eventHub) metricsSnapshot() MetricsJSONAPI {
+	var t time.Duration
+	if h.cs.Scheduler != nil {
+		t = h.cs.Scheduler.GetState().GetCurrentTestRunDuration()
+	}
+
+	h.cs.MetricsEngine.MetricsLock.Lock()
+	defer h.cs.MetricsEngine.MetricsLock.Unlock()
+	return newMetricsJSONAPI(h.cs.MetricsEngine.ObservedMetrics, t)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// eventsKeepAliveInterval is how often a comment is sent to the idle
+// /v1/events clients, for keeping the connection open through the proxies.
+const eventsKeepAliveInterval = 15 * time.Second
+
+// handleGetEvents streams the events of the test run as Server-Sent Events.
+// The current status is sent first, then the events following the one in the
+// Last-Event-ID header, if any, and then the new ones as they happen.
+func handleGetEvents(cs *ControlSurface, rw http.ResponseWriter, r *http.Request) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		apiError(rw, "Streaming unsupported", "The response can't be streamed", http.StatusInternalServerError)
+		return
+	}
+
+	var lastID uint64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		var err error
+		if lastID, err = strconv.ParseUint(v, 10, 64); err != nil {
+			apiError(rw, "Invalid Last-Event-ID", err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	events, backlog, unsubscribe, err := cs.eventHub().subscribe(lastID)
+	if err != nil {
+		apiError(rw, "Events unavailable", err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer unsubscribe()
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.WriteHeader(http.StatusOK)
+
+	// the current status has no ID, it isn't part of the history
+	if err := writeStreamEvent(rw, streamEvent{Name: streamEventStatus}, newStatusJSONAPIFromEngine(cs)); err != nil {
+		return
+	}
+	for _, evt := range backlog {
+		if err := writeStreamEvent(rw, evt, nil); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventsKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(rw, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeStreamEvent(rw, evt, nil); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// writeStreamEvent writes the event in the Server-Sent Events format, with v
+// encoded as its data if it isn't already encoded.
+func writeStreamEvent(rw http.ResponseWriter, evt streamEvent, v interface{}) error {
+	if v != nil {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		evt.Data = data
+	}
+
+	if evt.ID > 0 {
+		if _, err := fmt.Fprintf(rw, "id: %d\n", evt.ID); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(rw, "event: %s\ndata: %s\n\n", evt.Name, evt.Data)
+	return err
+}
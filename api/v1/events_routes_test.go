@@ -0,0 +1,225 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/event"
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/minirunner"
+	"go.k6.io/k6/metrics"
+)
+
+type sseEvent struct {
+	id   uint64
+	name string
+	data string
+}
+
+// readSSEEvent reads the next event from the stream, skipping the comments.
+func readSSEEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+
+	var evt sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "":
+			if evt.name != "" {
+				return evt
+			}
+		case strings.HasPrefix(line, "id: "):
+			evt.id, err = strconv.ParseUint(strings.TrimPrefix(line, "id: "), 10, 64)
+			require.NoError(t, err)
+		case strings.HasPrefix(line, "event: "):
+			evt.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			evt.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func getEventsStream(t *testing.T, url string, lastID uint64) (*bufio.Reader, func()) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url+"/v1/events", nil)
+	require.NoError(t, err)
+	if lastID > 0 {
+		req.Header.Set("Last-Event-ID", strconv.FormatUint(lastID, 10))
+	}
+	res, err := http.DefaultClient.Do(req) //nolint:bodyclose
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	return bufio.NewReader(res.Body), func() { _ = res.Body.Close() }
+}
+
+func TestGetEvents(t *testing.T) {
+	t.Parallel()
+
+	testState := getTestRunState(t, lib.Options{}, &minirunner.MiniRunner{})
+	testState.Events = event.NewEventSystem(10, testState.Logger)
+	cs := getControlSurface(t, testState)
+	cs.eventHub().pollInterval = 10 * time.Millisecond
+	cs.eventHub().metricsInterval = time.Hour
+	cs.StartEventStreams()
+
+	srv := httptest.NewServer(NewHandler(cs))
+	defer srv.Close()
+
+	stream, closeStream := getEventsStream(t, srv.URL, 0)
+
+	// the current status is sent first
+	evt := readSSEEvent(t, stream)
+	assert.Equal(t, "status", evt.name)
+	assert.Zero(t, evt.id)
+	var status StatusJSONAPI
+	require.NoError(t, json.Unmarshal([]byte(evt.data), &status))
+	assert.False(t, status.Status().Stopped)
+
+	waitDone := testState.Events.Emit(&event.Event{Type: event.TestStart})
+	evt = readSSEEvent(t, stream)
+	assert.Equal(t, sseEvent{id: 1, name: "lifecycle", data: `{"type":"TestStart"}`}, evt)
+	require.NoError(t, waitDone(context.Background()))
+
+	cs.MetricsEngine.MetricsLock.Lock()
+	m, err := testState.Registry.NewMetric("my_metric", metrics.Counter)
+	require.NoError(t, err)
+	m.Tainted = null.BoolFrom(true)
+	cs.MetricsEngine.ObservedMetrics[m.Name] = m
+	cs.MetricsEngine.MetricsLock.Unlock()
+	evt = readSSEEvent(t, stream)
+	assert.Equal(t, sseEvent{id: 2, name: "thresholds", data: `{"breached":["my_metric"]}`}, evt)
+
+	execution.AbortTestRun(cs.RunCtx, errors.New("stopped"))
+	evt = readSSEEvent(t, stream)
+	assert.Equal(t, uint64(3), evt.id)
+	assert.Equal(t, "status", evt.name)
+	require.NoError(t, json.Unmarshal([]byte(evt.data), &status))
+	assert.True(t, status.Status().Stopped)
+	closeStream()
+
+	// the events after the last received one are replayed on reconnection
+	stream, closeStream = getEventsStream(t, srv.URL, 1)
+	defer closeStream()
+	evt = readSSEEvent(t, stream)
+	assert.Equal(t, "status", evt.name)
+	assert.Zero(t, evt.id)
+	assert.Equal(t, uint64(2), readSSEEvent(t, stream).id)
+	assert.Equal(t, uint64(3), readSSEEvent(t, stream).id)
+
+	// closing the streams sends the Exit event, then ends the connected ones
+	// and rejects the new ones
+	cs.CloseEventStreams()
+	assert.Equal(t, sseEvent{id: 4, name: "lifecycle", data: `{"type":"Exit","error":"stopped"}`}, readSSEEvent(t, stream))
+	_, err = stream.ReadString('\n')
+	require.Error(t, err)
+
+	rw := httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/events", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rw.Code)
+}
+
+func TestGetEventsMetrics(t *testing.T) {
+	t.Parallel()
+
+	testState := getTestRunState(t, lib.Options{}, &minirunner.MiniRunner{})
+	cs := getControlSurface(t, testState)
+	cs.eventHub().metricsInterval = 10 * time.Millisecond
+	cs.StartEventStreams()
+	t.Cleanup(cs.CloseEventStreams)
+
+	cs.MetricsEngine.MetricsLock.Lock()
+	m, err := testState.Registry.NewMetric("my_metric", metrics.Gauge)
+	require.NoError(t, err)
+	cs.MetricsEngine.ObservedMetrics[m.Name] = m
+	cs.MetricsEngine.MetricsLock.Unlock()
+
+	srv := httptest.NewServer(NewHandler(cs))
+	defer srv.Close()
+
+	stream, closeStream := getEventsStream(t, srv.URL, 0)
+	defer closeStream()
+
+	assert.Equal(t, "status", readSSEEvent(t, stream).name)
+	evt := readSSEEvent(t, stream)
+	assert.Equal(t, "metrics", evt.name)
+	assert.Equal(t, uint64(1), evt.id)
+
+	var doc MetricsJSONAPI
+	require.NoError(t, json.Unmarshal([]byte(evt.data), &doc))
+	require.Len(t, doc.Metrics(), 1)
+	assert.Equal(t, "my_metric", doc.Metrics()[0].Name)
+}
+
+func TestGetEventsReconnect(t *testing.T) {
+	t.Parallel()
+
+	testState := getTestRunState(t, lib.Options{}, &minirunner.MiniRunner{})
+	testState.Events = event.NewEventSystem(10, testState.Logger)
+	cs := getControlSurface(t, testState)
+	cs.eventHub().pollInterval = 10 * time.Millisecond
+	cs.eventHub().metricsInterval = time.Hour
+	cs.StartEventStreams()
+
+	srv := httptest.NewServer(NewHandler(cs))
+	defer srv.Close()
+
+	stream, closeStream := getEventsStream(t, srv.URL, 0)
+	assert.Equal(t, "status", readSSEEvent(t, stream).name)
+	waitDone := testState.Events.Emit(&event.Event{Type: event.TestStart})
+	assert.Equal(t, sseEvent{id: 1, name: "lifecycle", data: `{"type":"TestStart"}`}, readSSEEvent(t, stream))
+	require.NoError(t, waitDone(context.Background()))
+	closeStream()
+	require.Eventually(t, func() bool { return !cs.eventHub().hasClients() }, time.Second, 10*time.Millisecond)
+
+	// the events are still watched while no client is connected
+	waitDone = testState.Events.Emit(&event.Event{Type: event.TestEnd})
+	require.NoError(t, waitDone(context.Background()))
+	execution.AbortTestRun(cs.RunCtx, errors.New("stopped"))
+	require.Eventually(t, func() bool {
+		cs.eventHub().mu.Lock()
+		defer cs.eventHub().mu.Unlock()
+		return cs.eventHub().lastID == 3
+	}, time.Second, 10*time.Millisecond)
+
+	stream, closeStream = getEventsStream(t, srv.URL, 1)
+	defer closeStream()
+	assert.Equal(t, "status", readSSEEvent(t, stream).name)
+	assert.Equal(t, sseEvent{id: 2, name: "lifecycle", data: `{"type":"TestEnd"}`}, readSSEEvent(t, stream))
+	evt := readSSEEvent(t, stream)
+	assert.Equal(t, uint64(3), evt.id)
+	assert.Equal(t, "status", evt.name)
+
+	cs.CloseEventStreams()
+	assert.Equal(t, sseEvent{id: 4, name: "lifecycle", data: `{"type":"Exit","error":"stopped"}`}, readSSEEvent(t, stream))
+}
+
+func TestGetEventsInvalidLastEventID(t *testing.T) {
+	t.Parallel()
+
+	cs := getControlSurface(t, getTestRunState(t, lib.Options{}, &minirunner.MiniRunner{}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/events", nil)
+	req.Header.Set("Last-Event-ID", "nope")
+	rw := httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusBadRequest, rw.Code)
+}
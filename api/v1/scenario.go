@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"encoding/json"
+
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/executor"
+	"go.k6.io/k6/ui/pb"
+)
+
+// Scenario is the state of a scenario and of its executor.
+type Scenario struct {
+	Name            string          `json:"name" yaml:"name"`
+	Executor        string          `json:"executor" yaml:"executor"`
+	Status          string          `json:"status" yaml:"status"`
+	Progress        float64         `json:"progress" yaml:"progress"`
+	ProgressDetails []string        `json:"progress-details" yaml:"progress-details"`
+	ActiveVUs       int64           `json:"active-vus" yaml:"active-vus"`
+	Config          json.RawMessage `json:"config" yaml:"config"`
+
+	// Stopped and Rate can be changed with a PATCH request, for stopping the
+	// scenario or changing the rate of a constant-arrival-rate one.
+	Stopped bool     `json:"stopped" yaml:"stopped"`
+	Rate    null.Int `json:"rate" yaml:"rate"`
+}
+
+// The statuses of the scenarios, by the status of their progress bars.
+var scenarioStatuses = map[pb.Status]string{ //nolint:gochecknoglobals
+	pb.Waiting:     "waiting",
+	pb.Running:     "running",
+	pb.Stopping:    "stopping",
+	pb.Interrupted: "interrupted",
+	pb.Done:        "done",
+}
+
+func newScenario(scheduler *execution.Scheduler, ex lib.Executor) (Scenario, error) {
+	config := ex.GetConfig()
+	rawConfig, err := json.Marshal(config)
+	if err != nil {
+		return Scenario{}, err
+	}
+
+	scenario := Scenario{
+		Name:     config.GetName(),
+		Executor: config.GetType(),
+		Status:   "pending",
+		Config:   rawConfig,
+		Stopped:  scheduler.IsExecutorStopped(config.GetName()),
+	}
+
+	if progress := ex.GetProgress(); progress != nil {
+		if status, ok := scenarioStatuses[progress.Status()]; ok {
+			scenario.Status = status
+		}
+		scenario.Progress, scenario.ProgressDetails = progress.Progress()
+	}
+	if ex, ok := ex.(interface{ GetActiveVUsCount() int64 }); ok {
+		scenario.ActiveVUs = ex.GetActiveVUsCount()
+	}
+	if car, ok := ex.(*executor.ConstantArrivalRate); ok {
+		scenario.Rate = car.GetCurrentConfig().Rate
+	}
+
+	return scenario, nil
+}
+
+func getExecutor(scheduler *execution.Scheduler, name string) (lib.Executor, bool) {
+	for _, ex := range scheduler.GetExecutors() {
+		if ex.GetConfig().GetName() == name {
+			return ex, true
+		}
+	}
+	return nil, false
+}
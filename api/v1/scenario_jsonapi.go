@@ -0,0 +1,53 @@
+package v1
+
+// ScenarioJSONAPI is JSON API envelop for a scenario
+type ScenarioJSONAPI struct {
+	Data scenarioData `json:"data"`
+}
+
+// ScenariosJSONAPI is JSON API envelop for the scenarios
+type ScenariosJSONAPI struct {
+	Data []scenarioData `json:"data"`
+}
+
+type scenarioData struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Attributes Scenario `json:"attributes"`
+}
+
+// NewScenarioJSONAPI creates the JSON API scenario envelop
+func NewScenarioJSONAPI(s Scenario) ScenarioJSONAPI {
+	return ScenarioJSONAPI{Data: newScenarioData(s)}
+}
+
+// NewScenariosJSONAPI creates the JSON API scenarios envelop
+func NewScenariosJSONAPI(scenarios []Scenario) ScenariosJSONAPI {
+	envelop := ScenariosJSONAPI{Data: make([]scenarioData, 0, len(scenarios))}
+	for _, s := range scenarios {
+		envelop.Data = append(envelop.Data, newScenarioData(s))
+	}
+	return envelop
+}
+
+// Scenario extract the v1.Scenario from the JSON API envelop
+func (s ScenarioJSONAPI) Scenario() Scenario {
+	return s.Data.Attributes
+}
+
+// Scenarios extract the v1.Scenario list from the JSON API envelop
+func (s ScenariosJSONAPI) Scenarios() []Scenario {
+	scenarios := make([]Scenario, 0, len(s.Data))
+	for _, data := range s.Data {
+		scenarios = append(scenarios, data.Attributes)
+	}
+	return scenarios
+}
+
+func newScenarioData(s Scenario) scenarioData {
+	return scenarioData{
+		Type:       "scenarios",
+		ID:         s.Name,
+		Attributes: s,
+	}
+}
@@ -0,0 +1,107 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/executor"
+)
+
+func handleGetScenarios(cs *ControlSurface, rw http.ResponseWriter, _ *http.Request) {
+	executors := cs.Scheduler.GetExecutors()
+	scenarios := make([]Scenario, 0, len(executors))
+	for _, ex := range executors {
+		scenario, err := newScenario(cs.Scheduler, ex)
+		if err != nil {
+			apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+			return
+		}
+		scenarios = append(scenarios, scenario)
+	}
+
+	data, err := json.Marshal(NewScenariosJSONAPI(scenarios))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = rw.Write(data)
+}
+
+func handleGetScenario(cs *ControlSurface, rw http.ResponseWriter, _ *http.Request, name string) {
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	ex, ok := getExecutor(cs.Scheduler, name)
+	if !ok {
+		apiError(rw, "Not Found", "No scenario with that name was found", http.StatusNotFound)
+		return
+	}
+
+	writeScenario(cs, rw, ex)
+}
+
+func handlePatchScenario(cs *ControlSurface, rw http.ResponseWriter, r *http.Request, name string) {
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	ex, ok := getExecutor(cs.Scheduler, name)
+	if !ok {
+		apiError(rw, "Not Found", "No scenario with that name was found", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		apiError(rw, "Couldn't read request", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var scenarioEnvelop ScenarioJSONAPI
+	if err = json.Unmarshal(body, &scenarioEnvelop); err != nil {
+		apiError(rw, "Invalid data", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	scenario := scenarioEnvelop.Scenario()
+
+	if scenario.Stopped {
+		if err = cs.Scheduler.StopExecutor(name); err != nil {
+			apiError(rw, "Stop error", err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else if scenario.Rate.Valid {
+		car, ok := ex.(*executor.ConstantArrivalRate)
+		if !ok {
+			apiError(rw, "Execution config error", fmt.Sprintf(
+				"the rate can only be changed for the constant-arrival-rate scenarios, %q is %s",
+				name, ex.GetConfig().GetType(),
+			), http.StatusBadRequest)
+			return
+		}
+		newConfig := car.GetCurrentConfig()
+		newConfig.Rate = scenario.Rate
+		if err = car.UpdateConfig(r.Context(), newConfig); err != nil {
+			apiError(rw, "Config update error", err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	writeScenario(cs, rw, ex)
+}
+
+func writeScenario(cs *ControlSurface, rw http.ResponseWriter, ex lib.Executor) {
+	scenario, err := newScenario(cs.Scheduler, ex)
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(NewScenarioJSONAPI(scenario))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = rw.Write(data)
+}
@@ -0,0 +1,152 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/minirunner"
+)
+
+func getScenariosControlSurface(t *testing.T) *ControlSurface {
+	t.Helper()
+
+	scenarios := lib.ScenarioConfigs{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"arrivals": {"executor": "constant-arrival-rate", "rate": 10, "duration": "1m", "preAllocatedVUs": 2, "maxVUs": 2},
+		"constant": {"executor": "constant-vus", "vus": 2, "duration": "1m"}
+	}`), &scenarios))
+
+	return getControlSurface(t, getTestRunState(t, lib.Options{Scenarios: scenarios}, &minirunner.MiniRunner{}))
+}
+
+func TestGetScenarios(t *testing.T) {
+	t.Parallel()
+
+	cs := getScenariosControlSurface(t)
+
+	rw := httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/scenarios", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+
+	var doc ScenariosJSONAPI
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &doc))
+	require.Len(t, doc.Data, 2)
+	assert.Equal(t, "scenarios", doc.Data[0].Type)
+	assert.Equal(t, "arrivals", doc.Data[0].ID)
+
+	scenarios := doc.Scenarios()
+	assert.Equal(t, "arrivals", scenarios[0].Name)
+	assert.Equal(t, "constant-arrival-rate", scenarios[0].Executor)
+	assert.Equal(t, "pending", scenarios[0].Status)
+	assert.Equal(t, int64(10), scenarios[0].Rate.Int64)
+	assert.False(t, scenarios[0].Stopped)
+
+	var config map[string]interface{}
+	require.NoError(t, json.Unmarshal(scenarios[0].Config, &config))
+	assert.Equal(t, "1m0s", config["duration"])
+
+	assert.Equal(t, "constant", scenarios[1].Name)
+	assert.Equal(t, "constant-vus", scenarios[1].Executor)
+	assert.False(t, scenarios[1].Rate.Valid)
+	assert.Zero(t, scenarios[1].ActiveVUs)
+}
+
+func TestGetScenario(t *testing.T) {
+	t.Parallel()
+
+	cs := getScenariosControlSurface(t)
+
+	rw := httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/scenarios/constant", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+
+	var doc ScenarioJSONAPI
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &doc))
+	assert.Equal(t, "constant", doc.Data.ID)
+	assert.Equal(t, "constant-vus", doc.Scenario().Executor)
+
+	rw = httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/scenarios/nope", nil))
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+}
+
+func TestPatchScenario(t *testing.T) {
+	t.Parallel()
+
+	testData := map[string]struct {
+		Scenario           string
+		Payload            string
+		ExpectedStatusCode int
+		ExpectedRate       int64
+	}{
+		"nothing": {
+			Scenario:           "arrivals",
+			Payload:            `{"data":{"type":"scenarios","id":"arrivals","attributes":{}}}`,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedRate:       10,
+		},
+		"rate": {
+			Scenario:           "arrivals",
+			Payload:            `{"data":{"type":"scenarios","id":"arrivals","attributes":{"rate":25}}}`,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedRate:       25,
+		},
+		"invalid rate": {
+			Scenario:           "arrivals",
+			Payload:            `{"data":{"type":"scenarios","id":"arrivals","attributes":{"rate":0}}}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedRate:       10,
+		},
+		"rate of another executor": {
+			Scenario:           "constant",
+			Payload:            `{"data":{"type":"scenarios","id":"constant","attributes":{"rate":25}}}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		"stop before running": {
+			Scenario:           "constant",
+			Payload:            `{"data":{"type":"scenarios","id":"constant","attributes":{"stopped":true}}}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		"unknown scenario": {
+			Scenario:           "nope",
+			Payload:            `{"data":{"type":"scenarios","id":"nope","attributes":{"stopped":true}}}`,
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		"invalid data": {
+			Scenario:           "arrivals",
+			Payload:            `{"data":`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedRate:       10,
+		},
+	}
+
+	for name, testCase := range testData {
+		name, testCase := name, testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cs := getScenariosControlSurface(t)
+
+			rw := httptest.NewRecorder()
+			NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(
+				http.MethodPatch, "/v1/scenarios/"+testCase.Scenario, bytes.NewBufferString(testCase.Payload)))
+			assert.Equal(t, testCase.ExpectedStatusCode, rw.Code)
+
+			if testCase.ExpectedRate == 0 {
+				return
+			}
+			ex, ok := getExecutor(cs.Scheduler, testCase.Scenario)
+			require.True(t, ok)
+			scenario, err := newScenario(cs.Scheduler, ex)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.ExpectedRate, scenario.Rate.Int64)
+		})
+	}
+}
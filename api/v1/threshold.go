@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"sort"
+
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+// Threshold is a threshold of a metric, with the result of its last
+// evaluation.
+type Threshold struct {
+	Source         string             `json:"threshold"`
+	AbortOnFail    bool               `json:"abortOnFail"`
+	DelayAbortEval types.NullDuration `json:"delayAbortEval"`
+	// Failed is ignored when the thresholds are set.
+	Failed bool `json:"failed"`
+}
+
+// MetricThresholds are the thresholds of a metric, which is tainted if any of
+// them is breached.
+type MetricThresholds struct {
+	Metric string `json:"-"`
+
+	Tainted    null.Bool   `json:"tainted"`
+	Thresholds []Threshold `json:"thresholds"`
+}
+
+// NewMetricThresholds constructs the MetricThresholds of a metric.
+func NewMetricThresholds(m *metrics.Metric) MetricThresholds {
+	mt := MetricThresholds{
+		Metric:     m.Name,
+		Tainted:    m.Tainted,
+		Thresholds: make([]Threshold, 0, len(m.Thresholds.Thresholds)),
+	}
+	for _, t := range m.Thresholds.Thresholds {
+		mt.Thresholds = append(mt.Thresholds, Threshold{
+			Source:         t.Source,
+			AbortOnFail:    t.AbortOnFail,
+			DelayAbortEval: t.AbortGracePeriod,
+			Failed:         t.LastFailed,
+		})
+	}
+	return mt
+}
+
+func (mt MetricThresholds) toThresholds() metrics.Thresholds {
+	ts := metrics.Thresholds{Thresholds: make([]*metrics.Threshold, 0, len(mt.Thresholds))}
+	for _, t := range mt.Thresholds {
+		ts.Thresholds = append(ts.Thresholds, &metrics.Threshold{
+			Source:           t.Source,
+			AbortOnFail:      t.AbortOnFail,
+			AbortGracePeriod: t.DelayAbortEval,
+		})
+	}
+	return ts
+}
+
+// getMetricsThresholds returns the thresholds of all the metrics which have
+// any, sorted by the metric name.
+func getMetricsThresholds(cs *ControlSurface) []MetricThresholds {
+	cs.MetricsEngine.MetricsLock.Lock()
+	defer cs.MetricsEngine.MetricsLock.Unlock()
+
+	list := make([]MetricThresholds, 0)
+	for _, m := range cs.MetricsEngine.ObservedMetrics {
+		if len(m.Thresholds.Thresholds) > 0 {
+			list = append(list, NewMetricThresholds(m))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Metric < list[j].Metric })
+	return list
+}
@@ -0,0 +1,36 @@
+package v1
+
+// ThresholdsJSONAPI is JSON API envelop for the thresholds
+type ThresholdsJSONAPI struct {
+	Data []thresholdsData `json:"data"`
+}
+
+type thresholdsData struct {
+	Type       string           `json:"type"`
+	ID         string           `json:"id"`
+	Attributes MetricThresholds `json:"attributes"`
+}
+
+// NewThresholdsJSONAPI creates the JSON API thresholds envelop
+func NewThresholdsJSONAPI(list []MetricThresholds) ThresholdsJSONAPI {
+	envelop := ThresholdsJSONAPI{Data: make([]thresholdsData, 0, len(list))}
+	for _, mt := range list {
+		envelop.Data = append(envelop.Data, thresholdsData{
+			Type:       "thresholds",
+			ID:         mt.Metric,
+			Attributes: mt,
+		})
+	}
+	return envelop
+}
+
+// Thresholds extract the []v1.MetricThresholds from the JSON API envelop
+func (t ThresholdsJSONAPI) Thresholds() []MetricThresholds {
+	list := make([]MetricThresholds, 0, len(t.Data))
+	for _, data := range t.Data {
+		mt := data.Attributes
+		mt.Metric = data.ID
+		list = append(list, mt)
+	}
+	return list
+}
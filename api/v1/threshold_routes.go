@@ -0,0 +1,63 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.k6.io/k6/metrics"
+)
+
+func handleGetThresholds(cs *ControlSurface, rw http.ResponseWriter, _ *http.Request) {
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	writeThresholds(cs, rw)
+}
+
+// handlePutThresholds replaces all the thresholds, so the ones of the metrics
+// missing from the request are removed.
+func handlePutThresholds(cs *ControlSurface, rw http.ResponseWriter, r *http.Request) {
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if cs.RunState.RuntimeOptions.NoThresholds.Bool {
+		apiError(rw, "Thresholds disabled", "the thresholds aren't evaluated with --no-thresholds", http.StatusConflict)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		apiError(rw, "Couldn't read request", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var envelop ThresholdsJSONAPI
+	if err = json.Unmarshal(body, &envelop); err != nil {
+		apiError(rw, "Invalid data", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	thresholds := make(map[string]metrics.Thresholds, len(envelop.Data))
+	for _, mt := range envelop.Thresholds() {
+		if _, ok := thresholds[mt.Metric]; ok {
+			apiError(rw, "Invalid data", fmt.Sprintf("the thresholds of %q are duplicated", mt.Metric), http.StatusBadRequest)
+			return
+		}
+		thresholds[mt.Metric] = mt.toThresholds()
+	}
+
+	if _, err = cs.MetricsEngine.SetThresholds(thresholds); err != nil {
+		apiError(rw, "Thresholds update error", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeThresholds(cs, rw)
+}
+
+func writeThresholds(cs *ControlSurface, rw http.ResponseWriter) {
+	data, err := json.Marshal(NewThresholdsJSONAPI(getMetricsThresholds(cs)))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = rw.Write(data)
+}
@@ -0,0 +1,117 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/minirunner"
+	"go.k6.io/k6/metrics"
+)
+
+func getTestThresholdsControlSurface(t *testing.T) *ControlSurface {
+	t.Helper()
+
+	testState := getTestRunState(t, lib.Options{}, &minirunner.MiniRunner{})
+	_, err := testState.Registry.NewMetric("my_counter", metrics.Counter)
+	require.NoError(t, err)
+	_, err = testState.Registry.NewMetric("my_trend", metrics.Trend)
+	require.NoError(t, err)
+
+	cs := getControlSurface(t, testState)
+	ths := metrics.NewThresholds([]string{"count<5"})
+	require.NoError(t, ths.Parse())
+	require.NoError(t, cs.MetricsEngine.InitSubMetricsAndThresholds(lib.Options{
+		Thresholds: map[string]metrics.Thresholds{"my_counter": ths},
+	}, false))
+	return cs
+}
+
+func TestGetThresholds(t *testing.T) {
+	t.Parallel()
+
+	cs := getTestThresholdsControlSurface(t)
+	cs.MetricsEngine.ObservedMetrics["my_counter"].Thresholds.Thresholds[0].LastFailed = true
+	cs.MetricsEngine.ObservedMetrics["my_counter"].Tainted = null.BoolFrom(true)
+
+	rw := httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/thresholds", nil))
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.JSONEq(t, `{"data":[{"type":"thresholds","id":"my_counter","attributes":{
+		"tainted":true,
+		"thresholds":[{"threshold":"count<5","abortOnFail":false,"delayAbortEval":null,"failed":true}]
+	}}]}`, rw.Body.String())
+}
+
+func TestPutThresholds(t *testing.T) {
+	t.Parallel()
+
+	cs := getTestThresholdsControlSurface(t)
+
+	body := `{"data":[
+		{"type":"thresholds","id":"my_counter","attributes":{"thresholds":[{"threshold":"count<10"}]}},
+		{"type":"thresholds","id":"my_trend{status:200}","attributes":{"thresholds":[
+			{"threshold":"p(95)<500","abortOnFail":true,"delayAbortEval":"10s"}
+		]}}
+	]}`
+	rw := httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodPut, "/v1/thresholds", bytes.NewBufferString(body)))
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	var doc ThresholdsJSONAPI
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &doc))
+	list := doc.Thresholds()
+	require.Len(t, list, 2)
+	assert.Equal(t, "my_counter", list[0].Metric)
+	assert.Equal(t, "count<10", list[0].Thresholds[0].Source)
+	assert.Equal(t, "my_trend{status:200}", list[1].Metric)
+	assert.True(t, list[1].Thresholds[0].AbortOnFail)
+	assert.Equal(t, "10s", list[1].Thresholds[0].DelayAbortEval.String())
+	assert.Len(t, cs.MetricsEngine.ThresholdsChanges, 2)
+
+	// the thresholds missing from the request are removed
+	body = `{"data":[{"type":"thresholds","id":"my_counter","attributes":{"thresholds":[{"threshold":"count<10"}]}}]}`
+	rw = httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodPut, "/v1/thresholds", bytes.NewBufferString(body)))
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &doc))
+	require.Len(t, doc.Thresholds(), 1)
+	assert.Len(t, cs.MetricsEngine.ThresholdsChanges, 3)
+}
+
+func TestPutThresholdsInvalid(t *testing.T) {
+	t.Parallel()
+
+	cs := getTestThresholdsControlSurface(t)
+
+	for _, body := range []string{
+		`{"data":`,
+		`{"data":[{"type":"thresholds","id":"nope","attributes":{"thresholds":[{"threshold":"count<10"}]}}]}`,
+		`{"data":[{"type":"thresholds","id":"my_counter","attributes":{"thresholds":[{"threshold":"p(95)<10"}]}}]}`,
+		`{"data":[
+			{"type":"thresholds","id":"my_counter","attributes":{"thresholds":[{"threshold":"count<10"}]}},
+			{"type":"thresholds","id":"my_counter","attributes":{"thresholds":[{"threshold":"count<20"}]}}
+		]}`,
+	} {
+		rw := httptest.NewRecorder()
+		NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodPut, "/v1/thresholds", bytes.NewBufferString(body)))
+		assert.Equal(t, http.StatusBadRequest, rw.Code, body)
+	}
+	assert.Empty(t, cs.MetricsEngine.ThresholdsChanges)
+
+	cs.RunState.RuntimeOptions.NoThresholds = null.BoolFrom(true)
+	rw := httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodPut, "/v1/thresholds", bytes.NewBufferString(`{"data":[]}`)))
+	assert.Equal(t, http.StatusConflict, rw.Code)
+
+	rw = httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/v1/thresholds", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+}
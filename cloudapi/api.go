@@ -3,11 +3,14 @@ package cloudapi
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"strconv"
 
 	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+
 	"go.k6.io/k6/lib"
 )
 
@@ -37,9 +40,34 @@ type LogEntry struct {
 }
 
 type CreateTestRunResponse struct {
-	ReferenceID    string     `json:"reference_id"`
-	ConfigOverride *Config    `json:"config"`
-	Logs           []LogEntry `json:"logs"`
+	ReferenceID    string       `json:"reference_id"`
+	ConfigOverride *Config      `json:"config"`
+	Logs           []LogEntry   `json:"logs"`
+	Capabilities   Capabilities `json:"capabilities"`
+}
+
+// Capabilities are the features that the organization of the test run has
+// enabled in the cloud. The ones that the cloud doesn't specify are assumed
+// to be enabled, as they were before it started specifying them.
+type Capabilities struct {
+	Traces    null.Bool `json:"traces"`
+	MetricsV2 null.Bool `json:"metrics_v2"`
+	LogsTail  null.Bool `json:"logs_tail"`
+}
+
+// HasTraces returns whether the traces can be sent to the cloud.
+func (c Capabilities) HasTraces() bool {
+	return !c.Traces.Valid || c.Traces.Bool
+}
+
+// HasMetricsV2 returns whether the metrics can be sent with the v2 API.
+func (c Capabilities) HasMetricsV2() bool {
+	return !c.MetricsV2.Valid || c.MetricsV2.Bool
+}
+
+// HasLogsTail returns whether the logs of the test run can be tailed.
+func (c Capabilities) HasLogsTail() bool {
+	return !c.LogsTail.Valid || c.LogsTail.Bool
 }
 
 type TestProgressResponse struct {
@@ -111,36 +139,44 @@ func (c *Client) UploadTestOnly(name string, projectID int64, arc *lib.Archive)
 }
 
 func (c *Client) uploadArchive(fields [][2]string, arc *lib.Archive) (*CreateTestRunResponse, error) {
-	requestURL := fmt.Sprintf("%s/archive-upload", c.baseURL)
-
-	var buf bytes.Buffer
-	mp := multipart.NewWriter(&buf)
-
-	for _, field := range fields {
-		if err := mp.WriteField(field[0], field[1]); err != nil {
-			return nil, err
-		}
-	}
-
-	fw, err := mp.CreateFormFile("file", "archive.tar")
-	if err != nil {
+	var arcBuf bytes.Buffer
+	if err := arc.Write(&arcBuf); err != nil {
 		return nil, err
 	}
-
-	if err = arc.Write(fw); err != nil {
-		return nil, err
+	data := arcBuf.Bytes()
+	// Big archives are uploaded in chunks, so a flaky connection doesn't
+	// require the whole archive to be uploaded again.
+	if len(data) > c.uploadChunkSize {
+		return c.uploadArchiveChunked(fields, data)
 	}
 
-	if err = mp.Close(); err != nil {
+	requestURL := fmt.Sprintf("%s/archive-upload", c.baseURL)
+
+	// The multipart body is streamed from the archive while it's sent, so the
+	// archive isn't held in memory twice. Its length is known in advance, since
+	// counting the bytes of the archive doesn't require copying them.
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+	var length countingWriter
+	if err := writeArchiveForm(&length, boundary, fields, bytes.NewReader(data)); err != nil {
 		return nil, err
 	}
+	getBody := func() (io.ReadCloser, error) {
+		pr, pw := io.Pipe()
+		go func() {
+			archive := c.newUploadProgressReader(data, 0, int64(len(data)))
+			_ = pw.CloseWithError(writeArchiveForm(pw, boundary, fields, archive))
+		}()
+		return pr, nil
+	}
 
-	req, err := http.NewRequest(http.MethodPost, requestURL, &buf) //nolint:noctx
+	req, err := http.NewRequest(http.MethodPost, requestURL, nil) //nolint:noctx
 	if err != nil {
 		return nil, err
 	}
-
-	req.Header.Set("Content-Type", mp.FormDataContentType())
+	req.Body, _ = getBody()
+	req.GetBody = getBody
+	req.ContentLength = int64(length)
+	req.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
 
 	ctrr := CreateTestRunResponse{}
 	if err := c.Do(req, &ctrr); err != nil {
@@ -150,6 +186,31 @@ func (c *Client) uploadArchive(fields [][2]string, arc *lib.Archive) (*CreateTes
 	return &ctrr, nil
 }
 
+// writeArchiveForm writes the multipart form with the fields and the archive,
+// using the given boundary, so the same form can be written more than once.
+func writeArchiveForm(w io.Writer, boundary string, fields [][2]string, archive io.Reader) error {
+	mp := multipart.NewWriter(w)
+	if err := mp.SetBoundary(boundary); err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		if err := mp.WriteField(field[0], field[1]); err != nil {
+			return err
+		}
+	}
+
+	fw, err := mp.CreateFormFile("file", "archive.tar")
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(fw, archive); err != nil {
+		return err
+	}
+
+	return mp.Close()
+}
+
 // TestFinished sends the result and run status values to the cloud, along with
 // information for the test thresholds, and marks the test run as finished.
 func (c *Client) TestFinished(referenceID string, thresholds ThresholdResult, tained bool, runStatus RunStatus) error {
@@ -194,6 +255,8 @@ func (c *Client) GetTestProgress(referenceID string) (*TestProgressResponse, err
 	return &ctrr, nil
 }
 
+// StopCloudTestRun requests a graceful stop of a cloud test run, i.e. the
+// running iterations are allowed to finish and the teardown is executed.
 func (c *Client) StopCloudTestRun(referenceID string) error {
 	url := fmt.Sprintf("%s/tests/%s/stop", c.baseURL, referenceID)
 
@@ -205,6 +268,19 @@ func (c *Client) StopCloudTestRun(referenceID string) error {
 	return c.Do(req, nil)
 }
 
+// AbortCloudTestRun requests an immediate stop of a cloud test run, without
+// waiting for the running iterations or executing the teardown.
+func (c *Client) AbortCloudTestRun(referenceID string) error {
+	url := fmt.Sprintf("%s/tests/%s/abort", c.baseURL, referenceID)
+
+	req, err := c.NewRequest("POST", url, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.Do(req, nil)
+}
+
 func (c *Client) ValidateOptions(options lib.Options) error {
 	url := fmt.Sprintf("%s/validate-options", c.baseURL)
 
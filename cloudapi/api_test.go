@@ -48,6 +48,25 @@ func TestCreateTestRun(t *testing.T) {
 	assert.False(t, resp.ConfigOverride.AggregationMinSamples.Valid)
 }
 
+func TestCreateTestRunCapabilities(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fprintf(t, w, `{"reference_id": "1", "capabilities": {"traces": false, "metrics_v2": true}}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(testutils.NewLogger(t), "token", server.URL, "1.0", 1*time.Second)
+
+	resp, err := client.CreateTestRun(&TestRun{Name: "test"})
+	require.NoError(t, err)
+
+	assert.False(t, resp.Capabilities.HasTraces())
+	assert.True(t, resp.Capabilities.HasMetricsV2())
+	// the capabilities that aren't specified are enabled
+	assert.True(t, resp.Capabilities.HasLogsTail())
+	assert.True(t, Capabilities{}.HasTraces())
+}
+
 func TestFinished(t *testing.T) {
 	t.Parallel()
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -8,6 +8,7 @@ import (
 	"io"
 	"math/rand"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -33,6 +34,10 @@ type Client struct {
 
 	retries       int
 	retryInterval time.Duration
+
+	uploadChunkSize  int
+	uploadProgress   func(sent, total int64)
+	uploadProgressMu sync.Mutex
 }
 
 // NewClient return a new client for the cloud API
@@ -45,10 +50,18 @@ func NewClient(logger logrus.FieldLogger, token, host, version string, timeout t
 		retries:       MaxRetries,
 		retryInterval: RetryInterval,
 		logger:        logger,
+
+		uploadChunkSize: ArchiveUploadChunkSize,
 	}
 	return c
 }
 
+// SetUploadProgressCallback sets a function that is called with the number of
+// bytes sent so far and the total size of the archive while it's uploaded.
+func (c *Client) SetUploadProgressCallback(callback func(sent, total int64)) {
+	c.uploadProgress = callback
+}
+
 // BaseURL returns configured host.
 func (c *Client) BaseURL() string {
 	return c.baseURL
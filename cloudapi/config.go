@@ -2,6 +2,8 @@ package cloudapi
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/mstoykov/envconfig"
@@ -21,6 +23,15 @@ type Config struct {
 	Host    null.String        `json:"host" envconfig:"K6_CLOUD_HOST"`
 	Timeout types.NullDuration `json:"timeout" envconfig:"K6_CLOUD_TIMEOUT"`
 
+	// The name of the profile in Profiles whose values should be used.
+	Profile null.String `json:"profile" envconfig:"K6_CLOUD_PROFILE"`
+
+	// Named sets of credentials and addresses, e.g. for different k6 cloud stacks.
+	Profiles map[string]Profile `json:"profiles,omitempty" ignored:"true"`
+
+	// The name of the credential helper that stores the tokens, if Token isn't set.
+	CredentialHelper null.String `json:"credentialHelper" envconfig:"K6_CLOUD_CREDENTIAL_HELPER"`
+
 	LogsTailURL    null.String `json:"-" envconfig:"K6_CLOUD_LOGS_TAIL_URL"`
 	WebAppURL      null.String `json:"webAppURL" envconfig:"K6_CLOUD_WEB_APP_URL"`
 	TestRunDetails null.String `json:"testRunDetails" envconfig:"K6_CLOUD_TEST_RUN_DETAILS"`
@@ -37,6 +48,32 @@ type Config struct {
 	// Currently, a renaming is not planned.
 	PushRefID null.String `json:"pushRefID" envconfig:"K6_CLOUD_PUSH_REF_ID"`
 
+	// If set, the metrics that fail to be pushed to the cloud are written to
+	// this directory, so they can be pushed later with `k6 cloud replay-metrics`.
+	SpoolDir null.String `json:"spoolDir" envconfig:"K6_CLOUD_SPOOL_DIR"`
+
+	// If set, the cloud output doesn't use the cloud at all, it writes every
+	// batch of metrics, exactly as it would push it, to a file in this
+	// directory instead, which can be read with `k6 cloud decode-metrics`.
+	MetricsDir null.String `json:"metricsDir" envconfig:"K6_CLOUD_METRICS_DIR"`
+
+	// If set, the aggregated metrics are exposed for being scraped by
+	// Prometheus on the /metrics path of this address, e.g. ":9464".
+	MetricsExpose null.String `json:"metricsExpose" envconfig:"K6_CLOUD_METRICS_EXPOSE"`
+
+	// Comma-separated glob patterns of the tags that are sent to the cloud as
+	// labels of the metrics, e.g. "name,status,scenario". All of them are sent
+	// if it's empty, and LabelsDeny is applied after it.
+	LabelsAllow null.String `json:"labelsAllow" envconfig:"K6_CLOUD_LABELS_ALLOW"`
+
+	// Comma-separated glob patterns of the tags that are never sent to the
+	// cloud as labels of the metrics, e.g. "url,iter_*".
+	LabelsDeny null.String `json:"labelsDeny" envconfig:"K6_CLOUD_LABELS_DENY"`
+
+	// The rules for the labels of specific metrics, by the name of the metric.
+	// They override LabelsAllow and LabelsDeny for those metrics.
+	LabelRules map[string]LabelRule `json:"labelRules,omitempty" ignored:"true"`
+
 	// The time interval between periodic API calls for sending samples to the cloud ingest service.
 	MetricPushInterval types.NullDuration `json:"metricPushInterval" envconfig:"K6_CLOUD_METRIC_PUSH_INTERVAL"`
 
@@ -49,12 +86,32 @@ type Config struct {
 	// The host of the k6 Insights backend service.
 	TracesHost null.String `json:"traceHost" envconfig:"K6_CLOUD_TRACES_HOST"`
 
+	// The exporter of the traces: "insights" sends them to the k6 Insights
+	// backend service, "otlp" sends them as spans to an OpenTelemetry collector.
+	TracesExporter null.String `json:"tracesExporter" envconfig:"K6_INSIGHTS_EXPORTER"`
+
+	// The OpenTelemetry collector of the "otlp" exporter, in the same format
+	// of the otel traces output, e.g. "otel=http://localhost:4318/v1/traces".
+	TracesOTLPOutput null.String `json:"tracesOTLPOutput" envconfig:"K6_INSIGHTS_OTLP_OUTPUT"`
+
 	// This is how many concurrent pushes will be done at the same time to the cloud
 	TracesPushConcurrency null.Int `json:"tracesPushConcurrency" envconfig:"K6_CLOUD_TRACES_PUSH_CONCURRENCY"`
 
 	// The time interval between periodic API calls for sending samples to the cloud ingest service.
 	TracesPushInterval types.NullDuration `json:"tracesPushInterval" envconfig:"K6_CLOUD_TRACES_PUSH_INTERVAL"`
 
+	// The maximum number of traces waiting to be sent to the cloud, 0 means no limit.
+	TracesBufferSize null.Int `json:"tracesBufferSize" envconfig:"K6_CLOUD_TRACES_BUFFER_SIZE"`
+
+	// Which traces are dropped when there are already TracesBufferSize of
+	// them waiting: the "oldest" ones, or the "newest" ones being collected.
+	TracesDropPolicy null.String `json:"tracesDropPolicy" envconfig:"K6_CLOUD_TRACES_DROP_POLICY"`
+
+	// The maximum size in bytes of a batch of traces sent to the cloud at once,
+	// 0 means no limit. When the waiting traces reach it, they are sent without
+	// waiting for the next push interval.
+	TracesBatchSize null.Int `json:"tracesBatchSize" envconfig:"K6_CLOUD_TRACES_BATCH_SIZE"`
+
 	// Aggregation docs:
 	//
 	// If AggregationPeriod is specified and if it is greater than 0, HTTP metric aggregation
@@ -160,6 +217,32 @@ type Config struct {
 	MaxMetricSamplesPerPackage null.Int `json:"maxMetricSamplesPerPackage" envconfig:"K6_CLOUD_MAX_METRIC_SAMPLES_PER_PACKAGE"`
 }
 
+// Profile holds the credentials and addresses for a single k6 cloud stack, so
+// users can easily switch between them, e.g. `k6 cloud --profile staging`.
+type Profile struct {
+	Token     null.String `json:"token"`
+	Host      null.String `json:"host"`
+	ProjectID null.Int    `json:"projectID"`
+	WebAppURL null.String `json:"webAppURL"`
+}
+
+func (p Profile) config() Config {
+	return Config{
+		Token:     p.Token,
+		Host:      p.Host,
+		ProjectID: p.ProjectID,
+		WebAppURL: p.WebAppURL,
+	}
+}
+
+// LabelRule holds the glob patterns of the tags that are sent, or not sent,
+// to the cloud as labels of a specific metric. A nil list means that the one
+// of the Config is used.
+type LabelRule struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
 // NewConfig creates a new Config instance with default values for some fields.
 func NewConfig() Config {
 	c := Config{
@@ -171,8 +254,13 @@ func NewConfig() Config {
 
 		TracesEnabled:         null.NewBool(true, false),
 		TracesHost:            null.NewString("grpc-k6-api-prod-prod-us-east-0.grafana.net:443", false),
+		TracesExporter:        null.NewString("insights", false),
+		TracesOTLPOutput:      null.NewString("otel", false),
 		TracesPushInterval:    types.NewNullDuration(1*time.Second, false),
 		TracesPushConcurrency: null.NewInt(1, false),
+		TracesBufferSize:      null.NewInt(100000, false),
+		TracesDropPolicy:      null.NewString("oldest", false),
+		TracesBatchSize:       null.NewInt(1024*1024, false),
 
 		MaxMetricSamplesPerPackage: null.NewInt(100000, false),
 		Timeout:                    types.NewNullDuration(1*time.Minute, false),
@@ -249,6 +337,15 @@ func (c Config) Apply(cfg Config) Config {
 	if cfg.Timeout.Valid {
 		c.Timeout = cfg.Timeout
 	}
+	if cfg.Profile.Valid {
+		c.Profile = cfg.Profile
+	}
+	if len(cfg.Profiles) > 0 {
+		c.Profiles = cfg.Profiles
+	}
+	if cfg.CredentialHelper.Valid {
+		c.CredentialHelper = cfg.CredentialHelper
+	}
 	if cfg.APIVersion.Valid {
 		c.APIVersion = cfg.APIVersion
 	}
@@ -258,6 +355,24 @@ func (c Config) Apply(cfg Config) Config {
 	if cfg.MaxTimeSeriesInBatch.Valid {
 		c.MaxTimeSeriesInBatch = cfg.MaxTimeSeriesInBatch
 	}
+	if cfg.SpoolDir.Valid {
+		c.SpoolDir = cfg.SpoolDir
+	}
+	if cfg.MetricsDir.Valid {
+		c.MetricsDir = cfg.MetricsDir
+	}
+	if cfg.MetricsExpose.Valid {
+		c.MetricsExpose = cfg.MetricsExpose
+	}
+	if cfg.LabelsAllow.Valid {
+		c.LabelsAllow = cfg.LabelsAllow
+	}
+	if cfg.LabelsDeny.Valid {
+		c.LabelsDeny = cfg.LabelsDeny
+	}
+	if len(cfg.LabelRules) > 0 {
+		c.LabelRules = cfg.LabelRules
+	}
 	if cfg.MetricPushInterval.Valid {
 		c.MetricPushInterval = cfg.MetricPushInterval
 	}
@@ -270,12 +385,27 @@ func (c Config) Apply(cfg Config) Config {
 	if cfg.TracesHost.Valid {
 		c.TracesHost = cfg.TracesHost
 	}
+	if cfg.TracesExporter.Valid {
+		c.TracesExporter = cfg.TracesExporter
+	}
+	if cfg.TracesOTLPOutput.Valid {
+		c.TracesOTLPOutput = cfg.TracesOTLPOutput
+	}
 	if cfg.TracesPushInterval.Valid {
 		c.TracesPushInterval = cfg.TracesPushInterval
 	}
 	if cfg.TracesPushConcurrency.Valid {
 		c.TracesPushConcurrency = cfg.TracesPushConcurrency
 	}
+	if cfg.TracesBufferSize.Valid {
+		c.TracesBufferSize = cfg.TracesBufferSize
+	}
+	if cfg.TracesDropPolicy.Valid {
+		c.TracesDropPolicy = cfg.TracesDropPolicy
+	}
+	if cfg.TracesBatchSize.Valid {
+		c.TracesBatchSize = cfg.TracesBatchSize
+	}
 	if cfg.AggregationPeriod.Valid {
 		c.AggregationPeriod = cfg.AggregationPeriod
 	}
@@ -306,8 +436,9 @@ func (c Config) Apply(cfg Config) Config {
 	return c
 }
 
-// MergeFromExternal merges three fields from the JSON in a loadimpact key of
-// the provided external map. Used for options.ext.loadimpact settings.
+// MergeFromExternal merges the project, name, token and labels fields from the
+// JSON in a loadimpact key of the provided external map. Used for
+// options.ext.loadimpact settings.
 func MergeFromExternal(external map[string]json.RawMessage, conf *Config) error {
 	if val, ok := external["loadimpact"]; ok {
 		// TODO: Important! Separate configs and fix the whole 2 configs mess!
@@ -315,7 +446,7 @@ func MergeFromExternal(external map[string]json.RawMessage, conf *Config) error
 		if err := json.Unmarshal(val, &tmpConfig); err != nil {
 			return err
 		}
-		// Only take out the ProjectID, Name and Token from the options.ext.loadimpact map:
+		// Only take out the ProjectID, Name, Token and labels from the options.ext.loadimpact map:
 		if tmpConfig.ProjectID.Valid {
 			conf.ProjectID = tmpConfig.ProjectID
 		}
@@ -325,6 +456,17 @@ func MergeFromExternal(external map[string]json.RawMessage, conf *Config) error
 		if tmpConfig.Token.Valid {
 			conf.Token = tmpConfig.Token
 		}
+		// The labels of the metrics depend on the tags that the script uses,
+		// so the rules for them can be defined in the script too.
+		if tmpConfig.LabelsAllow.Valid {
+			conf.LabelsAllow = tmpConfig.LabelsAllow
+		}
+		if tmpConfig.LabelsDeny.Valid {
+			conf.LabelsDeny = tmpConfig.LabelsDeny
+		}
+		if len(tmpConfig.LabelRules) > 0 {
+			conf.LabelRules = tmpConfig.LabelRules
+		}
 	}
 	return nil
 }
@@ -342,9 +484,6 @@ func GetConsolidatedConfig(
 		}
 		result = result.Apply(jsonConf)
 	}
-	if err := MergeFromExternal(external, &result); err != nil {
-		return result, err
-	}
 
 	envConfig := Config{}
 	if err := envconfig.Process("", &envConfig, func(key string) (string, bool) {
@@ -354,11 +493,51 @@ func GetConsolidatedConfig(
 		// TODO: get rid of envconfig and actually use the env parameter...
 		return result, err
 	}
+
+	// The profile is applied on top of the JSON config, but the values from
+	// the script options and the environment variables still have priority.
+	if envConfig.Profile.Valid {
+		result.Profile = envConfig.Profile
+	}
+	if result.Profile.Valid && result.Profile.String != "" {
+		profile, ok := result.Profiles[result.Profile.String]
+		if !ok {
+			return result, fmt.Errorf("the cloud profile %q doesn't exist, "+
+				"use `k6 login cloud --list` to see the available profiles", result.Profile.String)
+		}
+		// The token and the addresses of the top-level config are for another
+		// stack, so they must never be mixed with the ones of the profile.
+		// Without a token in the profile, the one from the credential helper
+		// for its host is used, and without addresses, the default ones.
+		defaults := NewConfig()
+		result.Token = null.String{}
+		result.Host = defaults.Host
+		result.ProjectID = defaults.ProjectID
+		result.WebAppURL = defaults.WebAppURL
+		result = result.Apply(profile.config())
+	}
+
+	if err := MergeFromExternal(external, &result); err != nil {
+		return result, err
+	}
 	result = result.Apply(envConfig)
 
 	if configArg != "" {
 		result.Name = null.StringFrom(configArg)
 	}
 
+	// An explicitly configured token always has priority over the one saved
+	// in the credential helper.
+	if !result.Token.Valid && result.CredentialHelper.Valid && result.CredentialHelper.String != "" {
+		token, err := NewCredentialHelper(result.CredentialHelper.String).Get(result.Host.String)
+		switch {
+		case errors.Is(err, ErrCredentialsNotFound):
+		case err != nil:
+			return result, err
+		default:
+			result.Token = null.StringFrom(token)
+		}
+	}
+
 	return result, nil
 }
@@ -29,6 +29,9 @@ func TestConfigApply(t *testing.T) {
 		Name:                            null.NewString("Name", true),
 		Host:                            null.NewString("Host", true),
 		Timeout:                         types.NewNullDuration(5*time.Second, true),
+		Profile:                         null.NewString("Profile", true),
+		Profiles:                        map[string]Profile{"Profile": {Token: null.NewString("ProfileToken", true)}},
+		CredentialHelper:                null.NewString("CredentialHelper", true),
 		LogsTailURL:                     null.NewString("LogsTailURL", true),
 		PushRefID:                       null.NewString("PushRefID", true),
 		WebAppURL:                       null.NewString("foo", true),
@@ -38,11 +41,22 @@ func TestConfigApply(t *testing.T) {
 		MaxMetricSamplesPerPackage:      null.NewInt(2, true),
 		MaxTimeSeriesInBatch:            null.NewInt(3, true),
 		MetricPushInterval:              types.NewNullDuration(1*time.Second, true),
+		SpoolDir:                        null.NewString("SpoolDir", true),
+		MetricsDir:                      null.NewString("MetricsDir", true),
+		MetricsExpose:                   null.NewString("MetricsExpose", true),
+		LabelsAllow:                     null.NewString("LabelsAllow", true),
+		LabelsDeny:                      null.NewString("LabelsDeny", true),
+		LabelRules:                      map[string]LabelRule{"metric": {Deny: []string{"url"}}},
 		MetricPushConcurrency:           null.NewInt(3, true),
 		TracesEnabled:                   null.NewBool(true, true),
 		TracesHost:                      null.NewString("TracesHost", true),
+		TracesExporter:                  null.NewString("otlp", true),
+		TracesOTLPOutput:                null.NewString("TracesOTLPOutput", true),
 		TracesPushInterval:              types.NewNullDuration(10*time.Second, true),
 		TracesPushConcurrency:           null.NewInt(6, true),
+		TracesBufferSize:                null.NewInt(7, true),
+		TracesDropPolicy:                null.NewString("newest", true),
+		TracesBatchSize:                 null.NewInt(8, true),
 		AggregationPeriod:               types.NewNullDuration(2*time.Second, true),
 		AggregationCalcInterval:         types.NewNullDuration(3*time.Second, true),
 		AggregationWaitPeriod:           types.NewNullDuration(4*time.Second, true),
@@ -77,3 +91,86 @@ func TestGetConsolidatedConfig(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, config.Token.String, "envvalue")
 }
+
+func TestGetConsolidatedConfigLabels(t *testing.T) {
+	t.Parallel()
+	config, err := GetConsolidatedConfig(json.RawMessage(`{"labelsDeny":"url"}`), nil, "",
+		map[string]json.RawMessage{"loadimpact": json.RawMessage(`{
+			"labelsAllow": "name,status",
+			"labelRules": {"http_req_duration": {"allow": ["name", "status", "method"]}}
+		}`)})
+	require.NoError(t, err)
+	assert.Equal(t, "name,status", config.LabelsAllow.String)
+	assert.Equal(t, "url", config.LabelsDeny.String)
+	assert.Equal(t, map[string]LabelRule{"http_req_duration": {Allow: []string{"name", "status", "method"}}},
+		config.LabelRules)
+
+	config, err = GetConsolidatedConfig(nil, map[string]string{"K6_CLOUD_LABELS_DENY": "url,iter_*"}, "",
+		map[string]json.RawMessage{"loadimpact": json.RawMessage(`{"labelsDeny": "url"}`)})
+	require.NoError(t, err)
+	assert.Equal(t, "url,iter_*", config.LabelsDeny.String)
+}
+
+func TestGetConsolidatedConfigProfiles(t *testing.T) {
+	t.Parallel()
+	jsonConf := json.RawMessage(`{
+		"token": "default",
+		"profiles": {
+			"prod": {"token": "prodtoken", "host": "https://prod.example.com", "projectID": 1},
+			"staging": {"token": "stagingtoken", "webAppURL": "https://app.staging.example.com"}
+		}
+	}`)
+
+	config, err := GetConsolidatedConfig(jsonConf, nil, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "default", config.Token.String)
+	assert.False(t, config.Profile.Valid)
+
+	config, err = GetConsolidatedConfig(jsonConf, map[string]string{"K6_CLOUD_PROFILE": "prod"}, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "prodtoken", config.Token.String)
+	assert.Equal(t, "https://prod.example.com", config.Host.String)
+	assert.Equal(t, int64(1), config.ProjectID.Int64)
+	assert.Equal(t, "https://app.k6.io", config.WebAppURL.String)
+
+	// the script options and the env vars still have priority over the profile
+	config, err = GetConsolidatedConfig(jsonConf,
+		map[string]string{"K6_CLOUD_PROFILE": "staging", "K6_CLOUD_HOST": "https://env.example.com"}, "",
+		map[string]json.RawMessage{"loadimpact": json.RawMessage(`{"projectID": 2}`)})
+	require.NoError(t, err)
+	assert.Equal(t, "stagingtoken", config.Token.String)
+	assert.Equal(t, "https://env.example.com", config.Host.String)
+	assert.Equal(t, "https://app.staging.example.com", config.WebAppURL.String)
+	assert.Equal(t, int64(2), config.ProjectID.Int64)
+
+	// the default profile can be set in the JSON config
+	config, err = GetConsolidatedConfig(
+		json.RawMessage(`{"profile": "prod", "profiles": {"prod": {"token": "prodtoken"}}}`), nil, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "prodtoken", config.Token.String)
+
+	// the top-level token isn't inherited by a profile without one
+	config, err = GetConsolidatedConfig(
+		json.RawMessage(`{"token": "default", "profiles": {"other": {"host": "https://other.example.com"}}}`),
+		map[string]string{"K6_CLOUD_PROFILE": "other"}, "", nil)
+	require.NoError(t, err)
+	assert.False(t, config.Token.Valid)
+	assert.Equal(t, "https://other.example.com", config.Host.String)
+
+	// nor are the addresses of the top-level config, for a profile with only a token
+	config, err = GetConsolidatedConfig(
+		json.RawMessage(`{
+			"token": "default", "host": "https://top.example.com", "projectID": 3,
+			"webAppURL": "https://app.top.example.com",
+			"profiles": {"tokenonly": {"token": "profiletoken"}}
+		}`),
+		map[string]string{"K6_CLOUD_PROFILE": "tokenonly"}, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "profiletoken", config.Token.String)
+	assert.Equal(t, NewConfig().Host, config.Host)
+	assert.False(t, config.ProjectID.Valid)
+	assert.Equal(t, NewConfig().WebAppURL, config.WebAppURL)
+
+	_, err = GetConsolidatedConfig(jsonConf, map[string]string{"K6_CLOUD_PROFILE": "missing"}, "", nil)
+	assert.ErrorContains(t, err, `the cloud profile "missing" doesn't exist`)
+}
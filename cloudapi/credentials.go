@@ -0,0 +1,159 @@
+package cloudapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// credentialHelperPrefix is prepended to the name of a credential helper to
+// get the name of the program that should be executed, e.g. `k6-credential-pass`.
+const credentialHelperPrefix = "k6-credential-"
+
+// credentialsNotFoundMsg is what credential helpers print when they don't have
+// the credentials for a server. It's the same message that the docker
+// credential helpers use, so they can be reused with a simple wrapper.
+const credentialsNotFoundMsg = "credentials not found in native keychain"
+
+// credentialHelperTimeout is how long a credential helper can run, so k6
+// doesn't hang if it's waiting for something that never happens.
+const credentialHelperTimeout = 30 * time.Second
+
+// ErrCredentialsNotFound is returned by CredentialHelper.Get when the helper
+// doesn't have a token for the requested server.
+var ErrCredentialsNotFound = errors.New("credentials not found")
+
+// Credentials is what is exchanged with the credential helpers, the format is
+// the same as the one of the docker credential helpers.
+type Credentials struct {
+	ServerURL string `json:"ServerURL"`
+	Secret    string `json:"Secret"`
+}
+
+// CredentialHelper stores and retrieves the k6 cloud tokens with an external
+// program, so they don't have to be saved in plain text in the config file.
+//
+// The program is executed with a single argument, the action:
+//   - get: the server URL is written on its stdin and it should print the
+//     Credentials as JSON on its stdout, or exit with a non-zero exit code and
+//     print "credentials not found in native keychain" if it has none.
+//   - store: the Credentials are written as JSON on its stdin.
+//   - erase: the server URL is written on its stdin.
+//
+// The tokens it returns are cached, so the program is executed only once for
+// every server URL by the same CredentialHelper.
+type CredentialHelper struct {
+	program string
+	timeout time.Duration
+
+	mu     sync.Mutex
+	tokens map[string]string // an empty token means that the helper has none
+}
+
+// NewCredentialHelper returns a CredentialHelper that executes the
+// `k6-credential-<name>` program from the PATH. If name is a path instead, it
+// is executed directly.
+func NewCredentialHelper(name string) *CredentialHelper {
+	program := name
+	if !strings.ContainsAny(name, `/\`) {
+		program = credentialHelperPrefix + name
+	}
+	return &CredentialHelper{
+		program: program,
+		timeout: credentialHelperTimeout,
+		tokens:  make(map[string]string),
+	}
+}
+
+// Get returns the token for the given server URL. The helper is executed
+// only the first time, the token is cached for the following calls.
+func (h *CredentialHelper) Get(serverURL string) (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	token, ok := h.tokens[serverURL]
+	if !ok {
+		var err error
+		if token, err = h.get(serverURL); err != nil && !errors.Is(err, ErrCredentialsNotFound) {
+			return "", err
+		}
+		h.tokens[serverURL] = token
+	}
+	if token == "" {
+		return "", ErrCredentialsNotFound
+	}
+	return token, nil
+}
+
+func (h *CredentialHelper) get(serverURL string) (string, error) {
+	out, err := h.execute("get", []byte(serverURL))
+	if err != nil {
+		if strings.Contains(string(out), credentialsNotFoundMsg) {
+			return "", ErrCredentialsNotFound
+		}
+		return "", err
+	}
+
+	var creds Credentials
+	if err = json.Unmarshal(out, &creds); err != nil {
+		return "", fmt.Errorf("invalid response from the credential helper %s: %w", h.program, err)
+	}
+	return creds.Secret, nil
+}
+
+// Store saves the token for the given server URL.
+func (h *CredentialHelper) Store(serverURL, token string) error {
+	input, err := json.Marshal(Credentials{ServerURL: serverURL, Secret: token})
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.tokens, serverURL)
+	_, err = h.execute("store", input)
+	return err
+}
+
+// Erase removes the token for the given server URL.
+func (h *CredentialHelper) Erase(serverURL string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.tokens, serverURL)
+
+	out, err := h.execute("erase", []byte(serverURL))
+	if err != nil && strings.Contains(string(out), credentialsNotFoundMsg) {
+		return nil
+	}
+	return err
+}
+
+func (h *CredentialHelper) execute(action string, input []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, h.program, action) //nolint:gosec
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("the credential helper %s didn't %s the token within %s",
+				h.program, action, h.timeout)
+		}
+		// The error details are usually printed on stdout, as with the docker
+		// credential helpers, but we don't want to ignore stderr either.
+		msg := strings.TrimSpace(stdout.String() + " " + stderr.String())
+		return []byte(msg), fmt.Errorf("the credential helper %s failed to %s the token: %w: %s",
+			h.program, action, err, msg)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,118 @@
+package cloudapi
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib/testutils"
+)
+
+func TestCredentialHelper(t *testing.T) {
+	t.Parallel()
+
+	h := NewCredentialHelper(testutils.WriteFakeCredentialHelper(t))
+
+	_, err := h.Get("https://ingest.k6.io")
+	assert.ErrorIs(t, err, ErrCredentialsNotFound)
+
+	require.NoError(t, h.Store("https://ingest.k6.io", "mytoken"))
+	token, err := h.Get("https://ingest.k6.io")
+	require.NoError(t, err)
+	assert.Equal(t, "mytoken", token)
+
+	require.NoError(t, h.Erase("https://ingest.k6.io"))
+	require.NoError(t, h.Erase("https://ingest.k6.io"))
+	_, err = h.Get("https://ingest.k6.io")
+	assert.ErrorIs(t, err, ErrCredentialsNotFound)
+}
+
+func TestCredentialHelperCachesTheTokens(t *testing.T) {
+	t.Parallel()
+
+	helper := testutils.WriteFakeCredentialHelper(t)
+	calls := func() []string {
+		data, err := os.ReadFile(filepath.Join(filepath.Dir(helper), "calls")) //nolint:forbidigo
+		require.NoError(t, err)
+		return strings.Fields(string(data))
+	}
+
+	h := NewCredentialHelper(helper)
+	for i := 0; i < 3; i++ {
+		_, err := h.Get("https://ingest.k6.io")
+		assert.ErrorIs(t, err, ErrCredentialsNotFound)
+	}
+	assert.Equal(t, []string{"get"}, calls())
+
+	require.NoError(t, h.Store("https://ingest.k6.io", "mytoken"))
+	for i := 0; i < 3; i++ {
+		token, err := h.Get("https://ingest.k6.io")
+		require.NoError(t, err)
+		assert.Equal(t, "mytoken", token)
+	}
+	assert.Equal(t, []string{"get", "store", "get"}, calls())
+
+	// the token is cached for every server URL
+	_, err := h.Get("https://other.k6.io")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"get", "store", "get", "get"}, calls())
+
+	// and only by the same helper
+	token, err := NewCredentialHelper(helper).Get("https://ingest.k6.io")
+	require.NoError(t, err)
+	assert.Equal(t, "mytoken", token)
+	assert.Equal(t, []string{"get", "store", "get", "get", "get"}, calls())
+}
+
+func TestCredentialHelperTimeout(t *testing.T) {
+	t.Parallel()
+
+	helper := filepath.Join(filepath.Dir(testutils.WriteFakeCredentialHelper(t)), "k6-credential-hanging")
+	require.NoError(t, os.WriteFile(helper, []byte("#!/bin/sh\nexec sleep 10\n"), 0o700)) //nolint:forbidigo,gosec
+
+	h := NewCredentialHelper(helper)
+	h.timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	_, err := h.Get("https://ingest.k6.io")
+	assert.ErrorContains(t, err, "didn't get the token within 100ms")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestCredentialHelperNotFound(t *testing.T) {
+	t.Parallel()
+
+	h := NewCredentialHelper("k6-test-nonexistent")
+	assert.Equal(t, "k6-credential-k6-test-nonexistent", h.program)
+
+	_, err := h.Get("https://ingest.k6.io")
+	assert.ErrorContains(t, err, "the credential helper k6-credential-k6-test-nonexistent failed to get the token")
+}
+
+func TestGetConsolidatedConfigCredentialHelper(t *testing.T) {
+	t.Parallel()
+
+	helper := testutils.WriteFakeCredentialHelper(t)
+	jsonConf, err := json.Marshal(map[string]string{"credentialHelper": helper})
+	require.NoError(t, err)
+
+	config, err := GetConsolidatedConfig(jsonConf, nil, "", nil)
+	require.NoError(t, err)
+	assert.False(t, config.Token.Valid)
+
+	require.NoError(t, NewCredentialHelper(helper).Store("https://ingest.k6.io", "helpertoken"))
+	config, err = GetConsolidatedConfig(jsonConf, nil, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "helpertoken", config.Token.String)
+
+	// an explicitly configured token has priority
+	config, err = GetConsolidatedConfig(jsonConf, map[string]string{"K6_CLOUD_TOKEN": "envtoken"}, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "envtoken", config.Token.String)
+}
@@ -0,0 +1,33 @@
+package cloudapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// TestRunEventAnnotation is the type of the events created from the annotations
+// of the test run.
+const TestRunEventAnnotation = "annotation"
+
+// TestRunEvent is something that happened during a test run, which is shown
+// on its timeline in the cloud.
+type TestRunEvent struct {
+	Type string            `json:"type"`
+	Time time.Time         `json:"time"`
+	Text string            `json:"text"`
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// CreateTestRunEvent adds the event to the timeline of the test run with the
+// given reference ID.
+func (c *Client) CreateTestRunEvent(referenceID string, event TestRunEvent) error {
+	requestURL := fmt.Sprintf("%s/tests/%s/events", c.baseURL, url.PathEscape(referenceID))
+	req, err := c.NewRequest(http.MethodPost, requestURL, event)
+	if err != nil {
+		return err
+	}
+
+	return c.Do(req, nil)
+}
@@ -0,0 +1,41 @@
+package cloudapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib/testutils"
+)
+
+func TestCreateTestRunEvent(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/tests/123/events", r.URL.Path)
+
+		var event TestRunEvent
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		assert.Equal(t, TestRunEvent{
+			Type: TestRunEventAnnotation,
+			Time: time.Date(2023, 10, 1, 10, 0, 0, 0, time.UTC),
+			Text: "DB failover started",
+			Tags: map[string]string{"team": "db"},
+		}, event)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(testutils.NewLogger(t), "token", server.URL, "1.0", 1*time.Second)
+	require.NoError(t, client.CreateTestRunEvent("123", TestRunEvent{
+		Type: TestRunEventAnnotation,
+		Time: time.Date(2023, 10, 1, 10, 0, 0, 0, time.UTC),
+		Text: "DB failover started",
+		Tags: map[string]string{"team": "db"},
+	}))
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 
+	"google.golang.org/protobuf/proto"
+
 	"go.k6.io/k6/cloudapi/insights/proto/v1/ingester"
 	"go.k6.io/k6/cloudapi/insights/proto/v1/k6"
 )
@@ -48,6 +50,17 @@ func newCreateRequestMetadataRequest(requestMetadata RequestMetadata) (*ingester
 	}, nil
 }
 
+// RequestMetadataSize returns the approximate size in bytes that the request
+// metadata adds to a batch sent to the ingester, or 0 if it can't be sent.
+func RequestMetadataSize(requestMetadata RequestMetadata) int {
+	req, err := newCreateRequestMetadataRequest(requestMetadata)
+	if err != nil {
+		return 0
+	}
+
+	return proto.Size(req)
+}
+
 func setProtocolLabels(rm *k6.RequestMetadata, labels ProtocolLabels) error {
 	// TODO(lukasz, other-proto-support): Set other protocol labels.
 	switch l := labels.(type) {
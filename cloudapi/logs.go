@@ -2,14 +2,15 @@ package cloudapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -90,13 +91,22 @@ func labelsToLogrusFields(labels map[string]string) logrus.Fields {
 	return fields
 }
 
-func (c *Config) logtailConn(ctx context.Context, referenceID string, since time.Time) (*websocket.Conn, error) {
+func (c *Config) logtailConn(
+	ctx context.Context, referenceID string, since time.Time, matchers []LabelMatcher,
+) (*websocket.Conn, error) {
 	u, err := url.Parse(c.LogsTailURL.String)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't parse cloud logs host %w", err)
 	}
 
-	u.RawQuery = fmt.Sprintf(`query={test_run_id="%s"}&start=%d`, referenceID, since.UnixNano())
+	selector := fmt.Sprintf(`test_run_id="%s"`, referenceID)
+	for _, m := range matchers {
+		selector += "," + m.String()
+	}
+	u.RawQuery = url.Values{
+		"query": {"{" + selector + "}"},
+		"start": {strconv.FormatInt(since.UnixNano(), 10)},
+	}.Encode()
 
 	headers := make(http.Header)
 	headers.Add("Sec-WebSocket-Protocol", "token="+c.Token.String)
@@ -121,11 +131,96 @@ func (c *Config) logtailConn(ctx context.Context, referenceID string, since time
 func (c *Config) StreamLogsToLogger(
 	ctx context.Context, logger logrus.FieldLogger, referenceID string, tailFrom time.Duration,
 ) error {
+	stats, err := c.streamLogs(ctx, logger, referenceID, time.Now().Add(-tailFrom), nil, 0, func(m *msg) {
+		m.Log(logger)
+	})
+	stats.Log(logger)
+	return err
+}
+
+// StreamLogsOptions configures what is streamed by StreamLogs.
+type StreamLogsOptions struct {
+	// Since is the time from which the logs are streamed, e.g. the start of
+	// the test run to get all of its logs.
+	Since time.Time
+
+	// Only the entries with this level or a more severe one are streamed.
+	MinLevel logrus.Level
+
+	// Only the entries whose labels match all of the matchers are streamed.
+	Matchers []LabelMatcher
+
+	// If it's positive, the streaming stops once no new entries were
+	// received for that long, instead of following the logs until ctx is done.
+	IdleTimeout time.Duration
+}
+
+// LogsStreamStats contains the counters of a logs stream, to help with
+// troubleshooting missing log lines.
+type LogsStreamStats struct {
+	// How many times the connection to the logs service was restored.
+	Reconnects int
+	// How many entries the logs service dropped, e.g. because of rate limits.
+	DroppedLines int
+	// How many entries were received more than once, usually after a
+	// reconnect, and were delivered only once.
+	Duplicates int
+}
+
+// Log writes the counters to the logger, as a warning if some lines were dropped.
+func (s LogsStreamStats) Log(logger logrus.FieldLogger) {
+	e := logger.WithFields(logrus.Fields{
+		"reconnects": s.Reconnects, "dropped_lines": s.DroppedLines, "duplicates": s.Duplicates,
+	})
+	if s.DroppedLines > 0 {
+		e.Warn("The cloud logs service dropped some log lines")
+	} else {
+		e.Debug("Finished streaming the cloud logs")
+	}
+}
+
+// StreamLogs streams the log entries of the test run that match the options
+// to the callback, in the order they are received, until ctx is done, an
+// error occurs or, if an IdleTimeout is set, no more entries are received.
+// Every entry is delivered exactly once, even if the connection to the logs
+// service has to be restored.
+func (c *Config) StreamLogs(
+	ctx context.Context, logger logrus.FieldLogger, referenceID string, opts StreamLogsOptions,
+	callback func(TestRunLogEntry),
+) (LogsStreamStats, error) {
+	return c.streamLogs(ctx, logger, referenceID, opts.Since, opts.Matchers, opts.IdleTimeout, func(m *msg) {
+		for _, entry := range m.entries() {
+			if entry.matches(opts) {
+				callback(entry)
+			}
+		}
+	})
+}
+
+// logsDedupWindow is how many of the most recently delivered log entries are
+// remembered, so they aren't delivered again after a reconnect.
+const logsDedupWindow = 10000
+
+// streamLogs reads the messages of the logtail connection and passes them to
+// handle, without the entries that were already delivered. If the connection
+// is lost, it's restored from the lowest of the most recent delivered
+// timestamps of the streams, inclusive, so no entries are skipped, and the
+// replayed ones are deduplicated.
+//
+//nolint:funlen
+func (c *Config) streamLogs(
+	ctx context.Context, logger logrus.FieldLogger, referenceID string, since time.Time,
+	matchers []LabelMatcher, idleTimeout time.Duration, handle func(*msg),
+) (stats LogsStreamStats, err error) {
+	// The connection is closed once ctx is done, or when we return.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	var mconn sync.Mutex
 
-	conn, err := c.logtailConn(ctx, referenceID, time.Now().Add(-tailFrom))
+	conn, err := c.logtailConn(ctx, referenceID, since, matchers)
 	if err != nil {
-		return err
+		return stats, err
 	}
 
 	go func() {
@@ -142,56 +237,79 @@ func (c *Config) StreamLogsToLogger(
 		_ = conn.Close()
 	}()
 
+	// The consumer is the only one that updates the cursor, the dedup window
+	// and the counters other than the reconnects. Before reconnecting, we wait
+	// for all of the pending messages to be handled, so the cursor has exactly
+	// the most recent delivered timestamp of every stream.
+	var pending sync.WaitGroup
+	cursor := newLogsCursor()
+	dedup := newLogsDeduplicator(logsDedupWindow)
+	var duplicates, droppedLines int
+
 	msgBuffer := make(chan []byte, 10)
-	defer close(msgBuffer)
+	consumerDone := make(chan struct{})
+	defer func() {
+		// wait for all of the received messages to be handled
+		close(msgBuffer)
+		<-consumerDone
+		stats.Duplicates, stats.DroppedLines = duplicates, droppedLines
+	}()
 
-	var mostRecent int64
 	go func() {
+		defer close(consumerDone)
 		for message := range msgBuffer {
 			var m msg
 			err := easyjson.Unmarshal(message, &m)
 			if err != nil {
 				logger.WithError(err).Errorf("couldn't unmarshal a message from the cloud: %s", string(message))
-
+				pending.Done()
 				continue
 			}
-			ts := m.Log(logger)
-			atomic.StoreInt64(&mostRecent, ts)
+
+			fresh, dups := dedup.filter(&m)
+			duplicates += dups
+			droppedLines += len(fresh.DroppedEntries)
+			handle(fresh)
+			cursor.update(fresh)
+			pending.Done()
 		}
 	}()
 
 	for {
+		if idleTimeout > 0 {
+			mconn.Lock()
+			_ = conn.SetReadDeadline(time.Now().Add(idleTimeout))
+			mconn.Unlock()
+		}
 		_, message, err := conn.ReadMessage()
 		select { // check if we should stop before continuing
 		case <-ctx.Done():
-			return nil
+			return stats, nil
 		default:
 		}
 
+		var netErr net.Error
+		if idleTimeout > 0 && errors.As(err, &netErr) && netErr.Timeout() {
+			return stats, nil
+		}
+
 		if err != nil {
 			logger.WithError(err).Warn("error reading a log message from the cloud, trying to establish a fresh connection with the logs service...") //nolint:lll
 
-			var since time.Time
-			if ts := atomic.LoadInt64(&mostRecent); ts > 0 {
-				// add 1ns for avoid possible repetition
-				since = time.Unix(0, ts).Add(time.Nanosecond)
-			} else {
-				since = time.Now()
+			// The logs are resumed from the stream that lags the most, so the
+			// entries of the other streams after it are received again, and
+			// then deduplicated.
+			pending.Wait()
+			resumeFrom := since
+			if ts := cursor.resumeFrom(); ts > 0 {
+				resumeFrom = time.Unix(0, ts)
 			}
-
-			// TODO: avoid the "logical" race condition
-			// The case explained:
-			// * The msgBuffer consumer is slow
-			// * ReadMessage is fast and adds at least one more message in the buffer
-			// * An error is got in the meantime and the re-dialing procedure is tried
-			// * Then the latest timestamp used will not be the real latest received
-			// * because it is still waiting to be processed.
-			// In the case the connection will be restored then the first message will be a duplicate.
-			newconn, errd := c.logtailConn(ctx, referenceID, since)
+			newconn, errd := c.logtailConn(ctx, referenceID, resumeFrom, matchers)
 			if errd != nil {
 				// return the main error
-				return err
+				return stats, err
 			}
+			stats.Reconnects++
 
 			mconn.Lock()
 			conn = newconn
@@ -199,9 +317,11 @@ func (c *Config) StreamLogsToLogger(
 			continue
 		}
 
+		pending.Add(1)
 		select {
 		case <-ctx.Done():
-			return nil
+			pending.Done()
+			return stats, nil
 		case msgBuffer <- message:
 		}
 	}
@@ -0,0 +1,140 @@
+package cloudapi
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// logEntryKey identifies a log entry, since the logs service doesn't give
+// the entries IDs.
+type logEntryKey struct {
+	timestamp string
+	labels    string
+	line      string
+}
+
+func newLogEntryKey(timestamp string, labels map[string]string, line string) logEntryKey {
+	return logEntryKey{timestamp: timestamp, labels: labelsKey(labels), line: line}
+}
+
+// labelsKey returns the labels as a string, with the names in a stable order.
+func labelsKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[name]))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+// logsDeduplicator remembers the keys of the last delivered log entries, up
+// to a maximum number, so the entries that are received again are skipped.
+type logsDeduplicator struct {
+	seen  map[logEntryKey]struct{}
+	order []logEntryKey // a ring buffer of the keys in seen
+	next  int
+}
+
+func newLogsDeduplicator(size int) *logsDeduplicator {
+	return &logsDeduplicator{
+		seen:  make(map[logEntryKey]struct{}, size),
+		order: make([]logEntryKey, 0, size),
+	}
+}
+
+// add returns false if the key was already seen, otherwise it remembers it,
+// forgetting the oldest one if the window is full.
+func (d *logsDeduplicator) add(key logEntryKey) bool {
+	if _, ok := d.seen[key]; ok {
+		return false
+	}
+	d.seen[key] = struct{}{}
+	if len(d.order) < cap(d.order) {
+		d.order = append(d.order, key)
+		return true
+	}
+	delete(d.seen, d.order[d.next])
+	d.order[d.next] = key
+	d.next = (d.next + 1) % len(d.order)
+	return true
+}
+
+// filter returns a copy of the message without the entries that were already
+// seen, and how many of them there were.
+func (d *logsDeduplicator) filter(m *msg) (*msg, int) {
+	var duplicates int
+	fresh := &msg{}
+	for _, stream := range m.Streams {
+		values := make([][2]string, 0, len(stream.Values))
+		for _, value := range stream.Values {
+			if d.add(newLogEntryKey(value[0], stream.Stream, value[1])) {
+				values = append(values, value)
+			} else {
+				duplicates++
+			}
+		}
+		if len(values) > 0 {
+			fresh.Streams = append(fresh.Streams, msgStreams{Stream: stream.Stream, Values: values})
+		}
+	}
+	for _, dropped := range m.DroppedEntries {
+		if d.add(newLogEntryKey(dropped.Timestamp, dropped.Labels, "")) {
+			fresh.DroppedEntries = append(fresh.DroppedEntries, dropped)
+		} else {
+			duplicates++
+		}
+	}
+	return fresh, duplicates
+}
+
+// logsCursor tracks the most recent delivered timestamp of every stream, since
+// the streams don't advance together. The logs are resumed from the one of
+// the stream that lags the most, so no entries of any stream are skipped.
+type logsCursor struct {
+	mu      sync.Mutex
+	streams map[string]int64
+}
+
+func newLogsCursor() *logsCursor {
+	return &logsCursor{streams: make(map[string]int64)}
+}
+
+// update records the most recent timestamps of the streams in the message.
+func (c *logsCursor) update(m *msg) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, stream := range m.Streams {
+		key := labelsKey(stream.Stream)
+		for _, value := range stream.Values {
+			if nsec, _ := strconv.ParseInt(value[0], 10, 64); nsec > c.streams[key] {
+				c.streams[key] = nsec
+			}
+		}
+	}
+}
+
+// resumeFrom returns the lowest of the most recent timestamps of the streams,
+// or 0 if no entries were delivered yet.
+func (c *logsCursor) resumeFrom() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var ts int64
+	for _, nsec := range c.streams {
+		if ts == 0 || nsec < ts {
+			ts = nsec
+		}
+	}
+	return ts
+}
@@ -0,0 +1,189 @@
+package cloudapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib/testutils"
+)
+
+func TestLogsDeduplicatorWindow(t *testing.T) {
+	t.Parallel()
+
+	d := newLogsDeduplicator(2)
+	a := newLogEntryKey("1", map[string]string{"b": "2", "a": "1"}, "line")
+	b := newLogEntryKey("1", map[string]string{"a": "1", "b": "2"}, "other line")
+	c := newLogEntryKey("2", nil, "line")
+
+	assert.True(t, d.add(a))
+	assert.False(t, d.add(newLogEntryKey("1", map[string]string{"a": "1", "b": "2"}, "line")))
+	assert.True(t, d.add(b))
+	assert.True(t, d.add(c))  // a is forgotten
+	assert.False(t, d.add(b)) // but b is still in the window
+	assert.True(t, d.add(a))
+}
+
+// droppingLogsServer is a stand-in for the logs service which sends the
+// entries since the requested start, including the ones with the same
+// timestamp, and rudely closes every connection after a few messages.
+type droppingLogsServer struct {
+	t         *testing.T
+	entries   []string // messages with a single entry, ordered by their timestamps
+	timestamp []int64
+	perConn   int
+
+	mu     sync.Mutex
+	starts []int64
+}
+
+func (s *droppingLogsServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	start, err := strconv.ParseInt(req.URL.Query().Get("start"), 10, 64)
+	require.NoError(s.t, err)
+	s.mu.Lock()
+	s.starts = append(s.starts, start)
+	s.mu.Unlock()
+
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, req, nil)
+	require.NoError(s.t, err)
+	defer func() { _ = conn.Close() }()
+
+	sent := 0
+	for i, entry := range s.entries {
+		if s.timestamp[i] < start {
+			continue
+		}
+		if sent == s.perConn {
+			return // drop the connection without a close message
+		}
+		require.NoError(s.t, conn.WriteMessage(websocket.TextMessage, []byte(entry)))
+		sent++
+	}
+	// all of the entries were sent, the client should stop because of the idle timeout
+	_, _, _ = conn.ReadMessage()
+}
+
+func TestStreamLogsExactlyOnce(t *testing.T) {
+	t.Parallel()
+
+	srv := &droppingLogsServer{t: t, perConn: 4}
+	var expected []string
+	base := int64(1627351200000000000)
+	for i := 0; i < 10; i++ {
+		// pairs of entries share their timestamp, so they are sent again
+		// after every reconnect that resumes from one of them
+		ts := base + int64(i/2)
+		line := fmt.Sprintf("line %d", i)
+		srv.entries = append(srv.entries, fmt.Sprintf(
+			`{"streams":[{"stream":{"level":"info","key":"stream%d"},"values":[["%d",%q]]}]}`, i%2, ts, line))
+		srv.timestamp = append(srv.timestamp, ts)
+		expected = append(expected, line)
+	}
+	srv.entries = append(srv.entries, fmt.Sprintf(
+		`{"dropped_entries":[{"labels":{"key":"stream0"},"timestamp":"%d"}]}`, base+5))
+	srv.timestamp = append(srv.timestamp, base+5)
+	expected = append(expected, "dropped")
+
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	config := Config{
+		LogsTailURL: null.StringFrom("ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/tail"),
+	}
+	var received []string
+	stats, err := config.StreamLogs(context.Background(), testutils.NewLogger(t), "123", StreamLogsOptions{
+		Since:       time.Unix(0, base),
+		MinLevel:    logrus.TraceLevel,
+		IdleTimeout: 200 * time.Millisecond,
+	}, func(e TestRunLogEntry) {
+		received = append(received, e.Message)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, received, "every entry should be delivered exactly once and in order")
+	assert.Equal(t, len(srv.starts)-1, stats.Reconnects)
+	assert.Positive(t, stats.Reconnects)
+	assert.Positive(t, stats.Duplicates)
+	assert.Equal(t, 1, stats.DroppedLines)
+
+	// every reconnect resumes from the most recent delivered entry
+	for i := 1; i < len(srv.starts); i++ {
+		assert.Greater(t, srv.starts[i], srv.starts[i-1])
+	}
+}
+
+func TestStreamLogsResumesFromTheLaggingStream(t *testing.T) {
+	t.Parallel()
+
+	base := int64(1627351200000000000)
+	streamMsg := func(key string, from, to int) string {
+		values := make([]string, 0, to-from+1)
+		for i := from; i <= to; i++ {
+			values = append(values, fmt.Sprintf(`["%d","%s %d"]`, base+int64(i), key, i))
+		}
+		return fmt.Sprintf(`{"stream":{"level":"info","key":%q},"values":[%s]}`, key, strings.Join(values, ","))
+	}
+
+	var (
+		mu     sync.Mutex
+		starts []int64
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start, err := strconv.ParseInt(req.URL.Query().Get("start"), 10, 64)
+		require.NoError(t, err)
+		mu.Lock()
+		starts = append(starts, start)
+		reconnect := len(starts) > 1
+		mu.Unlock()
+
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, req, nil)
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+
+		if !reconnect {
+			// the stream b lags behind the stream a, then the connection is dropped
+			message := fmt.Sprintf(`{"streams":[%s,%s]}`, streamMsg("a", 1, 4), streamMsg("b", 1, 2))
+			require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(message)))
+			return
+		}
+		from := int(start - base)
+		message := fmt.Sprintf(`{"streams":[%s,%s]}`, streamMsg("a", from, 4), streamMsg("b", from, 4))
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(message)))
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	config := Config{
+		LogsTailURL: null.StringFrom("ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/tail"),
+	}
+	var received []string
+	stats, err := config.StreamLogs(context.Background(), testutils.NewLogger(t), "123", StreamLogsOptions{
+		Since:       time.Unix(0, base),
+		MinLevel:    logrus.TraceLevel,
+		IdleTimeout: 200 * time.Millisecond,
+	}, func(e TestRunLogEntry) {
+		received = append(received, e.Message)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a 1", "b 1", "a 2", "b 2", "a 3", "a 4", "b 3", "b 4"}, received)
+	assert.Equal(t, 1, stats.Reconnects)
+	assert.Equal(t, 4, stats.Duplicates) // a 2, a 3, a 4 and b 2
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, starts, 2)
+	assert.Equal(t, base+2, starts[1], "the logs should be resumed from the lagging stream")
+}
@@ -0,0 +1,178 @@
+package cloudapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestRunLogEntry is a single log entry of a cloud test run.
+type TestRunLogEntry struct {
+	Time    time.Time         `json:"time"`
+	Level   string            `json:"level"`
+	Message string            `json:"message"`
+	Labels  map[string]string `json:"labels,omitempty"`
+
+	// Dropped is true for the placeholders of the entries that the logs
+	// service dropped, e.g. because of rate limiting.
+	Dropped bool `json:"dropped,omitempty"`
+}
+
+// entries returns the Streams and Dropped Entries of the message as separate
+// log entries, sorted by their time.
+func (m *msg) entries() []TestRunLogEntry {
+	var entries []TestRunLogEntry
+	for _, stream := range m.Streams {
+		labels := make(map[string]string, len(stream.Stream))
+		for key, val := range stream.Stream {
+			if key != "level" {
+				labels[key] = val
+			}
+		}
+		for _, value := range stream.Values {
+			nsec, _ := strconv.ParseInt(value[0], 10, 64)
+			entries = append(entries, TestRunLogEntry{
+				Time:    time.Unix(0, nsec),
+				Level:   stream.Stream["level"],
+				Message: value[1],
+				Labels:  labels,
+			})
+		}
+	}
+	for _, dropped := range m.DroppedEntries {
+		nsec, _ := strconv.ParseInt(dropped.Timestamp, 10, 64)
+		entries = append(entries, TestRunLogEntry{
+			Time:    time.Unix(0, nsec),
+			Level:   logrus.WarnLevel.String(),
+			Message: "dropped",
+			Labels:  dropped.Labels,
+			Dropped: true,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	return entries
+}
+
+// matches returns whether the entry should be streamed with the options.
+// Entries with an unknown level are never filtered out by their level.
+func (e TestRunLogEntry) matches(opts StreamLogsOptions) bool {
+	if lvl, err := logrus.ParseLevel(e.Level); err == nil && lvl > opts.MinLevel {
+		return false
+	}
+	for _, m := range opts.Matchers {
+		if !m.Matches(e.Labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// LabelMatcher is a Loki-style matcher of log labels, e.g. `scenario="foo"`
+// or `instance=~"i-.*"`.
+type LabelMatcher struct {
+	Name  string
+	Op    string
+	Value string
+
+	re *regexp.Regexp
+}
+
+// The supported operators of the LabelMatcher, the longer ones first so
+// they're matched before their prefixes.
+var labelMatcherOps = []string{"=~", "!~", "!=", "="} //nolint:gochecknoglobals
+
+var labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ParseLabelMatchers parses a comma-separated list of label matchers, with
+// or without the surrounding braces of a Loki stream selector, e.g.
+// `{scenario="foo", instance=~"i-.*"}`.
+func ParseLabelMatchers(selector string) ([]LabelMatcher, error) {
+	rest := strings.TrimSpace(selector)
+	if strings.HasPrefix(rest, "{") && strings.HasSuffix(rest, "}") {
+		rest = strings.TrimSpace(rest[1 : len(rest)-1])
+	}
+
+	var matchers []LabelMatcher
+	for rest != "" {
+		m, remaining, err := parseLabelMatcher(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %w", selector, err)
+		}
+		matchers = append(matchers, m)
+
+		rest = strings.TrimSpace(remaining)
+		if rest == "" {
+			break
+		}
+		if rest[0] != ',' {
+			return nil, fmt.Errorf("invalid label selector %q: expected a comma before %q", selector, rest)
+		}
+		rest = strings.TrimSpace(rest[1:])
+	}
+	return matchers, nil
+}
+
+func parseLabelMatcher(s string) (LabelMatcher, string, error) {
+	opIndex := strings.IndexAny(s, "=!")
+	if opIndex < 0 {
+		return LabelMatcher{}, "", fmt.Errorf("missing operator in %q", s)
+	}
+	m := LabelMatcher{Name: strings.TrimSpace(s[:opIndex])}
+	if !labelNameRegexp.MatchString(m.Name) {
+		return LabelMatcher{}, "", fmt.Errorf("invalid label name %q", m.Name)
+	}
+	for _, op := range labelMatcherOps {
+		if strings.HasPrefix(s[opIndex:], op) {
+			m.Op = op
+			break
+		}
+	}
+	if m.Op == "" {
+		return LabelMatcher{}, "", fmt.Errorf("invalid operator in %q", s)
+	}
+
+	rest := strings.TrimSpace(s[opIndex+len(m.Op):])
+	quoted, err := strconv.QuotedPrefix(rest)
+	if err != nil {
+		return LabelMatcher{}, "", fmt.Errorf("the value of the %q label should be quoted", m.Name)
+	}
+	if m.Value, err = strconv.Unquote(quoted); err != nil {
+		return LabelMatcher{}, "", err
+	}
+	if m.Op == "=~" || m.Op == "!~" {
+		// Like in Loki, the regular expressions are fully anchored.
+		if m.re, err = regexp.Compile("^(?:" + m.Value + ")$"); err != nil {
+			return LabelMatcher{}, "", err
+		}
+	}
+
+	return m, rest[len(quoted):], nil
+}
+
+// Matches returns whether the labels match. A missing label is treated as if
+// it had an empty value.
+func (m LabelMatcher) Matches(labels map[string]string) bool {
+	value := labels[m.Name]
+	switch m.Op {
+	case "=":
+		return value == m.Value
+	case "!=":
+		return value != m.Value
+	case "=~":
+		return m.re.MatchString(value)
+	case "!~":
+		return !m.re.MatchString(value)
+	default:
+		return false
+	}
+}
+
+func (m LabelMatcher) String() string {
+	return m.Name + m.Op + strconv.Quote(m.Value)
+}
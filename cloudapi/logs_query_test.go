@@ -0,0 +1,144 @@
+package cloudapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mailru/easyjson"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib/testutils"
+)
+
+func TestParseLabelMatchers(t *testing.T) {
+	t.Parallel()
+
+	matchers, err := ParseLabelMatchers(`{scenario="foo", instance=~"i-.*",lz!="amazon:us:ashburn" , x!~"a|b"}`)
+	require.NoError(t, err)
+	require.Len(t, matchers, 4)
+	assert.Equal(t, `scenario="foo"`, matchers[0].String())
+	assert.Equal(t, `instance=~"i-.*"`, matchers[1].String())
+	assert.Equal(t, `lz!="amazon:us:ashburn"`, matchers[2].String())
+	assert.Equal(t, `x!~"a|b"`, matchers[3].String())
+
+	matchers, err = ParseLabelMatchers(`scenario="with \"quotes\", and a comma"`)
+	require.NoError(t, err)
+	require.Len(t, matchers, 1)
+	assert.Equal(t, `with "quotes", and a comma`, matchers[0].Value)
+
+	matchers, err = ParseLabelMatchers("")
+	require.NoError(t, err)
+	assert.Empty(t, matchers)
+
+	for _, invalid := range []string{`scenario`, `scenario=foo`, `1a="b"`, `a="b" b="c"`, `a=~"("`, `a<"b"`} {
+		_, err = ParseLabelMatchers(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestLabelMatcherMatches(t *testing.T) {
+	t.Parallel()
+
+	labels := map[string]string{"scenario": "foo", "instance": "i-123"}
+	testCases := map[string]bool{
+		`scenario="foo"`:     true,
+		`scenario="fo"`:      false,
+		`scenario!="foo"`:    false,
+		`instance=~"i-\\d+"`: true,
+		`instance=~"i-1"`:    false, // the regular expressions are anchored
+		`instance!~"i-.*"`:   false,
+		`lz=""`:              true,
+		`lz!=""`:             false,
+	}
+	for selector, expected := range testCases {
+		matchers, err := ParseLabelMatchers(selector)
+		require.NoError(t, err)
+		require.Len(t, matchers, 1)
+		assert.Equal(t, expected, matchers[0].Matches(labels), selector)
+	}
+}
+
+func TestMsgEntries(t *testing.T) {
+	t.Parallel()
+
+	var m msg
+	require.NoError(t, easyjson.Unmarshal([]byte(`{
+		"streams": [{
+			"stream": {"level": "error", "scenario": "foo"},
+			"values": [["1598282752000000002", "second"], ["1598282752000000001", "first"]]
+		}],
+		"dropped_entries": [{"labels": {"scenario": "bar"}, "timestamp": "1598282752000000003"}]
+	}`), &m))
+
+	entries := m.entries()
+	require.Len(t, entries, 3)
+	assert.Equal(t, TestRunLogEntry{
+		Time:    time.Unix(0, 1598282752000000001),
+		Level:   "error",
+		Message: "first",
+		Labels:  map[string]string{"scenario": "foo"},
+	}, entries[0])
+	assert.Equal(t, "second", entries[1].Message)
+	assert.True(t, entries[2].Dropped)
+	assert.Equal(t, "warning", entries[2].Level)
+}
+
+func TestStreamLogs(t *testing.T) {
+	t.Parallel()
+
+	upgrader := websocket.Upgrader{}
+	var query, rawQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		query, rawQuery = req.URL.Query().Get("query"), req.URL.RawQuery
+		conn, err := upgrader.Upgrade(w, req, nil)
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+
+		for i, level := range []string{"info", "error", "warning", "debug"} {
+			err = conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(
+				`{"streams":[{"stream":{"level":%q,"scenario":"foo"},"values":[["%d","line %d"]]}]}`,
+				level, 1598282752000000000+i, i)))
+			require.NoError(t, err)
+		}
+		// the logs service shouldn't send this entry, but we filter it anyway
+		err = conn.WriteMessage(websocket.TextMessage,
+			[]byte(`{"streams":[{"stream":{"level":"error","scenario":"bar"},"values":[["1598282752000000009","other"]]}]}`))
+		require.NoError(t, err)
+
+		// the client should stop reading because of the idle timeout
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer srv.Close()
+
+	config := Config{
+		LogsTailURL: null.StringFrom("ws" + strings.TrimPrefix(srv.URL, "http") + "/api/v1/tail"),
+	}
+	matchers, err := ParseLabelMatchers(`scenario="foo"`)
+	require.NoError(t, err)
+
+	var entries []TestRunLogEntry
+	_, err = config.StreamLogs(context.Background(), testutils.NewLogger(t), "123", StreamLogsOptions{
+		Since:       time.Unix(0, 1598282752000000000),
+		MinLevel:    logrus.WarnLevel,
+		Matchers:    matchers,
+		IdleTimeout: 200 * time.Millisecond,
+	}, func(e TestRunLogEntry) {
+		entries = append(entries, e)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `{test_run_id="123",scenario="foo"}`, query)
+	assert.Equal(t, "query=%7Btest_run_id%3D%22123%22%2Cscenario%3D%22foo%22%7D&start=1598282752000000000", rawQuery)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "line 1", entries[0].Message)
+	assert.Equal(t, "line 2", entries[1].Message)
+}
@@ -0,0 +1,67 @@
+package cloudapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.k6.io/k6/metrics"
+)
+
+// TestRunSummary holds the results of a cloud test run, already aggregated by
+// the cloud, in a shape that mirrors the data passed to handleSummary().
+type TestRunSummary struct {
+	TestRunDurationMs float64                  `json:"test_run_duration_ms"`
+	Metrics           map[string]SummaryMetric `json:"metrics"`
+	RootGroup         SummaryGroup             `json:"root_group"`
+}
+
+// SummaryMetric holds the aggregated values and the threshold results of a
+// single metric or submetric of a cloud test run.
+type SummaryMetric struct {
+	Type       metrics.MetricType          `json:"type"`
+	Contains   metrics.ValueType           `json:"contains"`
+	Values     map[string]float64          `json:"values"`
+	Thresholds map[string]SummaryThreshold `json:"thresholds"`
+}
+
+// SummaryThreshold is the final result of a single threshold.
+type SummaryThreshold struct {
+	OK bool `json:"ok"`
+}
+
+// SummaryGroup holds the checks and sub-groups of a group of a cloud test run.
+type SummaryGroup struct {
+	Name   string         `json:"name"`
+	Groups []SummaryGroup `json:"groups"`
+	Checks []SummaryCheck `json:"checks"`
+}
+
+// SummaryCheck holds the results of a single check of a cloud test run.
+type SummaryCheck struct {
+	Name   string `json:"name"`
+	Passes int64  `json:"passes"`
+	Fails  int64  `json:"fails"`
+}
+
+// GetTestRunSummary returns the aggregated results of the test run with the
+// given reference ID. The trendStats are the values that should be calculated
+// for the trend metrics, in the same format as the summaryTrendStats option.
+func (c *Client) GetTestRunSummary(referenceID string, trendStats []string) (*TestRunSummary, error) {
+	requestURL := fmt.Sprintf("%s/tests/%s/summary", c.baseURL, url.PathEscape(referenceID))
+	if len(trendStats) > 0 {
+		requestURL += "?" + url.Values{"trend_stats": {strings.Join(trendStats, ",")}}.Encode()
+	}
+	req, err := c.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	trs := TestRunSummary{}
+	if err = c.Do(req, &trs); err != nil {
+		return nil, err
+	}
+
+	return &trs, nil
+}
@@ -0,0 +1,48 @@
+package cloudapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/metrics"
+)
+
+func TestGetTestRunSummary(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/tests/123/summary", r.URL.Path)
+		assert.Equal(t, "avg,p(95)", r.URL.Query().Get("trend_stats"))
+		fprintf(t, w, `{
+			"test_run_duration_ms": 1500,
+			"metrics": {
+				"http_req_duration": {
+					"type": "trend", "contains": "time",
+					"values": {"avg": 10, "p(95)": 20},
+					"thresholds": {"p(95)<10": {"ok": false}}
+				}
+			},
+			"root_group": {"name": "", "groups": [{"name": "g", "checks": [{"name": "c", "passes": 3, "fails": 1}]}]}
+		}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(testutils.NewLogger(t), "token", server.URL, "1.0", 1*time.Second)
+	summary, err := client.GetTestRunSummary("123", []string{"avg", "p(95)"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1500.0, summary.TestRunDurationMs)
+	require.Contains(t, summary.Metrics, "http_req_duration")
+	m := summary.Metrics["http_req_duration"]
+	assert.Equal(t, metrics.Trend, m.Type)
+	assert.Equal(t, metrics.Time, m.Contains)
+	assert.Equal(t, map[string]float64{"avg": 10, "p(95)": 20}, m.Values)
+	assert.Equal(t, map[string]SummaryThreshold{"p(95)<10": {OK: false}}, m.Thresholds)
+	require.Len(t, summary.RootGroup.Groups, 1)
+	assert.Equal(t, []SummaryCheck{{Name: "c", Passes: 3, Fails: 1}}, summary.RootGroup.Groups[0].Checks)
+}
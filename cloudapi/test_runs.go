@@ -0,0 +1,94 @@
+package cloudapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// TestRunDetails describes a single cloud test run, as returned by the test
+// run listing and lookup endpoints.
+type TestRunDetails struct {
+	ReferenceID   string       `json:"reference_id"`
+	Name          string       `json:"name"`
+	ProjectID     int64        `json:"project_id"`
+	RunStatus     RunStatus    `json:"run_status"`
+	RunStatusText string       `json:"run_status_text"`
+	ResultStatus  ResultStatus `json:"result_status"`
+	VUs           int64        `json:"vus"`
+	// Duration of test in seconds. -1 for unknown length, 0 for continuous running.
+	Duration int64     `json:"duration"`
+	Created  time.Time `json:"created"`
+	Started  null.Time `json:"started"`
+	Ended    null.Time `json:"ended"`
+}
+
+// ListTestRunsParams holds the filters and paging parameters for ListTestRuns.
+// Zero values aren't sent, so the server defaults are used for them.
+type ListTestRunsParams struct {
+	ProjectID    int64
+	CreatedAfter time.Time
+	Page         int
+	PageSize     int
+}
+
+// ListTestRunsResponse is a single page of test runs. NextPage is 0 when there
+// are no more pages to fetch.
+type ListTestRunsResponse struct {
+	TestRuns []TestRunDetails `json:"test_runs"`
+	NextPage int              `json:"next_page"`
+}
+
+// ListTestRuns returns a single page of the test runs visible with the current
+// token, starting from the most recent one and filtered by the given params.
+func (c *Client) ListTestRuns(params ListTestRunsParams) (*ListTestRunsResponse, error) {
+	query := url.Values{}
+	if params.ProjectID != 0 {
+		query.Set("project_id", strconv.FormatInt(params.ProjectID, 10))
+	}
+	if !params.CreatedAfter.IsZero() {
+		query.Set("created_after", params.CreatedAfter.UTC().Format(time.RFC3339))
+	}
+	if params.Page > 0 {
+		query.Set("page", strconv.Itoa(params.Page))
+	}
+	if params.PageSize > 0 {
+		query.Set("page_size", strconv.Itoa(params.PageSize))
+	}
+
+	requestURL := fmt.Sprintf("%s/tests", c.baseURL)
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+	req, err := c.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ltrr := ListTestRunsResponse{}
+	if err = c.Do(req, &ltrr); err != nil {
+		return nil, err
+	}
+
+	return &ltrr, nil
+}
+
+// GetTestRun returns the details of the test run with the given reference ID.
+func (c *Client) GetTestRun(referenceID string) (*TestRunDetails, error) {
+	requestURL := fmt.Sprintf("%s/tests/%s", c.baseURL, url.PathEscape(referenceID))
+	req, err := c.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	trd := TestRunDetails{}
+	if err = c.Do(req, &trd); err != nil {
+		return nil, err
+	}
+
+	return &trd, nil
+}
@@ -0,0 +1,73 @@
+package cloudapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib/testutils"
+)
+
+func TestListTestRuns(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/v1/tests", r.URL.Path)
+		assert.Equal(t, "12", r.URL.Query().Get("project_id"))
+		assert.Equal(t, "2023-10-01T10:00:00Z", r.URL.Query().Get("created_after"))
+		assert.Equal(t, "2", r.URL.Query().Get("page"))
+		assert.Empty(t, r.URL.Query().Get("page_size"))
+
+		fprintf(t, w, `{"test_runs": [{
+			"reference_id": "123",
+			"name": "my test",
+			"project_id": 12,
+			"run_status": 3,
+			"run_status_text": "Finished",
+			"result_status": 1,
+			"created": "2023-10-02T10:00:00Z",
+			"started": "2023-10-02T10:00:05Z",
+			"ended": null
+		}], "next_page": 3}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(testutils.NewLogger(t), "token", server.URL, "1.0", 1*time.Second)
+	resp, err := client.ListTestRuns(ListTestRunsParams{
+		ProjectID:    12,
+		CreatedAfter: time.Date(2023, 10, 1, 10, 0, 0, 0, time.UTC),
+		Page:         2,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, resp.NextPage)
+	require.Len(t, resp.TestRuns, 1)
+	run := resp.TestRuns[0]
+	assert.Equal(t, "123", run.ReferenceID)
+	assert.Equal(t, RunStatusFinished, run.RunStatus)
+	assert.Equal(t, ResultStatusFailed, run.ResultStatus)
+	assert.True(t, run.Started.Valid)
+	assert.False(t, run.Ended.Valid)
+}
+
+func TestGetTestRun(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/v1/tests/123", r.URL.Path)
+		fprintf(t, w, `{"reference_id": "123", "name": "my test", "run_status": 2, "run_status_text": "Running"}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(testutils.NewLogger(t), "token", server.URL, "1.0", 1*time.Second)
+	run, err := client.GetTestRun("123")
+	require.NoError(t, err)
+
+	assert.Equal(t, "my test", run.Name)
+	assert.Equal(t, RunStatusRunning, run.RunStatus)
+	assert.Equal(t, "Running", run.RunStatusText)
+}
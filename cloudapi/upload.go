@@ -0,0 +1,177 @@
+package cloudapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ArchiveUploadChunkSize is the default size of the chunks in which archives
+// are uploaded, archives that are smaller than it are uploaded at once.
+const ArchiveUploadChunkSize = 5 * 1024 * 1024
+
+// uploadProgressStep is how many bytes of the archive have to be sent before
+// the upload progress is reported again, so it isn't reported on every read.
+const uploadProgressStep = 256 * 1024
+
+const (
+	chunkOffsetHeader = "K6-Chunk-Offset"
+	chunkSHA256Header = "K6-Chunk-SHA256"
+)
+
+// chunkedUploadSession is returned by the cloud when a chunked upload is
+// started. If an upload of an archive with the same checksum was interrupted
+// before, the cloud returns the same session with the chunks that it already
+// received, so only the missing ones have to be uploaded.
+type chunkedUploadSession struct {
+	UploadID       string `json:"upload_id"`
+	ChunkSize      int    `json:"chunk_size"`
+	UploadedChunks []int  `json:"uploaded_chunks"`
+}
+
+// uploadArchiveChunked uploads the archive data in chunks, with every chunk
+// being retried on its own, and then starts the test run with the fields,
+// once the cloud has verified the checksum of the whole archive.
+func (c *Client) uploadArchiveChunked(fields [][2]string, data []byte) (*CreateTestRunResponse, error) {
+	checksum := sha256.Sum256(data)
+	archiveSHA256 := hex.EncodeToString(checksum[:])
+
+	sessionURL := fmt.Sprintf("%s/archive-upload/chunked", c.baseURL)
+	req, err := c.NewRequest(http.MethodPost, sessionURL, struct {
+		Size      int    `json:"size"`
+		SHA256    string `json:"sha256"`
+		ChunkSize int    `json:"chunk_size"`
+	}{len(data), archiveSHA256, c.uploadChunkSize})
+	if err != nil {
+		return nil, err
+	}
+	session := chunkedUploadSession{}
+	if err = c.Do(req, &session); err != nil {
+		return nil, err
+	}
+	if session.UploadID == "" {
+		return nil, errors.New("failed to get an upload ID for the archive")
+	}
+
+	// The cloud might require a different chunk size than the one we asked for.
+	chunkSize := c.uploadChunkSize
+	if session.ChunkSize > 0 {
+		chunkSize = session.ChunkSize
+	}
+	uploaded := make(map[int]bool, len(session.UploadedChunks))
+	for _, index := range session.UploadedChunks {
+		uploaded[index] = true
+	}
+	if len(uploaded) > 0 {
+		c.logger.Debugf("Resuming the upload of the archive, %d chunks were already uploaded", len(uploaded))
+	}
+
+	total := int64(len(data))
+	for index, offset := 0, 0; offset < len(data); index, offset = index+1, offset+chunkSize {
+		end := offset + chunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		if !uploaded[index] {
+			err = c.uploadArchiveChunk(session.UploadID, index, offset, data[offset:end], total)
+			if err != nil {
+				return nil, fmt.Errorf("failed to upload chunk %d of the archive: %w", index, err)
+			}
+			continue
+		}
+		c.reportUploadProgress(int64(end), total)
+	}
+
+	completeData := make(map[string]string, len(fields)+1)
+	for _, field := range fields {
+		completeData[field[0]] = field[1]
+	}
+	completeData["sha256"] = archiveSHA256
+
+	completeURL := fmt.Sprintf("%s/archive-upload/chunked/%s/complete", c.baseURL, url.PathEscape(session.UploadID))
+	req, err = c.NewRequest(http.MethodPost, completeURL, completeData)
+	if err != nil {
+		return nil, err
+	}
+	ctrr := CreateTestRunResponse{}
+	if err = c.Do(req, &ctrr); err != nil {
+		return nil, err
+	}
+	c.handleLogEntriesFromCloud(ctrr)
+	return &ctrr, nil
+}
+
+func (c *Client) uploadArchiveChunk(uploadID string, index, offset int, chunk []byte, total int64) error {
+	chunkURL := fmt.Sprintf("%s/archive-upload/chunked/%s/chunks/%d", c.baseURL, url.PathEscape(uploadID), index)
+	req, err := http.NewRequest(http.MethodPut, chunkURL, nil) //nolint:noctx
+	if err != nil {
+		return err
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(c.newUploadProgressReader(chunk, int64(offset), total)), nil
+	}
+	req.Body, _ = req.GetBody()
+	req.ContentLength = int64(len(chunk))
+
+	checksum := sha256.Sum256(chunk)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set(chunkOffsetHeader, strconv.Itoa(offset))
+	req.Header.Set(chunkSHA256Header, hex.EncodeToString(checksum[:]))
+
+	return c.Do(req, nil)
+}
+
+func (c *Client) reportUploadProgress(sent, total int64) {
+	if c.uploadProgress == nil {
+		return
+	}
+	// The body of a request that is retried could still be read by the HTTP
+	// transport, while the body of the next attempt is read.
+	c.uploadProgressMu.Lock()
+	defer c.uploadProgressMu.Unlock()
+	c.uploadProgress(sent, total)
+}
+
+// uploadProgressReader reads a part of the archive, that starts at offset, and
+// reports the upload progress as the HTTP transport reads it to send it.
+type uploadProgressReader struct {
+	r        *bytes.Reader
+	client   *Client
+	sent     int64
+	reported int64
+	total    int64
+}
+
+func (c *Client) newUploadProgressReader(data []byte, offset, total int64) *uploadProgressReader {
+	return &uploadProgressReader{
+		r:        bytes.NewReader(data),
+		client:   c,
+		sent:     offset,
+		reported: offset,
+		total:    total,
+	}
+}
+
+func (r *uploadProgressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.sent += int64(n)
+	if n > 0 && (r.sent-r.reported >= uploadProgressStep || r.r.Len() == 0) {
+		r.reported = r.sent
+		r.client.reportUploadProgress(r.sent, r.total)
+	}
+	return n, err
+}
+
+// countingWriter discards what is written to it and counts its length.
+type countingWriter int64
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,246 @@
+package cloudapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/lib/testutils"
+)
+
+// chunkedUploadServer is a stand-in for the chunked archive upload of the
+// cloud API, which fails the first attempt to upload every chunk in failOnce.
+type chunkedUploadServer struct {
+	t              *testing.T
+	uploadedChunks []int
+	failOnce       map[int]bool
+
+	mu     sync.Mutex
+	chunks map[int][]byte
+	fields map[string]string
+	size   int
+	sha256 string
+}
+
+var chunkURLRegexp = regexp.MustCompile(`^/v1/archive-upload/chunked/abc/chunks/(\d+)$`)
+
+func (s *chunkedUploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/archive-upload/chunked":
+		var session struct {
+			Size   int    `json:"size"`
+			SHA256 string `json:"sha256"`
+		}
+		require.NoError(s.t, json.NewDecoder(r.Body).Decode(&session))
+		s.size, s.sha256 = session.Size, session.SHA256
+		uploadedChunks, err := json.Marshal(s.uploadedChunks)
+		require.NoError(s.t, err)
+		fprintf(s.t, w, `{"upload_id": "abc", "uploaded_chunks": %s}`, uploadedChunks)
+
+	case r.Method == http.MethodPut && chunkURLRegexp.MatchString(r.URL.Path):
+		index, err := strconv.Atoi(chunkURLRegexp.FindStringSubmatch(r.URL.Path)[1])
+		require.NoError(s.t, err)
+		if s.failOnce[index] {
+			delete(s.failOnce, index)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		chunk, err := io.ReadAll(r.Body)
+		require.NoError(s.t, err)
+		checksum := sha256.Sum256(chunk)
+		assert.Equal(s.t, hex.EncodeToString(checksum[:]), r.Header.Get("K6-Chunk-SHA256"))
+		assert.Equal(s.t, "application/octet-stream", r.Header.Get("Content-Type"))
+		s.chunks[index] = chunk
+
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/archive-upload/chunked/abc/complete":
+		require.NoError(s.t, json.NewDecoder(r.Body).Decode(&s.fields))
+		assert.Equal(s.t, s.sha256, s.fields["sha256"])
+		fprintf(s.t, w, `{"reference_id": "123"}`)
+
+	default:
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *chunkedUploadServer) receivedChunks() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	indexes := make([]int, 0, len(s.chunks))
+	for index := range s.chunks {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+func getTestArchive(t *testing.T, dataSize int) *lib.Archive {
+	data := bytes.Repeat([]byte("// some data\n"), dataSize/13+1)
+	fs := fsext.NewMemMapFs()
+	require.NoError(t, fsext.WriteFile(fs, "/path/to/a.js", data, 0o644))
+	return &lib.Archive{
+		Type:        "js",
+		FilenameURL: &url.URL{Scheme: "file", Path: "/path/to/a.js"},
+		PwdURL:      &url.URL{Scheme: "file", Path: "/path/to"},
+		Data:        data,
+		Filesystems: map[string]fsext.Fs{"file": fs},
+	}
+}
+
+func TestUploadArchiveChunked(t *testing.T) {
+	t.Parallel()
+
+	srv := &chunkedUploadServer{t: t, failOnce: map[int]bool{2: true}, chunks: make(map[int][]byte)}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	client := NewClient(testutils.NewLogger(t), "token", server.URL, "1.0", 1*time.Second)
+	client.uploadChunkSize = 1024
+	client.retryInterval = time.Millisecond
+	var progress []int64
+	client.SetUploadProgressCallback(func(sent, total int64) {
+		assert.Equal(t, int64(srv.size), total)
+		progress = append(progress, sent)
+	})
+
+	arc := getTestArchive(t, 5000)
+	resp, err := client.StartCloudTestRun("my test", 12, arc)
+	require.NoError(t, err)
+	assert.Equal(t, "123", resp.ReferenceID)
+	assert.Equal(t, map[string]string{"name": "my test", "project_id": "12", "sha256": srv.sha256}, srv.fields)
+
+	// the chunks are reassembled into the same archive that was sent
+	chunkIndexes := srv.receivedChunks()
+	require.Len(t, chunkIndexes, (srv.size+1023)/1024)
+	var uploaded bytes.Buffer
+	for _, index := range chunkIndexes {
+		uploaded.Write(srv.chunks[index])
+	}
+	require.Equal(t, srv.size, uploaded.Len())
+	checksum := sha256.Sum256(uploaded.Bytes())
+	assert.Equal(t, srv.sha256, hex.EncodeToString(checksum[:]))
+	assert.Contains(t, uploaded.String(), string(arc.Data))
+
+	client.uploadProgressMu.Lock()
+	defer client.uploadProgressMu.Unlock()
+	require.NotEmpty(t, progress)
+	for _, sent := range progress {
+		assert.LessOrEqual(t, sent, int64(srv.size))
+	}
+	assert.Equal(t, int64(srv.size), progress[len(progress)-1])
+}
+
+func TestUploadArchiveChunkedProgress(t *testing.T) {
+	t.Parallel()
+
+	srv := &chunkedUploadServer{t: t, uploadedChunks: []int{0}, chunks: make(map[int][]byte)}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	client := NewClient(testutils.NewLogger(t), "token", server.URL, "1.0", 1*time.Second)
+	client.uploadChunkSize = 4 * uploadProgressStep
+	var progress []int64
+	client.SetUploadProgressCallback(func(sent, _ int64) {
+		progress = append(progress, sent)
+	})
+
+	_, err := client.StartCloudTestRun("my test", 0, getTestArchive(t, 6*uploadProgressStep))
+	require.NoError(t, err)
+
+	// the progress is reported while every chunk is sent, not once it's
+	// uploaded, and only once for the chunk that was already uploaded
+	client.uploadProgressMu.Lock()
+	defer client.uploadProgressMu.Unlock()
+	assert.Equal(t, int64(client.uploadChunkSize), progress[0])
+	assert.Equal(t, int64(client.uploadChunkSize+uploadProgressStep), progress[1])
+	assert.True(t, sort.SliceIsSorted(progress, func(i, j int) bool { return progress[i] < progress[j] }))
+	assert.Equal(t, int64(srv.size), progress[len(progress)-1])
+	assert.Greater(t, len(progress), len(srv.receivedChunks())+1)
+}
+
+func TestUploadArchiveChunkedResume(t *testing.T) {
+	t.Parallel()
+
+	srv := &chunkedUploadServer{t: t, uploadedChunks: []int{0, 1}, chunks: make(map[int][]byte)}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	client := NewClient(testutils.NewLogger(t), "token", server.URL, "1.0", 1*time.Second)
+	client.uploadChunkSize = 1024
+
+	resp, err := client.UploadTestOnly("my test", 0, getTestArchive(t, 5000))
+	require.NoError(t, err)
+	assert.Equal(t, "123", resp.ReferenceID)
+	assert.Equal(t, "true", srv.fields["upload_only"])
+
+	chunkIndexes := srv.receivedChunks()
+	require.NotEmpty(t, chunkIndexes)
+	assert.Equal(t, 2, chunkIndexes[0], "the already uploaded chunks shouldn't be uploaded again")
+}
+
+func TestUploadArchiveSmall(t *testing.T) {
+	t.Parallel()
+
+	arc := getTestArchive(t, 100)
+	var arcBuf bytes.Buffer
+	require.NoError(t, arc.Write(&arcBuf))
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/archive-upload", r.URL.Path)
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, r.ContentLength, int64(len(body)))
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "my test", r.FormValue("name"))
+		file, _, err := r.FormFile("file")
+		require.NoError(t, err)
+		uploaded, err := io.ReadAll(file)
+		require.NoError(t, err)
+		assert.Equal(t, arcBuf.Bytes(), uploaded)
+
+		// the streamed body is sent again when the request is retried
+		if attempts++; attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fprintf(t, w, `{"reference_id": "123"}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(testutils.NewLogger(t), "token", server.URL, "1.0", 1*time.Second)
+	client.retryInterval = time.Millisecond
+	var sent, total int64
+	client.SetUploadProgressCallback(func(s, tot int64) { sent, total = s, tot })
+
+	resp, err := client.StartCloudTestRun("my test", 0, arc)
+	require.NoError(t, err)
+	assert.Equal(t, "123", resp.ReferenceID)
+	assert.Equal(t, 2, attempts)
+
+	client.uploadProgressMu.Lock()
+	defer client.uploadProgressMu.Unlock()
+	assert.Equal(t, int64(arcBuf.Len()), total)
+	assert.Equal(t, total, sent)
+}
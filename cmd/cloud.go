@@ -9,10 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
-	"sync"
-	"time"
 
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
@@ -29,9 +26,12 @@ import (
 type cmdCloud struct {
 	gs *state.GlobalState
 
-	showCloudLogs bool
-	exitOnRunning bool
-	uploadOnly    bool
+	showCloudLogs  bool
+	exitOnRunning  bool
+	uploadOnly     bool
+	validateOnly   bool
+	progressFormat string
+	jsonProgress   bool
 }
 
 func (c *cmdCloud) preRun(cmd *cobra.Command, _ []string) error {
@@ -68,6 +68,20 @@ func (c *cmdCloud) preRun(cmd *cobra.Command, _ []string) error {
 			c.uploadOnly = uploadOnlyValue
 		}
 	}
+	if validateOnlyEnv, ok := c.gs.Env["K6_CLOUD_VALIDATE_ONLY"]; ok {
+		validateOnlyValue, err := strconv.ParseBool(validateOnlyEnv)
+		if err != nil {
+			return fmt.Errorf("parsing K6_CLOUD_VALIDATE_ONLY returned an error: %w", err)
+		}
+		if !cmd.Flags().Changed("validate-only") {
+			c.validateOnly = validateOnlyValue
+		}
+	}
+
+	var err error
+	if c.jsonProgress, err = setupCloudProgressFormat(c.gs, c.progressFormat); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -99,10 +113,6 @@ func (c *cmdCloud) run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// TODO: validate for usage of execution segment
-	// TODO: validate for externally controlled executor (i.e. executors that aren't distributable)
-	// TODO: move those validations to a separate function and reuse validateConfig()?
-
 	modifyAndPrintBar(c.gs, progressBar, pb.WithConstProgress(0, "Building the archive..."))
 	arc := testRunState.Runner.MakeArchive()
 
@@ -124,7 +134,7 @@ func (c *cmdCloud) run(cmd *cobra.Command, args []string) error {
 
 	// Cloud config
 	cloudConfig, err := cloudapi.GetConsolidatedConfig(
-		test.derivedConfig.Collectors["cloud"], c.gs.Env, "", arc.Options.External)
+		test.derivedConfig.Collectors["cloud"], getCloudEnv(c.gs, cmd.Flags()), "", arc.Options.External)
 	if err != nil {
 		return err
 	}
@@ -167,11 +177,30 @@ func (c *cmdCloud) run(cmd *cobra.Command, args []string) error {
 	modifyAndPrintBar(c.gs, progressBar, pb.WithConstProgress(0, "Validating script options"))
 	client := cloudapi.NewClient(
 		logger, cloudConfig.Token.String, cloudConfig.Host.String, consts.Version, cloudConfig.Timeout.TimeDuration())
+	validationErrs := validateCloudTest(test.derivedConfig)
 	if err = client.ValidateOptions(arc.Options); err != nil {
-		return err
+		remoteErrs, ok := cloudValidationErrorsFromResponse(err)
+		if !ok {
+			return err
+		}
+		validationErrs = append(validationErrs, remoteErrs...)
+	}
+	if len(validationErrs) > 0 {
+		return errext.WithExitCodeIfNone(validationErrs, exitcodes.InvalidConfig)
+	}
+	if c.validateOnly {
+		modifyAndPrintBar(c.gs, progressBar, pb.WithConstProgress(1, "Validated"))
+		printToStdout(c.gs, "The test is valid and can be run in the cloud\n")
+		return nil
 	}
 
 	modifyAndPrintBar(c.gs, progressBar, pb.WithConstProgress(0, "Uploading archive"))
+	client.SetUploadProgressCallback(func(sent, total int64) {
+		modifyAndPrintBar(c.gs, progressBar, pb.WithConstProgress(
+			float64(sent)/float64(total),
+			fmt.Sprintf("Uploading archive %.1f/%.1f MB", float64(sent)/1e6, float64(total)/1e6),
+		))
+	})
 
 	var cloudTestRun *cloudapi.CreateTestRunResponse
 	if c.uploadOnly {
@@ -188,6 +217,11 @@ func (c *cmdCloud) run(cmd *cobra.Command, args []string) error {
 	if cloudTestRun.ConfigOverride != nil {
 		cloudConfig = cloudConfig.Apply(*cloudTestRun.ConfigOverride)
 	}
+	showLogs := c.showCloudLogs
+	if showLogs && !cloudTestRun.Capabilities.HasLogsTail() {
+		logger.Warn("The logs tailing isn't enabled for the organization in the cloud, the logs won't be shown")
+		showLogs = false
+	}
 
 	// Trap Interrupts, SIGINTs and SIGTERMs.
 	gracefulStop := func(sig os.Signal) {
@@ -225,102 +259,37 @@ func (c *cmdCloud) run(cmd *cobra.Command, args []string) error {
 		pb.WithConstLeft("Run "), pb.WithConstProgress(0, "Initializing the cloud test"),
 	)
 
-	progressCtx, progressCancel := context.WithCancel(globalCtx)
-	progressBarWG := &sync.WaitGroup{}
-	progressBarWG.Add(1)
-	defer progressBarWG.Wait()
-	defer progressCancel()
-	go func() {
-		showProgress(progressCtx, c.gs, []*pb.ProgressBar{progressBar}, logger)
-		progressBarWG.Done()
-	}()
-
-	var (
-		startTime   time.Time
-		maxDuration time.Duration
-	)
-	maxDuration, _ = lib.GetEndOffset(executionPlan)
-
-	testProgressLock := &sync.Mutex{}
-	var testProgress *cloudapi.TestProgressResponse
-	progressBar.Modify(
-		pb.WithProgress(func() (float64, []string) {
-			testProgressLock.Lock()
-			defer testProgressLock.Unlock()
-
-			if testProgress == nil {
-				return 0, []string{"Waiting..."}
-			}
-
-			statusText := testProgress.RunStatusText
-
-			if testProgress.RunStatus == cloudapi.RunStatusFinished {
-				testProgress.Progress = 1
-			} else if testProgress.RunStatus == cloudapi.RunStatusRunning {
-				if startTime.IsZero() {
-					startTime = time.Now()
-				}
-				spent := time.Since(startTime)
-				if spent > maxDuration {
-					statusText = maxDuration.String()
-				} else {
-					statusText = fmt.Sprintf("%s/%s", pb.GetFixedLengthDuration(spent, maxDuration), maxDuration)
-				}
-			}
-
-			return testProgress.Progress, []string{statusText}
-		}),
-	)
-
-	ticker := time.NewTicker(time.Millisecond * 2000)
-	if c.showCloudLogs {
-		go func() {
-			logger.Debug("Connecting to cloud logs server...")
-			if err := cloudConfig.StreamLogsToLogger(globalCtx, logger, refID, 0); err != nil {
-				logger.WithError(err).Error("error while tailing cloud logs")
-			}
-		}()
+	maxDuration, _ := lib.GetEndOffset(executionPlan)
+	watcher := &cloudTestRunWatcher{
+		gs:            c.gs,
+		client:        client,
+		cloudConfig:   cloudConfig,
+		refID:         refID,
+		progressBar:   progressBar,
+		maxDuration:   maxDuration,
+		showLogs:      showLogs,
+		exitOnRunning: c.exitOnRunning,
+		jsonProgress:  c.jsonProgress,
 	}
-
-	for range ticker.C {
-		newTestProgress, progressErr := client.GetTestProgress(refID)
-		if progressErr != nil {
-			logger.WithError(progressErr).Error("Test progress error")
-			continue
-		}
-
-		testProgressLock.Lock()
-		testProgress = newTestProgress
-		testProgressLock.Unlock()
-
-		if (newTestProgress.RunStatus > cloudapi.RunStatusRunning) ||
-			(c.exitOnRunning && newTestProgress.RunStatus == cloudapi.RunStatusRunning) {
-			globalCancel()
-			break
-		}
-	}
-
-	if testProgress == nil {
-		//nolint:stylecheck,golint
-		return errext.WithExitCodeIfNone(errors.New("Test progress error"), exitcodes.CloudFailedToGetProgress)
-	}
-
-	if !c.gs.Flags.Quiet {
-		valueColor := getColor(c.gs.Flags.NoColor || !c.gs.Stdout.IsTTY, color.FgCyan)
-		printToStdout(c.gs, fmt.Sprintf(
-			"     test status: %s\n", valueColor.Sprint(testProgress.RunStatusText),
-		))
-	} else {
-		logger.WithField("run_status", testProgress.RunStatusText).Debug("Test finished")
+	// After a graceful stop, we don't show the progress and the logs anymore,
+	// but we still wait for the cloud test run to actually stop.
+	testProgress, err := watcher.watch(c.gs.Ctx, globalCtx)
+	if err != nil {
+		return err
 	}
 
-	if testProgress.ResultStatus == cloudapi.ResultStatusFailed {
-		// TODO: use different exit codes for failed thresholds vs failed test (e.g. aborted by system/limit)
-		//nolint:stylecheck,golint
-		return errext.WithExitCodeIfNone(errors.New("The test has failed"), exitcodes.CloudTestRunFailed)
+	// The results are only available once the test run has actually ended.
+	// With the JSON progress, stdout is reserved for the progress events.
+	if !testRunState.RuntimeOptions.NoSummary.Bool && !c.jsonProgress &&
+		testProgress.RunStatus > cloudapi.RunStatusRunning && testProgress.RunStatus != cloudapi.RunStatusArchived {
+		defer func() {
+			if sErr := handleCloudSummary(c.gs.Ctx, c.gs, client, test, refID); sErr != nil {
+				logger.WithError(sErr).Error("failed to handle the end-of-test summary")
+			}
+		}()
 	}
 
-	return nil
+	return printCloudTestResult(c.gs, testProgress)
 }
 
 func (c *cmdCloud) flagSet() *pflag.FlagSet {
@@ -336,20 +305,70 @@ func (c *cmdCloud) flagSet() *pflag.FlagSet {
 		"enable showing of logs when a test is executed in the cloud")
 	flags.BoolVar(&c.uploadOnly, "upload-only", c.uploadOnly,
 		"only upload the test to the cloud without actually starting a test run")
+	flags.BoolVar(&c.validateOnly, "validate-only", c.validateOnly,
+		"only validate the test options locally and against the cloud limits, without creating a test run")
+	flags.StringVar(&c.progressFormat, "progress-format", c.progressFormat,
+		"how to show the progress of the test run, either `bar` or json for a JSON object per line")
 
 	return flags
 }
 
+// getCloudEnv returns the environment variables that should be used for the
+// cloud config consolidation, with K6_CLOUD_PROFILE overwritten by the value
+// of the --profile flag, if it was specified.
+func getCloudEnv(gs *state.GlobalState, flags *pflag.FlagSet) map[string]string {
+	profileFlag := flags.Lookup("profile")
+	if profileFlag == nil || !profileFlag.Changed {
+		return gs.Env
+	}
+
+	env := make(map[string]string, len(gs.Env)+1)
+	for k, v := range gs.Env {
+		env[k] = v
+	}
+	env["K6_CLOUD_PROFILE"] = profileFlag.Value.String()
+	return env
+}
+
+// getCloudConfigAndClient consolidates the cloud config from the disk config
+// and the environment variables, and returns it together with a cloud API
+// client. It's meant for the `k6 cloud` sub-commands that don't load a script.
+func getCloudConfigAndClient(gs *state.GlobalState, flags *pflag.FlagSet) (cloudapi.Config, *cloudapi.Client, error) {
+	diskConf, err := readDiskConfig(gs)
+	if err != nil {
+		return cloudapi.Config{}, nil, err
+	}
+
+	cloudConfig, err := cloudapi.GetConsolidatedConfig(diskConf.Collectors["cloud"], getCloudEnv(gs, flags), "", nil)
+	if err != nil {
+		return cloudapi.Config{}, nil, err
+	}
+	if !cloudConfig.Token.Valid {
+		return cloudapi.Config{}, nil, errors.New("Not logged in, please use `k6 login cloud`.") //nolint:golint,revive,stylecheck
+	}
+
+	client := cloudapi.NewClient(
+		gs.Logger, cloudConfig.Token.String, cloudConfig.Host.String, consts.Version, cloudConfig.Timeout.TimeDuration())
+	return cloudConfig, client, nil
+}
+
 func getCmdCloud(gs *state.GlobalState) *cobra.Command {
 	c := &cmdCloud{
-		gs:            gs,
-		showCloudLogs: true,
-		exitOnRunning: false,
-		uploadOnly:    false,
+		gs:             gs,
+		showCloudLogs:  true,
+		exitOnRunning:  false,
+		uploadOnly:     false,
+		progressFormat: cloudProgressFormatBar,
 	}
 
 	exampleText := getExampleText(gs, `
-  {{.}} cloud script.js`[1:])
+  {{.}} cloud script.js
+
+  # Check that the test can be run in the cloud, without starting it.
+  {{.}} cloud --validate-only script.js
+
+  # Follow the test run from a CI pipeline, with a JSON object per progress update.
+  {{.}} cloud --progress-format=json script.js`[1:])
 
 	cloudCmd := &cobra.Command{
 		Use:   "cloud",
@@ -364,5 +383,16 @@ This will execute the test on the k6 cloud service. Use "k6 login cloud" to auth
 	}
 	cloudCmd.Flags().SortFlags = false
 	cloudCmd.Flags().AddFlagSet(c.flagSet())
+	cloudCmd.PersistentFlags().String("profile", "",
+		"use the credentials of the cloud profile with this `name`, see \"k6 login cloud --list\"")
+	cloudCmd.AddCommand(
+		getCmdCloudRuns(gs),
+		getCmdCloudWatch(gs),
+		getCmdCloudStop(gs),
+		getCmdCloudAbort(gs),
+		getCmdCloudLogs(gs),
+		getCmdCloudReplayMetrics(gs),
+		getCmdCloudDecodeMetrics(gs),
+	)
 	return cloudCmd
 }
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/output/cloud/expv2"
+)
+
+// cmdCloudDecodeMetrics handles the `k6 cloud decode-metrics` sub-command
+type cmdCloudDecodeMetrics struct {
+	gs *state.GlobalState
+}
+
+// decodedMetricsFile is a decoded file written by the cloud output, for
+// printing it as JSON.
+type decodedMetricsFile struct {
+	File string `json:"file"`
+	*expv2.DecodedMetricSet
+}
+
+func (c *cmdCloudDecodeMetrics) run(_ *cobra.Command, args []string) error {
+	var files []string
+	for _, arg := range args {
+		info, err := c.gs.FS.Stat(arg)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+
+		// the names of the files sort in the order in which they were written
+		entries, err := fsext.ReadDir(c.gs.FS, arg)
+		if err != nil {
+			return err
+		}
+		var names []string
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), expv2.MetricsFileExt) {
+				names = append(names, filepath.Join(arg, entry.Name()))
+			}
+		}
+		sort.Strings(names)
+		files = append(files, names...)
+	}
+
+	decoded := make([]decodedMetricsFile, 0, len(files))
+	for _, file := range files {
+		data, err := fsext.ReadFile(c.gs.FS, file)
+		if err != nil {
+			return err
+		}
+		ms, err := expv2.DecodeMetricsFile(data)
+		if err != nil {
+			return fmt.Errorf("couldn't decode %s: %w", file, err)
+		}
+		decoded = append(decoded, decodedMetricsFile{File: file, DecodedMetricSet: ms})
+	}
+	return jsonPrint(c.gs.Stdout, decoded)
+}
+
+func getCmdCloudDecodeMetrics(gs *state.GlobalState) *cobra.Command {
+	c := &cmdCloudDecodeMetrics{gs: gs}
+
+	exampleText := getExampleText(gs, `
+  # Write the metrics that would be sent to the cloud to files, and then decode them.
+  K6_CLOUD_METRICS_DIR=./metrics {{.}} run -o cloud script.js
+  {{.}} cloud decode-metrics ./metrics`[1:])
+
+	decodeCmd := &cobra.Command{
+		Use:   "decode-metrics",
+		Short: "Print the metrics written by the offline cloud output as JSON",
+		Long: `Print the metrics written by the offline cloud output as JSON.
+
+When K6_CLOUD_METRICS_DIR is set, "k6 run -o cloud" doesn't use the k6 cloud
+service, it writes every batch of metrics to a file in that directory, exactly
+as it would push it. This prints the batches in the files, and in all of the
+files in the directories, as JSON. The histograms of the trend metrics are
+shown as their approximate percentiles.`,
+		Example: exampleText,
+		Args:    cobra.MinimumNArgs(1),
+		RunE:    c.run,
+	}
+	return decodeCmd
+}
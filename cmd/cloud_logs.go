@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/lib/types"
+)
+
+// Without --follow, the logs are considered fully replayed once no new
+// entries were received for this long.
+const cloudLogsIdleTimeout = 2 * time.Second
+
+// cmdCloudLogs handles the `k6 cloud logs` sub-command
+type cmdCloudLogs struct {
+	gs *state.GlobalState
+
+	follow   bool
+	since    string
+	level    string
+	selector string
+	output   string
+}
+
+func (c *cmdCloudLogs) run(cmd *cobra.Command, args []string) error {
+	minLevel := logrus.TraceLevel
+	if c.level != "" {
+		var err error
+		if minLevel, err = logrus.ParseLevel(c.level); err != nil {
+			return fmt.Errorf("invalid --level value: %w", err)
+		}
+	}
+	matchers, err := cloudapi.ParseLabelMatchers(c.selector)
+	if err != nil {
+		return err
+	}
+
+	cloudConfig, client, err := getCloudConfigAndClient(c.gs, cmd.Flags())
+	if err != nil {
+		return err
+	}
+
+	refID := args[0]
+	opts := cloudapi.StreamLogsOptions{MinLevel: minLevel, Matchers: matchers}
+	if !c.follow {
+		opts.IdleTimeout = cloudLogsIdleTimeout
+	}
+	if c.since != "" {
+		since, perr := types.ParseExtendedDuration(c.since)
+		if perr != nil {
+			return fmt.Errorf("invalid --since value %q: %w", c.since, perr)
+		}
+		opts.Since = time.Now().Add(-since)
+	} else {
+		// By default, all of the logs since the start of the test run are replayed.
+		testRun, gerr := client.GetTestRun(refID)
+		if gerr != nil {
+			return gerr
+		}
+		opts.Since = testRun.Created
+		if testRun.Started.Valid {
+			opts.Since = testRun.Started.Time
+		}
+	}
+
+	var writeEntry func(cloudapi.TestRunLogEntry) error
+	if c.output != "" {
+		f, ferr := c.gs.FS.OpenFile(c.output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+		if ferr != nil {
+			return fmt.Errorf("couldn't create the logs file: %w", ferr)
+		}
+		defer func() {
+			if cerr := f.Close(); cerr != nil {
+				c.gs.Logger.WithError(cerr).Error("Couldn't close the logs file")
+			}
+		}()
+		encoder := json.NewEncoder(f)
+		writeEntry = func(entry cloudapi.TestRunLogEntry) error { return encoder.Encode(entry) }
+	} else {
+		writeEntry = func(entry cloudapi.TestRunLogEntry) error { return writeCloudLogEntry(c.gs.Stdout, entry) }
+	}
+
+	var writeErr error
+	stats, err := cloudConfig.StreamLogs(c.gs.Ctx, c.gs.Logger, refID, opts, func(entry cloudapi.TestRunLogEntry) {
+		if writeErr == nil {
+			writeErr = writeEntry(entry)
+		}
+	})
+	stats.Log(c.gs.Logger)
+	if err != nil {
+		return err
+	}
+	return writeErr
+}
+
+// writeCloudLogEntry writes the entry as a human-readable line, with the
+// labels sorted by their names.
+func writeCloudLogEntry(w io.Writer, entry cloudapi.TestRunLogEntry) error {
+	names := make([]string, 0, len(entry.Labels))
+	for name := range entry.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var labels strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&labels, " %s=%q", name, entry.Labels[name])
+	}
+	_, err := fmt.Fprintf(w, "%s [%s] %s%s\n",
+		entry.Time.UTC().Format(time.RFC3339Nano), entry.Level, entry.Message, labels.String())
+	return err
+}
+
+func (c *cmdCloudLogs) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.BoolVarP(&c.follow, "follow", "f", c.follow, "keep streaming the new logs until interrupted")
+	flags.StringVar(&c.since, "since", c.since,
+		"only show the logs of the last `duration`, e.g. 10m, instead of all since the start of the test run")
+	flags.StringVar(&c.level, "level", c.level,
+		"only show the logs with this `level` or a more severe one, e.g. warning")
+	flags.StringVarP(&c.selector, "selector", "l", c.selector,
+		"only show the logs whose labels match the Loki-style `matchers`, e.g. 'scenario=\"foo\",instance=~\"i-.*\"'")
+	flags.StringVarP(&c.output, "output", "o", c.output, "write the logs as JSON, one entry per line, to this `file`")
+	return flags
+}
+
+func getCmdCloudLogs(gs *state.GlobalState) *cobra.Command {
+	c := &cmdCloudLogs{gs: gs}
+
+	exampleText := getExampleText(gs, `
+  # Show all of the logs of a cloud test run.
+  {{.}} cloud logs 1234567
+
+  # Follow the errors of a single scenario in a load zone.
+  {{.}} cloud logs -f --level error -l 'scenario="checkout",lz="amazon:us:ashburn"' 1234567
+
+  # Export the logs of the last hour to a file.
+  {{.}} cloud logs --since 1h -o logs.ndjson 1234567`[1:])
+
+	logsCmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show the logs of a cloud test run",
+		Long: `Show the logs of a cloud test run.
+
+This replays the logs of a test run on the k6 cloud service, by default since
+its start, and with --follow keeps streaming the new ones as they arrive.`,
+		Example: exampleText,
+		Args:    exactArgsWithMsg(1, "arg should be the reference ID of a cloud test run"),
+		RunE:    c.run,
+	}
+	logsCmd.Flags().SortFlags = false
+	logsCmd.Flags().AddFlagSet(c.flagSet())
+	return logsCmd
+}
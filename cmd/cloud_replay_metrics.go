@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/output/cloud/expv2"
+)
+
+// cmdCloudReplayMetrics handles the `k6 cloud replay-metrics` sub-command
+type cmdCloudReplayMetrics struct {
+	gs *state.GlobalState
+
+	refID string
+}
+
+func (c *cmdCloudReplayMetrics) run(cmd *cobra.Command, args []string) error {
+	if c.refID == "" {
+		return errors.New("the reference ID of the test run is required, please specify it with --ref-id")
+	}
+
+	_, client, err := getCloudConfigAndClient(c.gs, cmd.Flags())
+	if err != nil {
+		return err
+	}
+
+	pushed, err := expv2.ReplaySpooledMetrics(c.gs.Logger, client, c.gs.FS, args[0], c.refID)
+	if err != nil {
+		if pushed > 0 {
+			c.gs.Logger.Warnf("Only %d spooled batches of metrics were pushed, the rest can be pushed by running "+
+				"the command again", pushed)
+		}
+		return err
+	}
+	printToStdout(c.gs, fmt.Sprintf("Pushed %d spooled batches of metrics to the cloud test run %s\n", pushed, c.refID))
+	return nil
+}
+
+func getCmdCloudReplayMetrics(gs *state.GlobalState) *cobra.Command {
+	c := &cmdCloudReplayMetrics{gs: gs}
+
+	exampleText := getExampleText(gs, `
+  # Push the metrics that a test run with K6_CLOUD_SPOOL_DIR=./spool couldn't push.
+  {{.}} cloud replay-metrics ./spool --ref-id 1234567`[1:])
+
+	replayCmd := &cobra.Command{
+		Use:   "replay-metrics",
+		Short: "Push the spooled metrics of a cloud test run",
+		Long: `Push the spooled metrics of a cloud test run.
+
+When K6_CLOUD_SPOOL_DIR is set, the cloud output writes the metrics that it
+couldn't push to the k6 cloud service to that directory, instead of losing
+them. This pushes them to the test run, and removes every file once it has
+been pushed, so it can be run again if it fails midway.`,
+		Example: exampleText,
+		Args:    exactArgsWithMsg(1, "arg should be the spool directory"),
+		RunE:    c.run,
+	}
+	replayCmd.Flags().StringVar(&c.refID, "ref-id", c.refID, "the reference ID of the cloud test run")
+	return replayCmd
+}
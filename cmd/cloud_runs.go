@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/lib/types"
+)
+
+// getCmdCloudRuns returns the `k6 cloud runs` sub-command, together with its children.
+func getCmdCloudRuns(gs *state.GlobalState) *cobra.Command {
+	runsCmd := &cobra.Command{
+		Use:   "runs",
+		Short: "Browse cloud test runs",
+		Long: `Browse cloud test runs.
+
+This lists and shows the details of test runs that were previously executed on,
+or streamed to, the k6 cloud service. Use "k6 login cloud" to authenticate.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Usage()
+		},
+	}
+	runsCmd.AddCommand(
+		getCmdCloudRunsList(gs),
+		getCmdCloudRunsGet(gs),
+	)
+
+	return runsCmd
+}
+
+// cmdCloudRunsList handles the `k6 cloud runs list` sub-command
+type cmdCloudRunsList struct {
+	gs *state.GlobalState
+
+	projectID  int64
+	since      string
+	limit      int
+	jsonOutput bool
+}
+
+func (c *cmdCloudRunsList) run(cmd *cobra.Command, _ []string) error {
+	cloudConfig, client, err := getCloudConfigAndClient(c.gs, cmd.Flags())
+	if err != nil {
+		return err
+	}
+
+	params := cloudapi.ListTestRunsParams{ProjectID: cloudConfig.ProjectID.Int64}
+	if cmd.Flags().Changed("project") {
+		params.ProjectID = c.projectID
+	}
+	if c.since != "" {
+		since, perr := types.ParseExtendedDuration(c.since)
+		if perr != nil {
+			return fmt.Errorf("invalid --since value %q: %w", c.since, perr)
+		}
+		params.CreatedAfter = time.Now().Add(-since)
+	}
+
+	runs := make([]cloudapi.TestRunDetails, 0)
+	for params.Page = 1; c.limit <= 0 || len(runs) < c.limit; {
+		page, lerr := client.ListTestRuns(params)
+		if lerr != nil {
+			return lerr
+		}
+		runs = append(runs, page.TestRuns...)
+
+		if page.NextPage <= params.Page {
+			break
+		}
+		params.Page = page.NextPage
+	}
+	if c.limit > 0 && len(runs) > c.limit {
+		runs = runs[:c.limit]
+	}
+
+	if c.jsonOutput {
+		return jsonPrint(c.gs.Stdout, runs)
+	}
+
+	tw := tabwriter.NewWriter(c.gs.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "REFERENCE ID\tNAME\tPROJECT\tSTATUS\tRESULT\tCREATED")
+	for _, run := range runs {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n",
+			run.ReferenceID, run.Name, run.ProjectID, run.RunStatusText,
+			resultStatusText(run), run.Created.Local().Format(time.RFC3339),
+		)
+	}
+	return tw.Flush()
+}
+
+func (c *cmdCloudRunsList) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.Int64Var(&c.projectID, "project", c.projectID,
+		"only list the test runs of the project with this `id`, defaults to the configured projectID")
+	flags.StringVar(&c.since, "since", c.since,
+		"only list the test runs created in the last `duration`, e.g. 12h or 7d")
+	flags.IntVar(&c.limit, "limit", c.limit, "maximum number of test runs to list, 0 for no limit")
+	flags.BoolVar(&c.jsonOutput, "json", c.jsonOutput, "print the test runs as JSON")
+	return flags
+}
+
+func getCmdCloudRunsList(gs *state.GlobalState) *cobra.Command {
+	c := &cmdCloudRunsList{
+		gs:    gs,
+		limit: 50,
+	}
+
+	exampleText := getExampleText(gs, `
+  # List the test runs of the last week in project 1234.
+  {{.}} cloud runs list --project 1234 --since 7d
+
+  # Get the reference ID of the most recent test run.
+  {{.}} cloud runs list --limit 1 --json`[1:])
+
+	listCmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List cloud test runs",
+		Long:    `List cloud test runs, starting from the most recent one.`,
+		Example: exampleText,
+		Args:    cobra.NoArgs,
+		RunE:    c.run,
+	}
+	listCmd.Flags().SortFlags = false
+	listCmd.Flags().AddFlagSet(c.flagSet())
+	return listCmd
+}
+
+// cmdCloudRunsGet handles the `k6 cloud runs get` sub-command
+type cmdCloudRunsGet struct {
+	gs *state.GlobalState
+
+	jsonOutput bool
+}
+
+func (c *cmdCloudRunsGet) run(cmd *cobra.Command, args []string) error {
+	cloudConfig, client, err := getCloudConfigAndClient(c.gs, cmd.Flags())
+	if err != nil {
+		return err
+	}
+
+	run, err := client.GetTestRun(args[0])
+	if err != nil {
+		return err
+	}
+
+	if c.jsonOutput {
+		return jsonPrint(c.gs.Stdout, run)
+	}
+
+	formatTime := func(t time.Time, valid bool) string {
+		if !valid {
+			return "-"
+		}
+		return t.Local().Format(time.RFC3339)
+	}
+
+	tw := tabwriter.NewWriter(c.gs.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "reference ID:\t%s\n", run.ReferenceID)
+	_, _ = fmt.Fprintf(tw, "name:\t%s\n", run.Name)
+	_, _ = fmt.Fprintf(tw, "project:\t%d\n", run.ProjectID)
+	_, _ = fmt.Fprintf(tw, "status:\t%s\n", run.RunStatusText)
+	_, _ = fmt.Fprintf(tw, "result:\t%s\n", resultStatusText(*run))
+	_, _ = fmt.Fprintf(tw, "vus:\t%d\n", run.VUs)
+	_, _ = fmt.Fprintf(tw, "created:\t%s\n", formatTime(run.Created, !run.Created.IsZero()))
+	_, _ = fmt.Fprintf(tw, "started:\t%s\n", formatTime(run.Started.Time, run.Started.Valid))
+	_, _ = fmt.Fprintf(tw, "ended:\t%s\n", formatTime(run.Ended.Time, run.Ended.Valid))
+	_, _ = fmt.Fprintf(tw, "url:\t%s\n", cloudapi.URLForResults(run.ReferenceID, cloudConfig))
+	return tw.Flush()
+}
+
+func getCmdCloudRunsGet(gs *state.GlobalState) *cobra.Command {
+	c := &cmdCloudRunsGet{gs: gs}
+
+	exampleText := getExampleText(gs, `
+  {{.}} cloud runs get 1234567`[1:])
+
+	getCmd := &cobra.Command{
+		Use:     "get",
+		Short:   "Show the details of a cloud test run",
+		Long:    `Show the details of a cloud test run.`,
+		Example: exampleText,
+		Args:    exactArgsWithMsg(1, "arg should be the reference ID of a cloud test run"),
+		RunE:    c.run,
+	}
+	getCmd.Flags().BoolVar(&c.jsonOutput, "json", c.jsonOutput, "print the test run as JSON")
+	return getCmd
+}
+
+// resultStatusText returns a human-readable result of the test run, or a dash
+// if the test run hasn't finished yet and so doesn't have a result.
+func resultStatusText(run cloudapi.TestRunDetails) string {
+	if run.RunStatus <= cloudapi.RunStatusRunning {
+		return "-"
+	}
+	if run.ResultStatus == cloudapi.ResultStatusFailed {
+		return "failed"
+	}
+	return "passed"
+}
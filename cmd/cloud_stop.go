@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/ui/pb"
+)
+
+// cmdCloudStop handles the `k6 cloud stop` and `k6 cloud abort` sub-commands
+type cmdCloudStop struct {
+	gs *state.GlobalState
+
+	// If abort is true, the test run is stopped immediately, without
+	// waiting for the iterations to finish or running the teardown.
+	abort bool
+	wait  bool
+}
+
+func (c *cmdCloudStop) run(cmd *cobra.Command, args []string) error {
+	cloudConfig, client, err := getCloudConfigAndClient(c.gs, cmd.Flags())
+	if err != nil {
+		return err
+	}
+
+	refID := args[0]
+	if c.abort {
+		err = client.AbortCloudTestRun(refID)
+	} else {
+		err = client.StopCloudTestRun(refID)
+	}
+	if err != nil {
+		return err
+	}
+
+	action := "stop"
+	if c.abort {
+		action = "abort"
+	}
+	if !c.wait {
+		printToStdout(c.gs, fmt.Sprintf("Successfully sent signal to %s the cloud test run %s\n", action, refID))
+		return nil
+	}
+	c.gs.Logger.Infof("Successfully sent signal to %s the cloud test, now waiting for it to actually stop...", action)
+
+	globalCtx, globalCancel := context.WithCancel(c.gs.Ctx)
+	defer globalCancel()
+
+	gracefulStop := func(sig os.Signal) {
+		c.gs.Logger.WithField("sig", sig).Print("Stopped waiting for the cloud test run in response to signal")
+		globalCancel()
+	}
+	stopSignalHandling := handleTestAbortSignals(c.gs, gracefulStop, nil)
+	defer stopSignalHandling()
+
+	watcher := &cloudTestRunWatcher{
+		gs:          c.gs,
+		client:      client,
+		cloudConfig: cloudConfig,
+		refID:       refID,
+		progressBar: pb.New(
+			pb.WithConstLeft("Stop"), pb.WithConstProgress(0, "Waiting for the cloud test to stop"),
+		),
+	}
+	testProgress, err := watcher.watch(globalCtx, globalCtx)
+	if err != nil {
+		return err
+	}
+	if testProgress.RunStatus <= cloudapi.RunStatusRunning {
+		// We were interrupted before the test run actually stopped, so there
+		// is no final result yet and the stop isn't confirmed.
+		return errext.WithExitCodeIfNone(fmt.Errorf(
+			"stopped waiting before the cloud test run %s had stopped, it may still be running", refID,
+		), exitcodes.ExternalAbort)
+	}
+
+	return printCloudTestResult(c.gs, testProgress)
+}
+
+func getCmdCloudStop(gs *state.GlobalState) *cobra.Command {
+	c := &cmdCloudStop{gs: gs}
+
+	exampleText := getExampleText(gs, `
+  # Gracefully stop a cloud test run.
+  {{.}} cloud stop 1234567
+
+  # Stop a cloud test run and wait until it has actually stopped.
+  {{.}} cloud stop --wait 1234567`[1:])
+
+	stopCmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop a running cloud test",
+		Long: `Stop a running cloud test.
+
+This gracefully stops a test run on the k6 cloud service, i.e. the running
+iterations are allowed to finish and the teardown is executed. With --wait,
+it exits with the same exit code as "k6 cloud" would for the final test status.`,
+		Example: exampleText,
+		Args:    exactArgsWithMsg(1, "arg should be the reference ID of a cloud test run"),
+		RunE:    c.run,
+	}
+	stopCmd.Flags().BoolVar(&c.wait, "wait", c.wait, "wait until the test run has actually stopped")
+	return stopCmd
+}
+
+func getCmdCloudAbort(gs *state.GlobalState) *cobra.Command {
+	c := &cmdCloudStop{gs: gs, abort: true}
+
+	exampleText := getExampleText(gs, `
+  {{.}} cloud abort 1234567`[1:])
+
+	abortCmd := &cobra.Command{
+		Use:   "abort",
+		Short: "Abort a running cloud test",
+		Long: `Abort a running cloud test.
+
+This immediately stops a test run on the k6 cloud service, without waiting for
+the running iterations to finish and without executing the teardown. With
+--wait, it exits with the same exit code as "k6 cloud" would for the final
+test status.`,
+		Example: exampleText,
+		Args:    exactArgsWithMsg(1, "arg should be the reference ID of a cloud test run"),
+		RunE:    c.run,
+	}
+	abortCmd.Flags().BoolVar(&c.wait, "wait", c.wait, "wait until the test run has actually stopped")
+	return abortCmd
+}
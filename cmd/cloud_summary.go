@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+// cloudSummarySink is a metrics.Sink for values that were already aggregated
+// by the cloud, so they can be passed as they are to handleSummary().
+type cloudSummarySink map[string]float64
+
+var _ metrics.Sink = cloudSummarySink{}
+
+func (s cloudSummarySink) Add(metrics.Sample) {}
+
+func (s cloudSummarySink) Format(time.Duration) map[string]float64 {
+	result := make(map[string]float64, len(s))
+	for k, v := range s {
+		result[k] = v
+	}
+	return result
+}
+
+func (s cloudSummarySink) IsEmpty() bool { return len(s) == 0 }
+
+// newCloudSummary builds the end-of-test summary data of a cloud test run from
+// the aggregated results returned by the cloud.
+func newCloudSummary(gs *state.GlobalState, trs *cloudapi.TestRunSummary) (*lib.Summary, error) {
+	summaryMetrics := make(map[string]*metrics.Metric, len(trs.Metrics))
+	for name, sm := range trs.Metrics {
+		m := &metrics.Metric{
+			Name:     name,
+			Type:     sm.Type,
+			Contains: sm.Contains,
+			Sink:     cloudSummarySink(sm.Values),
+		}
+
+		sources := make([]string, 0, len(sm.Thresholds))
+		for source := range sm.Thresholds {
+			sources = append(sources, source)
+		}
+		sort.Strings(sources)
+		for _, source := range sources {
+			m.Thresholds.Thresholds = append(m.Thresholds.Thresholds, &metrics.Threshold{
+				Source:     source,
+				LastFailed: !sm.Thresholds[source].OK,
+			})
+		}
+		summaryMetrics[name] = m
+	}
+
+	rootGroup, err := lib.NewGroup("", nil)
+	if err != nil {
+		return nil, err
+	}
+	if err = fillCloudSummaryGroup(rootGroup, trs.RootGroup); err != nil {
+		return nil, err
+	}
+
+	return &lib.Summary{
+		Metrics:         summaryMetrics,
+		RootGroup:       rootGroup,
+		TestRunDuration: time.Duration(trs.TestRunDurationMs * float64(time.Millisecond)),
+		NoColor:         gs.Flags.NoColor,
+		UIState: lib.UIState{
+			IsStdOutTTY: gs.Stdout.IsTTY,
+			IsStdErrTTY: gs.Stderr.IsTTY,
+		},
+	}, nil
+}
+
+func fillCloudSummaryGroup(group *lib.Group, sg cloudapi.SummaryGroup) error {
+	for _, sc := range sg.Checks {
+		check, err := group.Check(sc.Name)
+		if err != nil {
+			return err
+		}
+		check.Passes += sc.Passes
+		check.Fails += sc.Fails
+	}
+	for _, ssg := range sg.Groups {
+		subGroup, err := group.Group(ssg.Name)
+		if err != nil {
+			return err
+		}
+		if err = fillCloudSummaryGroup(subGroup, ssg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleCloudSummary downloads the results of a finished cloud test run and
+// passes them to the handleSummary() of the script, so that the end-of-test
+// summary looks the same as the one of a local test run.
+func handleCloudSummary(
+	ctx context.Context, gs *state.GlobalState, client *cloudapi.Client,
+	test *loadedAndConfiguredTest, refID string,
+) error {
+	gs.Logger.Debug("Downloading the results of the cloud test run for the end-of-test summary...")
+	trs, err := client.GetTestRunSummary(refID, test.derivedConfig.SummaryTrendStats)
+	if err != nil {
+		return fmt.Errorf("could not get the results of the cloud test run: %w", err)
+	}
+
+	summary, err := newCloudSummary(gs, trs)
+	if err != nil {
+		return err
+	}
+
+	summaryResult, err := test.initRunner.HandleSummary(ctx, summary)
+	if err != nil {
+		return err
+	}
+	return handleSummaryResult(gs.FS, gs.Stdout, gs.Stderr, summaryResult)
+}
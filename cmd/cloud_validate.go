@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/lib"
+)
+
+// cloudValidationError is a single problem that prevents a test from running
+// in the k6 cloud, either found locally or reported by the cloud.
+type cloudValidationError struct {
+	// The option that has the problem, e.g. `scenarios.foo.executor`. It's
+	// empty for problems that aren't specific to an option.
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e cloudValidationError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// cloudValidationErrors contains all of the problems found while validating a
+// test for the k6 cloud, so they can be fixed at once instead of one by one.
+type cloudValidationErrors []cloudValidationError
+
+func (errs cloudValidationErrors) Error() string {
+	lines := make([]string, 0, len(errs)+1)
+	lines = append(lines, fmt.Sprintf("the test can't be run in the cloud, %d problem(s) found:", len(errs)))
+	for _, e := range errs {
+		lines = append(lines, "  - "+e.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// validateCloudTest checks locally for the options that can't be used in the
+// k6 cloud, before the test is sent to it.
+func validateCloudTest(conf Config) cloudValidationErrors {
+	var errs cloudValidationErrors
+
+	if conf.ExecutionSegment != nil {
+		errs = append(errs, cloudValidationError{
+			Field:   "executionSegment",
+			Message: "execution segments aren't supported, the cloud splits the test between its instances by itself",
+		})
+	}
+	if conf.ExecutionSegmentSequence != nil {
+		errs = append(errs, cloudValidationError{
+			Field:   "executionSegmentSequence",
+			Message: "execution segments aren't supported, the cloud splits the test between its instances by itself",
+		})
+	}
+
+	scenarioNames := make([]string, 0, len(conf.Scenarios))
+	for name := range conf.Scenarios {
+		scenarioNames = append(scenarioNames, name)
+	}
+	sort.Strings(scenarioNames)
+	for _, name := range scenarioNames {
+		if sc := conf.Scenarios[name]; !sc.IsDistributable() {
+			errs = append(errs, cloudValidationError{
+				Field:   fmt.Sprintf("scenarios.%s.executor", name),
+				Message: fmt.Sprintf("the %s executor can't be distributed between cloud instances", sc.GetType()),
+			})
+		}
+	}
+
+	return append(errs, validateCloudDistribution(conf.Options)...)
+}
+
+// validateCloudDistribution checks that the load zones in
+// options.ext.loadimpact.distribution are well-formed. The cloud checks if
+// the load zones actually exist.
+func validateCloudDistribution(opts lib.Options) cloudValidationErrors {
+	rawLoadImpact, ok := opts.External["loadimpact"]
+	if !ok {
+		return nil
+	}
+	var loadImpact struct {
+		Distribution map[string]struct {
+			LoadZone string  `json:"loadZone"`
+			Percent  float64 `json:"percent"`
+		} `json:"distribution"`
+	}
+	if err := json.Unmarshal(rawLoadImpact, &loadImpact); err != nil {
+		return cloudValidationErrors{{Field: "ext.loadimpact", Message: err.Error()}}
+	}
+	if len(loadImpact.Distribution) == 0 {
+		return nil
+	}
+
+	var errs cloudValidationErrors
+	labels := make([]string, 0, len(loadImpact.Distribution))
+	for label := range loadImpact.Distribution {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	var total float64
+	for _, label := range labels {
+		dist := loadImpact.Distribution[label]
+		field := "ext.loadimpact.distribution." + label
+		if dist.LoadZone == "" {
+			errs = append(errs, cloudValidationError{Field: field + ".loadZone", Message: "the load zone is required"})
+		}
+		if dist.Percent <= 0 || dist.Percent > 100 {
+			errs = append(errs, cloudValidationError{
+				Field: field + ".percent", Message: fmt.Sprintf("%v isn't between 0 and 100", dist.Percent),
+			})
+		}
+		total += dist.Percent
+	}
+	// the percentages are floats, so they can't be compared exactly
+	if math.Abs(total-100) > 1e-9 {
+		errs = append(errs, cloudValidationError{
+			Field:   "ext.loadimpact.distribution",
+			Message: fmt.Sprintf("the percentages add up to %v instead of 100", total),
+		})
+	}
+
+	return errs
+}
+
+// cloudValidationErrorsFromResponse extracts the problems that the cloud
+// found with the test options. It returns false if err isn't a validation
+// error with the problematic fields, e.g. if the cloud couldn't be reached or
+// the token was rejected, so the real cause is reported.
+func cloudValidationErrorsFromResponse(err error) (cloudValidationErrors, bool) {
+	var errResp cloudapi.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil ||
+		(errResp.Response.StatusCode != http.StatusBadRequest &&
+			errResp.Response.StatusCode != http.StatusUnprocessableEntity) ||
+		(len(errResp.FieldErrors) == 0 && len(errResp.Details) == 0) {
+		return nil, false
+	}
+
+	var errs cloudValidationErrors
+	for _, fieldErrs := range []map[string][]string{errResp.FieldErrors, errResp.Details} {
+		fields := make([]string, 0, len(fieldErrs))
+		for field := range fieldErrs {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			for _, msg := range fieldErrs[field] {
+				e := cloudValidationError{Field: field, Message: msg}
+				if !containsCloudValidationError(errs, e) {
+					errs = append(errs, e)
+				}
+			}
+		}
+	}
+	return errs, true
+}
+
+func containsCloudValidationError(errs cloudValidationErrors, e cloudValidationError) bool {
+	for _, other := range errs {
+		if other == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/lib"
+)
+
+func TestCloudValidationErrorsFromResponse(t *testing.T) {
+	t.Parallel()
+
+	fieldErrors := map[string][]string{"options.vus": {"exceeds the limit of 100 VUs"}}
+	testCases := []struct {
+		status      int
+		fieldErrors map[string][]string
+		validation  bool
+	}{
+		{status: http.StatusBadRequest, fieldErrors: fieldErrors, validation: true},
+		{status: http.StatusUnprocessableEntity, fieldErrors: fieldErrors, validation: true},
+		{status: http.StatusBadRequest},
+		{status: http.StatusUnauthorized, fieldErrors: fieldErrors},
+		{status: http.StatusForbidden},
+		{status: http.StatusNotFound},
+		{status: http.StatusTooManyRequests},
+		{status: http.StatusInternalServerError, fieldErrors: fieldErrors},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(fmt.Sprint(tc.status), func(t *testing.T) {
+			t.Parallel()
+
+			err := fmt.Errorf("validating: %w", cloudapi.ErrorResponse{
+				Response:    &http.Response{StatusCode: tc.status},
+				Message:     "failed",
+				FieldErrors: tc.fieldErrors,
+			})
+			errs, ok := cloudValidationErrorsFromResponse(err)
+			require.Equal(t, tc.validation, ok)
+			if tc.validation {
+				assert.Equal(t, cloudValidationErrors{
+					{Field: "options.vus", Message: "exceeds the limit of 100 VUs"},
+				}, errs)
+			}
+		})
+	}
+
+	_, ok := cloudValidationErrorsFromResponse(errors.New("connection refused"))
+	assert.False(t, ok)
+}
+
+func TestValidateCloudDistribution(t *testing.T) {
+	t.Parallel()
+
+	getOptions := func(percents ...float64) lib.Options {
+		dist := make(map[string]interface{}, len(percents))
+		for i, p := range percents {
+			dist[fmt.Sprint("zone", i)] = map[string]interface{}{"loadZone": "amazon:us:ashburn", "percent": p}
+		}
+		raw, err := json.Marshal(map[string]interface{}{"distribution": dist})
+		require.NoError(t, err)
+		return lib.Options{External: map[string]json.RawMessage{"loadimpact": raw}}
+	}
+
+	assert.Empty(t, validateCloudDistribution(getOptions(33.4, 33.3, 33.3)))
+	assert.Empty(t, validateCloudDistribution(getOptions(0.1, 0.2, 99.7)))
+	assert.Equal(t, cloudValidationErrors{{
+		Field:   "ext.loadimpact.distribution",
+		Message: "the percentages add up to 90 instead of 100",
+	}}, validateCloudDistribution(getOptions(60, 30)))
+}
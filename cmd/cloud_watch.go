@@ -0,0 +1,460 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/ui/pb"
+)
+
+const cloudTestProgressPollInterval = 2 * time.Second
+
+// The supported values of the --progress-format flag of the cloud commands.
+const (
+	cloudProgressFormatBar  = "bar"
+	cloudProgressFormatJSON = "json"
+)
+
+// setupCloudProgressFormat validates the value of the --progress-format flag
+// and returns whether the progress should be printed as JSON. Since every line
+// on stdout should be a JSON object then, the rest of the output is silenced
+// as if --quiet was used.
+func setupCloudProgressFormat(gs *state.GlobalState, format string) (bool, error) {
+	switch format {
+	case cloudProgressFormatBar:
+		return false, nil
+	case cloudProgressFormatJSON:
+		gs.Flags.Quiet = true
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid progress format %q, it should be either %q or %q",
+			format, cloudProgressFormatBar, cloudProgressFormatJSON)
+	}
+}
+
+// cloudProgressEvent is printed as a single line of JSON for every progress
+// update of a cloud test run when --progress-format=json is used.
+type cloudProgressEvent struct {
+	// Either "progress" or, for the last event, "summary".
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+
+	RefID         string                `json:"ref_id"`
+	URL           string                `json:"url"`
+	RunStatus     cloudapi.RunStatus    `json:"run_status"`
+	RunStatusText string                `json:"run_status_text"`
+	ResultStatus  cloudapi.ResultStatus `json:"result_status"`
+	Progress      float64               `json:"progress"`
+
+	// The time since the test run was started by the cloud, or its duration
+	// once it has ended. It's omitted if the test run hasn't started yet.
+	ElapsedSeconds *float64 `json:"elapsed_seconds,omitempty"`
+
+	// Only set in the summary event, with the exit code and the error that
+	// the command exits with, because of the final status of the test run.
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// cloudTestRunWatcher follows a cloud test run until it finishes, rendering
+// its progress and optionally tailing its logs. It's used both by `k6 cloud`,
+// right after the test run is started, and by `k6 cloud watch`.
+type cloudTestRunWatcher struct {
+	gs          *state.GlobalState
+	client      *cloudapi.Client
+	cloudConfig cloudapi.Config
+	refID       string
+	progressBar *pb.ProgressBar
+
+	// The expected duration of the test run, used for the progress bar
+	// text. If it's not positive, only the elapsed time is shown.
+	maxDuration time.Duration
+
+	showLogs      bool
+	exitOnRunning bool
+
+	// If jsonProgress is true, a cloudProgressEvent is printed on stdout for
+	// every progress update, instead of rendering the progress bar.
+	jsonProgress bool
+	runTimes     cloudRunTimes
+}
+
+// cloudRunTimes holds the start and the end times of a cloud test run, since
+// they aren't part of its progress. They are fetched only when the progress
+// shows that the test run has started or ended and they aren't known yet.
+type cloudRunTimes struct {
+	started, ended time.Time
+	fetchFailed    bool
+}
+
+// runElapsed returns the time since the test run started, or its duration if it
+// has ended, and false if it hasn't started yet or its times are unknown.
+func (w *cloudTestRunWatcher) runElapsed(runStatus cloudapi.RunStatus) (time.Duration, bool) {
+	rt := &w.runTimes
+	if runStatus < cloudapi.RunStatusRunning {
+		return 0, false
+	}
+
+	if !rt.fetchFailed && (rt.started.IsZero() || (runStatus > cloudapi.RunStatusRunning && rt.ended.IsZero())) {
+		testRun, err := w.client.GetTestRun(w.refID)
+		if err != nil {
+			// the elapsed time is omitted, instead of retrying on every update
+			w.gs.Logger.WithError(err).Debug("Couldn't get the start time of the cloud test run")
+			rt.fetchFailed = true
+		} else {
+			rt.started, rt.ended = testRun.Started.Time, testRun.Ended.Time
+		}
+	}
+
+	switch {
+	case rt.started.IsZero():
+		return 0, false
+	case !rt.ended.IsZero():
+		return rt.ended.Sub(rt.started), true
+	default:
+		return time.Since(rt.started), true
+	}
+}
+
+// watch polls the progress of the cloud test run until it reaches a terminal
+// status, or until pollCtx is done, and returns the last received progress.
+// The progress bar rendering and the log tailing are stopped when displayCtx
+// is done, which allows callers to keep waiting for the test run to end
+// without showing anything else, e.g. after a graceful stop was requested.
+//
+//nolint:funlen
+func (w *cloudTestRunWatcher) watch(pollCtx, displayCtx context.Context) (*cloudapi.TestProgressResponse, error) {
+	logger := w.gs.Logger
+
+	displayCtx, displayCancel := context.WithCancel(displayCtx)
+	progressBarWG := &sync.WaitGroup{}
+	progressBarWG.Add(1)
+	defer progressBarWG.Wait()
+	defer displayCancel()
+	go func() {
+		if !w.jsonProgress {
+			showProgress(displayCtx, w.gs, []*pb.ProgressBar{w.progressBar}, logger)
+		}
+		progressBarWG.Done()
+	}()
+
+	var startTime time.Time
+	testProgressLock := &sync.Mutex{}
+	var testProgress *cloudapi.TestProgressResponse
+	w.progressBar.Modify(
+		pb.WithProgress(func() (float64, []string) {
+			testProgressLock.Lock()
+			defer testProgressLock.Unlock()
+
+			if testProgress == nil {
+				return 0, []string{"Waiting..."}
+			}
+
+			statusText := testProgress.RunStatusText
+
+			if testProgress.RunStatus == cloudapi.RunStatusFinished {
+				testProgress.Progress = 1
+			} else if testProgress.RunStatus == cloudapi.RunStatusRunning {
+				if startTime.IsZero() {
+					startTime = time.Now()
+				}
+				spent := time.Since(startTime)
+				switch {
+				case w.maxDuration <= 0:
+					statusText = pb.GetFixedLengthDuration(spent, spent)
+				case spent > w.maxDuration:
+					statusText = w.maxDuration.String()
+				default:
+					statusText = fmt.Sprintf("%s/%s", pb.GetFixedLengthDuration(spent, w.maxDuration), w.maxDuration)
+				}
+			}
+
+			return testProgress.Progress, []string{statusText}
+		}),
+	)
+
+	if w.showLogs {
+		go func() {
+			logger.Debug("Connecting to cloud logs server...")
+			if err := w.cloudConfig.StreamLogsToLogger(displayCtx, logger, w.refID, 0); err != nil {
+				logger.WithError(err).Error("error while tailing cloud logs")
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(cloudTestProgressPollInterval)
+	defer ticker.Stop()
+
+poll:
+	for {
+		select {
+		case <-pollCtx.Done():
+			break poll
+		case <-ticker.C:
+		}
+
+		newTestProgress, progressErr := w.client.GetTestProgress(w.refID)
+		if progressErr != nil {
+			logger.WithError(progressErr).Error("Test progress error")
+			continue
+		}
+
+		testProgressLock.Lock()
+		testProgress = newTestProgress
+		testProgressLock.Unlock()
+		if w.jsonProgress {
+			w.printJSONProgress("progress", newTestProgress)
+		}
+
+		if (newTestProgress.RunStatus > cloudapi.RunStatusRunning) ||
+			(w.exitOnRunning && newTestProgress.RunStatus == cloudapi.RunStatusRunning) {
+			break
+		}
+	}
+	displayCancel()
+
+	// The summary may fetch the times of the test run, so it's printed
+	// without holding the lock that the progress bar needs.
+	testProgressLock.Lock()
+	if testProgress == nil {
+		testProgressLock.Unlock()
+		//nolint:stylecheck,golint
+		return nil, errext.WithExitCodeIfNone(errors.New("Test progress error"), exitcodes.CloudFailedToGetProgress)
+	}
+	finalProgress := *testProgress
+	testProgressLock.Unlock()
+
+	if w.jsonProgress {
+		w.printJSONProgress("summary", &finalProgress)
+	}
+	return &finalProgress, nil
+}
+
+func (w *cloudTestRunWatcher) printJSONProgress(eventType string, testProgress *cloudapi.TestProgressResponse) {
+	event := cloudProgressEvent{
+		Type:          eventType,
+		Time:          time.Now().UTC(),
+		RefID:         w.refID,
+		URL:           cloudapi.URLForResults(w.refID, w.cloudConfig),
+		RunStatus:     testProgress.RunStatus,
+		RunStatusText: testProgress.RunStatusText,
+		ResultStatus:  testProgress.ResultStatus,
+		Progress:      testProgress.Progress,
+	}
+	if elapsed, ok := w.runElapsed(testProgress.RunStatus); ok {
+		seconds := elapsed.Seconds()
+		event.ElapsedSeconds = &seconds
+	}
+	if eventType == "summary" {
+		exitCode := 0
+		if err := cloudTestRunError(testProgress); err != nil {
+			var ecerr errext.HasExitCode
+			if errors.As(err, &ecerr) {
+				exitCode = int(ecerr.ExitCode())
+			}
+			event.Error = err.Error()
+		}
+		event.ExitCode = &exitCode
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		w.gs.Logger.WithError(err).Error("Couldn't encode the test progress")
+		return
+	}
+	printToStdout(w.gs, string(line)+"\n")
+}
+
+// printCloudTestResult prints the final status of a cloud test run and returns
+// an error with the matching exit code if the test run has failed or was aborted.
+func printCloudTestResult(gs *state.GlobalState, testProgress *cloudapi.TestProgressResponse) error {
+	if !gs.Flags.Quiet {
+		valueColor := getColor(gs.Flags.NoColor || !gs.Stdout.IsTTY, color.FgCyan)
+		printToStdout(gs, fmt.Sprintf(
+			"     test status: %s\n", valueColor.Sprint(testProgress.RunStatusText),
+		))
+	} else {
+		gs.Logger.WithField("run_status", testProgress.RunStatusText).Debug("Test finished")
+	}
+
+	return cloudTestRunError(testProgress)
+}
+
+// cloudTestRunError returns an error with a distinct exit code for every
+// terminal run status that means the cloud test run was aborted, reusing the
+// exit codes of `k6 run` where they mean the same thing. Otherwise, it returns
+// an error with the generic exit code only if the test run has failed.
+//
+//nolint:stylecheck,golint
+func cloudTestRunError(testProgress *cloudapi.TestProgressResponse) error {
+	switch testProgress.RunStatus {
+	case cloudapi.RunStatusAbortedThreshold:
+		return errext.WithExitCodeIfNone(
+			errors.New("The test was aborted because thresholds have failed"), exitcodes.ThresholdsHaveFailed)
+	case cloudapi.RunStatusAbortedScriptError:
+		return errext.WithExitCodeIfNone(
+			errors.New("The test was aborted because of a script error"), exitcodes.ScriptException)
+	case cloudapi.RunStatusAbortedUser:
+		return errext.WithExitCodeIfNone(
+			errors.New("The test was aborted by a user"), exitcodes.ExternalAbort)
+	case cloudapi.RunStatusAbortedSystem:
+		return errext.WithExitCodeIfNone(
+			errors.New("The test was aborted by the system"), exitcodes.CloudTestRunAbortedBySystem)
+	case cloudapi.RunStatusAbortedLimit:
+		return errext.WithExitCodeIfNone(
+			errors.New("The test was aborted because it exceeded a limit"), exitcodes.CloudTestRunAbortedByLimit)
+	case cloudapi.RunStatusTimedOut:
+		return errext.WithExitCodeIfNone(errors.New("The test has timed out"), exitcodes.CloudTestRunTimedOut)
+	}
+
+	if testProgress.ResultStatus == cloudapi.ResultStatusFailed {
+		return errext.WithExitCodeIfNone(errors.New("The test has failed"), exitcodes.CloudTestRunFailed)
+	}
+
+	return nil
+}
+
+// cmdCloudWatch handles the `k6 cloud watch` sub-command
+type cmdCloudWatch struct {
+	gs *state.GlobalState
+
+	showCloudLogs  bool
+	exitOnRunning  bool
+	progressFormat string
+	jsonProgress   bool
+}
+
+func (c *cmdCloudWatch) preRun(cmd *cobra.Command, _ []string) error {
+	var err error
+	if c.jsonProgress, err = setupCloudProgressFormat(c.gs, c.progressFormat); err != nil {
+		return err
+	}
+
+	// We parse the same env variables as `k6 cloud`, with the same priority.
+	if showCloudLogsEnv, ok := c.gs.Env["K6_SHOW_CLOUD_LOGS"]; ok {
+		showCloudLogsValue, err := strconv.ParseBool(showCloudLogsEnv)
+		if err != nil {
+			return fmt.Errorf("parsing K6_SHOW_CLOUD_LOGS returned an error: %w", err)
+		}
+		if !cmd.Flags().Changed("show-logs") {
+			c.showCloudLogs = showCloudLogsValue
+		}
+	}
+
+	if exitOnRunningEnv, ok := c.gs.Env["K6_EXIT_ON_RUNNING"]; ok {
+		exitOnRunningValue, err := strconv.ParseBool(exitOnRunningEnv)
+		if err != nil {
+			return fmt.Errorf("parsing K6_EXIT_ON_RUNNING returned an error: %w", err)
+		}
+		if !cmd.Flags().Changed("exit-on-running") {
+			c.exitOnRunning = exitOnRunningValue
+		}
+	}
+
+	return nil
+}
+
+func (c *cmdCloudWatch) run(cmd *cobra.Command, args []string) error {
+	printBanner(c.gs)
+
+	cloudConfig, client, err := getCloudConfigAndClient(c.gs, cmd.Flags())
+	if err != nil {
+		return err
+	}
+
+	refID := args[0]
+	testRun, err := client.GetTestRun(refID)
+	if err != nil {
+		return err
+	}
+
+	globalCtx, globalCancel := context.WithCancel(c.gs.Ctx)
+	defer globalCancel()
+
+	logger := c.gs.Logger
+	// Trap Interrupts, SIGINTs and SIGTERMs. We only stop watching the test
+	// run here, `k6 cloud stop` should be used to actually stop it.
+	gracefulStop := func(sig os.Signal) {
+		logger.WithField("sig", sig).Print("Stopped watching the cloud test run in response to signal, it will keep running")
+		globalCancel()
+	}
+	stopSignalHandling := handleTestAbortSignals(c.gs, gracefulStop, nil)
+	defer stopSignalHandling()
+
+	noColor := c.gs.Flags.NoColor || !c.gs.Stdout.IsTTY
+	valueColor := getColor(noColor, color.FgCyan)
+	description := fmt.Sprintf("  execution: %s\n", valueColor.Sprint("cloud")) +
+		fmt.Sprintf("   test run: %s\n", valueColor.Sprint(testRun.Name)) +
+		fmt.Sprintf("     output: %s\n\n", valueColor.Sprint(cloudapi.URLForResults(refID, cloudConfig)))
+	if c.gs.Flags.Quiet {
+		logger.Debug(description)
+	} else {
+		printToStdout(c.gs, description)
+	}
+
+	watcher := &cloudTestRunWatcher{
+		gs:          c.gs,
+		client:      client,
+		cloudConfig: cloudConfig,
+		refID:       refID,
+		progressBar: pb.New(
+			pb.WithConstLeft("Run "), pb.WithConstProgress(0, "Connecting to the cloud test"),
+		),
+		maxDuration:   time.Duration(testRun.Duration) * time.Second,
+		showLogs:      c.showCloudLogs,
+		exitOnRunning: c.exitOnRunning,
+		jsonProgress:  c.jsonProgress,
+	}
+	testProgress, err := watcher.watch(globalCtx, globalCtx)
+	if err != nil {
+		return err
+	}
+
+	return printCloudTestResult(c.gs, testProgress)
+}
+
+func getCmdCloudWatch(gs *state.GlobalState) *cobra.Command {
+	c := &cmdCloudWatch{
+		gs:             gs,
+		showCloudLogs:  true,
+		progressFormat: cloudProgressFormatBar,
+	}
+
+	exampleText := getExampleText(gs, `
+  # Follow a cloud test run started with "k6 cloud --exit-on-running".
+  {{.}} cloud watch 1234567`[1:])
+
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Attach to a running cloud test",
+		Long: `Attach to a running cloud test.
+
+This shows the progress and the logs of a test run that was already started on
+the k6 cloud service and exits with the same exit code as "k6 cloud" would.`,
+		Example: exampleText,
+		Args:    exactArgsWithMsg(1, "arg should be the reference ID of a cloud test run"),
+		PreRunE: c.preRun,
+		RunE:    c.run,
+	}
+	watchCmd.Flags().SortFlags = false
+	watchCmd.Flags().BoolVar(&c.exitOnRunning, "exit-on-running", c.exitOnRunning,
+		"exits when test reaches the running status")
+	watchCmd.Flags().BoolVar(&c.showCloudLogs, "show-logs", c.showCloudLogs,
+		"enable showing of logs of the cloud test run")
+	watchCmd.Flags().StringVar(&c.progressFormat, "progress-format", c.progressFormat,
+		"how to show the progress of the test run, either `bar` or json for a JSON object per line")
+	return watchCmd
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
+)
+
+func TestCloudTestRunError(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		runStatus    cloudapi.RunStatus
+		resultStatus cloudapi.ResultStatus
+		expExitCode  exitcodes.ExitCode // 0 means no error is expected
+	}{
+		{cloudapi.RunStatusRunning, cloudapi.ResultStatusPassed, 0},
+		{cloudapi.RunStatusArchived, cloudapi.ResultStatusPassed, 0},
+		{cloudapi.RunStatusFinished, cloudapi.ResultStatusPassed, 0},
+		{cloudapi.RunStatusFinished, cloudapi.ResultStatusFailed, exitcodes.CloudTestRunFailed},
+		{cloudapi.RunStatusAbortedThreshold, cloudapi.ResultStatusFailed, exitcodes.ThresholdsHaveFailed},
+		{cloudapi.RunStatusAbortedScriptError, cloudapi.ResultStatusFailed, exitcodes.ScriptException},
+		{cloudapi.RunStatusAbortedUser, cloudapi.ResultStatusPassed, exitcodes.ExternalAbort},
+		{cloudapi.RunStatusAbortedSystem, cloudapi.ResultStatusPassed, exitcodes.CloudTestRunAbortedBySystem},
+		{cloudapi.RunStatusAbortedLimit, cloudapi.ResultStatusFailed, exitcodes.CloudTestRunAbortedByLimit},
+		{cloudapi.RunStatusTimedOut, cloudapi.ResultStatusFailed, exitcodes.CloudTestRunTimedOut},
+	}
+
+	for _, tc := range testCases {
+		err := cloudTestRunError(&cloudapi.TestProgressResponse{
+			RunStatus:    tc.runStatus,
+			ResultStatus: tc.resultStatus,
+		})
+		if tc.expExitCode == 0 {
+			assert.NoError(t, err, "run status %d", tc.runStatus)
+			continue
+		}
+
+		var ecerr errext.HasExitCode
+		require.ErrorAs(t, err, &ecerr, "run status %d", tc.runStatus)
+		assert.Equal(t, tc.expExitCode, ecerr.ExitCode(), "run status %d", tc.runStatus)
+	}
+}
@@ -3,7 +3,9 @@ package cmd
 import (
 	"bytes"
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
 	"syscall"
 	"text/template"
 
@@ -11,6 +13,8 @@ import (
 	"github.com/spf13/pflag"
 	"gopkg.in/guregu/null.v3"
 
+	"go.k6.io/k6/api"
+	"go.k6.io/k6/api/v1/client"
 	"go.k6.io/k6/cmd/state"
 	"go.k6.io/k6/errext/exitcodes"
 	"go.k6.io/k6/lib/types"
@@ -68,6 +72,29 @@ func exactArgsWithMsg(n int, msg string) cobra.PositionalArgs {
 	}
 }
 
+// newAPIClient creates the client for the REST API server at the --address,
+// with the bearer token from the K6_API_TOKEN environment variable. With any
+// of the K6_API_TLS_CA, K6_API_TLS_CLIENT_CERT and K6_API_TLS_CLIENT_KEY
+// environment variables, the server is reached over HTTPS.
+func newAPIClient(gs *state.GlobalState) (*client.Client, error) {
+	tlsConfig, err := api.NewClientConfig(gs.Env).TLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid REST API client TLS configuration: %w", err)
+	}
+
+	address := gs.Flags.Address
+	options := []client.Option{client.WithToken(gs.Env["K6_API_TOKEN"])}
+	if tlsConfig != nil {
+		if !strings.Contains(address, "://") {
+			address = "https://" + address
+		}
+		options = append(options, client.WithHTTPClient(&http.Client{
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		}))
+	}
+	return client.New(address, options...)
+}
+
 func printToStdout(gs *state.GlobalState, s string) {
 	if _, err := fmt.Fprint(gs.Stdout, s); err != nil {
 		gs.Logger.Errorf("could not print '%s' to stdout: %s", s, err.Error())
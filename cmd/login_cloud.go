@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"syscall"
+	"text/tabwriter"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -28,7 +31,16 @@ func getCmdLoginCloud(gs *state.GlobalState) *cobra.Command {
   {{.}} login cloud -t YOUR_TOKEN
   
   # Log in with an email/password.
-  {{.}} login cloud`[1:])
+  {{.}} login cloud
+
+  # Store a token for a different cloud stack in a named profile.
+  {{.}} login cloud --profile staging --host https://ingest.staging.example.com -t YOUR_TOKEN
+
+  # List the saved profiles.
+  {{.}} login cloud --list
+
+  # Store the token with the k6-credential-pass program instead of in the config file.
+  {{.}} login cloud --credential-helper pass -t YOUR_TOKEN`[1:])
 
 	loginCloudCommand := &cobra.Command{
 		Use:   "cloud",
@@ -53,10 +65,28 @@ This will set the default token used when just "k6 run -o cloud" is passed.`,
 				}
 			}
 
+			// The active profile may not exist yet, e.g. because it's being
+			// created now, so it's only used if it's in the saved config.
+			env := gs.Env
+			activeProfile := currentJSONConfig.Profile
+			if envProfile, ok := gs.Env["K6_CLOUD_PROFILE"]; ok {
+				activeProfile = null.StringFrom(envProfile)
+			}
+			if getNullBool(cmd.Flags(), "list").Bool {
+				return printCloudProfiles(gs, activeProfile.String, currentJSONConfig.Profiles)
+			}
+			if _, ok := currentJSONConfig.Profiles[activeProfile.String]; !ok && activeProfile.String != "" {
+				env = make(map[string]string, len(gs.Env)+1)
+				for k, v := range gs.Env {
+					env[k] = v
+				}
+				env["K6_CLOUD_PROFILE"] = ""
+			}
+
 			// We want to use this fully consolidated config for things like
 			// host addresses, so users can overwrite them with env vars.
 			consolidatedCurrentConfig, err := cloudapi.GetConsolidatedConfig(
-				currentJSONConfigRaw, gs.Env, "", nil)
+				currentJSONConfigRaw, env, "", nil)
 			if err != nil {
 				return err
 			}
@@ -64,16 +94,42 @@ This will set the default token used when just "k6 run -o cloud" is passed.`,
 			// want to save what already existed there and the login details.
 			newCloudConf := currentJSONConfig
 
+			// The login details are saved either in the top-level cloud
+			// config or, if a profile was specified, in that profile.
+			profile := getNullString(cmd.Flags(), "profile")
+			target := cloudapi.Profile{
+				Token:     newCloudConf.Token,
+				Host:      newCloudConf.Host,
+				ProjectID: newCloudConf.ProjectID,
+				WebAppURL: newCloudConf.WebAppURL,
+			}
+			if profile.Valid {
+				target = newCloudConf.Profiles[profile.String]
+			}
+			if host := getNullString(cmd.Flags(), "host"); host.Valid {
+				target.Host = host
+			}
+			if projectID := getNullInt64(cmd.Flags(), "project-id"); projectID.Valid {
+				target.ProjectID = projectID
+			}
+			if webAppURL := getNullString(cmd.Flags(), "web-app-url"); webAppURL.Valid {
+				target.WebAppURL = webAppURL
+			}
+			loginHost := consolidatedCurrentConfig.Host.String
+			if target.Host.Valid {
+				loginHost = target.Host.String
+			}
+
 			show := getNullBool(cmd.Flags(), "show")
 			reset := getNullBool(cmd.Flags(), "reset")
 			token := getNullString(cmd.Flags(), "token")
 			switch {
 			case reset.Valid:
-				newCloudConf.Token = null.StringFromPtr(nil)
+				target.Token = null.StringFromPtr(nil)
 				printToStdout(gs, "  token reset\n")
 			case show.Bool:
 			case token.Valid:
-				newCloudConf.Token = token
+				target.Token = token
 			default:
 				form := ui.Form{
 					Fields: []ui.Field{
@@ -101,7 +157,7 @@ This will set the default token used when just "k6 run -o cloud" is passed.`,
 				client := cloudapi.NewClient(
 					gs.Logger,
 					"",
-					consolidatedCurrentConfig.Host.String,
+					loginHost,
 					consts.Version,
 					consolidatedCurrentConfig.Timeout.TimeDuration())
 
@@ -115,7 +171,59 @@ This will set the default token used when just "k6 run -o cloud" is passed.`,
 					return errors.New(`your account has no API token, please generate one at https://app.k6.io/account/api-token`)
 				}
 
-				newCloudConf.Token = null.StringFrom(res.Token)
+				target.Token = null.StringFrom(res.Token)
+			}
+
+			// With a credential helper, the token is never saved in the config
+			// file, and any token that was previously saved there is removed.
+			savedToken, savedIn := target.Token, gs.Flags.ConfigFilePath
+			helperName := consolidatedCurrentConfig.CredentialHelper
+			if h := getNullString(cmd.Flags(), "credential-helper"); h.Valid {
+				helperName = h
+				newCloudConf.CredentialHelper = h
+			}
+			if helperName.Valid && helperName.String != "" {
+				helper := cloudapi.NewCredentialHelper(helperName.String)
+				switch {
+				case reset.Valid:
+					err = helper.Erase(loginHost)
+				case show.Bool:
+					if !savedToken.Valid {
+						var helperToken string
+						helperToken, err = helper.Get(loginHost)
+						if err == nil {
+							savedToken = null.StringFrom(helperToken)
+						} else if errors.Is(err, cloudapi.ErrCredentialsNotFound) {
+							err = nil
+						}
+					}
+				case target.Token.Valid:
+					err = helper.Store(loginHost, target.Token.String)
+				}
+				if err != nil {
+					return err
+				}
+				if !show.Bool {
+					target.Token = null.StringFromPtr(nil)
+				}
+				savedIn = fmt.Sprintf("the %s credential helper", helperName.String)
+			}
+
+			if profile.Valid {
+				profiles := make(map[string]cloudapi.Profile, len(newCloudConf.Profiles)+1)
+				for name, p := range newCloudConf.Profiles {
+					profiles[name] = p
+				}
+				profiles[profile.String] = target
+				newCloudConf.Profiles = profiles
+				if savedIn == gs.Flags.ConfigFilePath {
+					savedIn = fmt.Sprintf("the %q profile in %s", profile.String, gs.Flags.ConfigFilePath)
+				}
+			} else {
+				newCloudConf.Token = target.Token
+				newCloudConf.Host = target.Host
+				newCloudConf.ProjectID = target.ProjectID
+				newCloudConf.WebAppURL = target.WebAppURL
 			}
 
 			if currentDiskConf.Collectors == nil {
@@ -129,14 +237,12 @@ This will set the default token used when just "k6 run -o cloud" is passed.`,
 				return err
 			}
 
-			if newCloudConf.Token.Valid {
+			if savedToken.Valid {
 				valueColor := getColor(gs.Flags.NoColor || !gs.Stdout.IsTTY, color.FgCyan)
 				if !gs.Flags.Quiet {
-					printToStdout(gs, fmt.Sprintf("  token: %s\n", valueColor.Sprint(newCloudConf.Token.String)))
+					printToStdout(gs, fmt.Sprintf("  token: %s\n", valueColor.Sprint(savedToken.String)))
 				}
-				printToStdout(gs, fmt.Sprintf(
-					"Logged in successfully, token saved in %s\n", gs.Flags.ConfigFilePath,
-				))
+				printToStdout(gs, fmt.Sprintf("Logged in successfully, token saved in %s\n", savedIn))
 			}
 			return nil
 		},
@@ -145,6 +251,57 @@ This will set the default token used when just "k6 run -o cloud" is passed.`,
 	loginCloudCommand.Flags().StringP("token", "t", "", "specify `token` to use")
 	loginCloudCommand.Flags().BoolP("show", "s", false, "display saved token and exit")
 	loginCloudCommand.Flags().BoolP("reset", "r", false, "reset token")
+	loginCloudCommand.Flags().String("profile", "", "save the login details in the cloud profile with this `name`")
+	loginCloudCommand.Flags().Bool("list", false, "list the saved cloud profiles and exit")
+	loginCloudCommand.Flags().String("host", "", "the cloud API `url` to save, e.g. for a different cloud stack")
+	loginCloudCommand.Flags().Int64("project-id", 0, "the default project `id` to save")
+	loginCloudCommand.Flags().String("credential-helper", "",
+		"save the token with the k6-credential-`name` program instead of in the config file")
+	loginCloudCommand.Flags().String("web-app-url", "", "the cloud web app `url` to save, used for the test run links")
 
 	return loginCloudCommand
 }
+
+// printCloudProfiles prints the names and the details, except for the tokens,
+// of the saved cloud profiles. The active profile is marked with an asterisk.
+func printCloudProfiles(gs *state.GlobalState, active string, profiles map[string]cloudapi.Profile) error {
+	if len(profiles) == 0 {
+		printToStdout(gs, "No cloud profiles saved, use `k6 login cloud --profile <name>` to create one\n")
+		return nil
+	}
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	valueOrDash := func(v string, valid bool) string {
+		if !valid || v == "" {
+			return "-"
+		}
+		return v
+	}
+
+	tw := tabwriter.NewWriter(gs.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "  PROFILE\tHOST\tPROJECT ID\tWEB APP URL\tTOKEN")
+	for _, name := range names {
+		p := profiles[name]
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+		projectID := "-"
+		if p.ProjectID.Valid {
+			projectID = strconv.FormatInt(p.ProjectID.Int64, 10)
+		}
+		tokenState := "not set"
+		if p.Token.Valid && p.Token.String != "" {
+			tokenState = "set"
+		}
+		_, _ = fmt.Fprintf(tw, "%s %s\t%s\t%s\t%s\t%s\n", marker, name,
+			valueOrDash(p.Host.String, p.Host.Valid), projectID,
+			valueOrDash(p.WebAppURL.String, p.WebAppURL.Valid), tokenState)
+	}
+	return tw.Flush()
+}
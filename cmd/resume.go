@@ -5,7 +5,6 @@ import (
 	"gopkg.in/guregu/null.v3"
 
 	v1 "go.k6.io/k6/api/v1"
-	"go.k6.io/k6/api/v1/client"
 	"go.k6.io/k6/cmd/state"
 )
 
@@ -16,9 +15,12 @@ func getCmdResume(gs *state.GlobalState) *cobra.Command {
 		Short: "Resume a paused test",
 		Long: `Resume a paused test.
 
-  Use the global --address flag to specify the URL to the API server.`,
+  Use the global --address flag to specify the URL to the API server, and the
+  K6_API_TOKEN environment variable for its bearer token. For a server with TLS,
+  K6_API_TLS_CA can specify its CA, and K6_API_TLS_CLIENT_CERT and
+  K6_API_TLS_CLIENT_KEY the client certificate.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, err := client.New(gs.Flags.Address)
+			c, err := newAPIClient(gs)
 			if err != nil {
 				return err
 			}
@@ -191,10 +191,12 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 		defer func() {
 			logger.Debug("Generating the end-of-test summary...")
 			summaryResult, hsErr := test.initRunner.HandleSummary(globalCtx, &lib.Summary{
-				Metrics:         metricsEngine.ObservedMetrics,
-				RootGroup:       testRunState.Runner.GetDefaultGroup(),
-				TestRunDuration: executionState.GetCurrentTestRunDuration(),
-				NoColor:         c.gs.Flags.NoColor,
+				Metrics:           metricsEngine.ObservedMetrics,
+				Annotations:       metricsEngine.Annotations,
+				ThresholdsChanges: metricsEngine.ThresholdsChanges,
+				RootGroup:         testRunState.Runner.GetDefaultGroup(),
+				TestRunDuration:   executionState.GetCurrentTestRunDuration(),
+				NoColor:           c.gs.Flags.NoColor,
 				UIState: lib.UIState{
 					IsStdOutTTY: c.gs.Stdout.IsTTY,
 					IsStdErrTTY: c.gs.Stderr.IsTTY,
@@ -245,7 +247,6 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 			// the OutputManager has flushed all of the cached samples to
 			// outputs (including MetricsEngine's ingester). So we are sure
 			// there won't be any more metrics being sent.
-			logger.Debug("Finalizing thresholds...")
 			breachedThresholds := finalizeThresholds()
 			if len(breachedThresholds) == 0 {
 				return
@@ -262,9 +263,7 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 				logger.WithError(tErr).Debug("Crossed thresholds, but test already exited with another error")
 			}
 		}
-		if finalizeThresholds != nil {
-			defer handleFinalThresholdCalculation()
-		}
+		defer handleFinalThresholdCalculation()
 	}
 
 	defer func() {
@@ -293,6 +292,19 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 	if c.gs.Flags.Address != "" { //nolint:nestif
 		initBar.Modify(pb.WithConstProgress(0, "Init API server"))
 
+		srv, err := api.GetServer(
+			runCtx,
+			c.gs.Flags.Address, c.gs.Flags.ProfilingEnabled,
+			api.NewConfig(c.gs.Env),
+			testRunState,
+			samples,
+			metricsEngine,
+			execScheduler,
+		)
+		if err != nil {
+			return err
+		}
+
 		// We cannot use backgroundProcesses here, since we need the REST API to
 		// be down before we can close the samples channel above and finish the
 		// processing the metrics pipeline.
@@ -303,21 +315,21 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 		srvCtx, srvCancel := context.WithCancel(globalCtx)
 		defer srvCancel()
 
-		srv := api.GetServer(
-			runCtx,
-			c.gs.Flags.Address, c.gs.Flags.ProfilingEnabled,
-			testRunState,
-			samples,
-			metricsEngine,
-			execScheduler,
-		)
 		go func() {
 			defer apiWG.Done()
-			logger.Debugf("Starting the REST API server on %s", c.gs.Flags.Address)
+			scheme := "http"
+			if srv.TLSConfig != nil {
+				scheme = "https"
+			}
+			logger.Debugf("Starting the REST API server on %s://%s", scheme, c.gs.Flags.Address)
 			if c.gs.Flags.ProfilingEnabled {
-				logger.Debugf("Profiling exposed on http://%s/debug/pprof/", c.gs.Flags.Address)
+				logger.Debugf("Profiling exposed on %s://%s/debug/pprof/", scheme, c.gs.Flags.Address)
+			}
+			serve := srv.ListenAndServe
+			if srv.TLSConfig != nil {
+				serve = func() error { return srv.ListenAndServeTLS("", "") }
 			}
-			if aerr := srv.ListenAndServe(); aerr != nil && !errors.Is(aerr, http.ErrServerClosed) {
+			if aerr := serve(); aerr != nil && !errors.Is(aerr, http.ErrServerClosed) {
 				// Only exit k6 if the user has explicitly set the REST API address
 				if cmd.Flags().Lookup("address").Changed {
 					logger.WithError(aerr).Error("Error from API server")
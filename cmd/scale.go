@@ -6,7 +6,6 @@ import (
 	"github.com/spf13/cobra"
 
 	v1 "go.k6.io/k6/api/v1"
-	"go.k6.io/k6/api/v1/client"
 	"go.k6.io/k6/cmd/state"
 )
 
@@ -17,7 +16,10 @@ func getCmdScale(gs *state.GlobalState) *cobra.Command {
 		Short: "Scale a running test",
 		Long: `Scale a running test.
 
-  Use the global --address flag to specify the URL to the API server.`,
+  Use the global --address flag to specify the URL to the API server, and the
+  K6_API_TOKEN environment variable for its bearer token. For a server with TLS,
+  K6_API_TLS_CA can specify its CA, and K6_API_TLS_CLIENT_CERT and
+  K6_API_TLS_CLIENT_KEY the client certificate.`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			vus := getNullInt64(cmd.Flags(), "vus")
 			max := getNullInt64(cmd.Flags(), "max")
@@ -25,7 +27,7 @@ func getCmdScale(gs *state.GlobalState) *cobra.Command {
 				return errors.New("Specify either -u/--vus or -m/--max") //nolint:golint,stylecheck
 			}
 
-			c, err := client.New(gs.Flags.Address)
+			c, err := newAPIClient(gs)
 			if err != nil {
 				return err
 			}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -8,14 +9,24 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"testing"
+	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
 	"go.k6.io/k6/cloudapi"
 	"go.k6.io/k6/cmd"
+	"go.k6.io/k6/errext/exitcodes"
 	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/output/cloud/expv2/pbcloud"
 )
 
 func cloudTestStartSimple(tb testing.TB, testRunID int) http.Handler {
@@ -74,6 +85,12 @@ func getSimpleCloudTestState(
 
 	srv := getMockCloud(t, 123, archiveUpload, progressCallback)
 
+	return getCloudTestState(t, srv, script, cliFlags)
+}
+
+// getCloudTestState returns the state for running `k6 cloud` with the script
+// and the flags, against the mock cloud server.
+func getCloudTestState(t *testing.T, srv *httptest.Server, script []byte, cliFlags []string) *GlobalTestState {
 	ts := NewGlobalTestState(t)
 	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "test.js"), script, 0o644))
 	ts.CmdArgs = append(append([]string{"k6", "cloud"}, cliFlags...), "test.js")
@@ -198,6 +215,24 @@ func TestCloudWithConfigOverride(t *testing.T) {
 	assert.Contains(t, stdout, `level=error msg="test error" source=grafana-k6-cloud`)
 }
 
+func TestCloudWithoutLogsTailCapability(t *testing.T) {
+	t.Parallel()
+
+	withoutLogsTail := http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		resp.WriteHeader(http.StatusOK)
+		_, err := fmt.Fprint(resp, `{"reference_id": "123", "capabilities": {"logs_tail": false}}`)
+		assert.NoError(t, err)
+	})
+	ts := getSimpleCloudTestState(t, nil, nil, withoutLogsTail, nil)
+	ts.Env["K6_SHOW_CLOUD_LOGS"] = "true"
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Contains(t, stdout, `level=warning msg="The logs tailing isn't enabled for the organization in the cloud, the logs won't be shown"`)
+	assert.NotContains(t, stdout, "Connecting to cloud logs server")
+}
+
 // TestCloudWithArchive tests that if k6 uses a static archive with the script inside that has cloud options like:
 //
 //	export let options = {
@@ -290,3 +325,673 @@ func TestCloudWithArchive(t *testing.T) {
 	assert.Contains(t, stdout, `output: https://app.k6.io/runs/123`)
 	assert.Contains(t, stdout, `test status: Finished`)
 }
+
+func TestCloudRunsList(t *testing.T) {
+	t.Parallel()
+
+	pages := map[string]string{
+		"1": `{"test_runs": [
+			{"reference_id": "125", "name": "third", "project_id": 12, "run_status": 2, "run_status_text": "Running"},
+			{"reference_id": "124", "name": "second", "project_id": 12, "run_status": 3, "run_status_text": "Finished"}
+		], "next_page": 2}`,
+		"2": `{"test_runs": [
+			{"reference_id": "123", "name": "first", "project_id": 12, "run_status": 3, "run_status_text": "Finished",
+			 "result_status": 1}
+		], "next_page": 0}`,
+	}
+	srv := getTestServer(t, map[string]http.Handler{
+		"GET ^/v1/tests\\?": http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, "12", req.URL.Query().Get("project_id"))
+			assert.NotEmpty(t, req.URL.Query().Get("created_after"))
+			_, err := fmt.Fprint(resp, pages[req.URL.Query().Get("page")])
+			assert.NoError(t, err)
+		}),
+	})
+	t.Cleanup(srv.Close)
+
+	ts := NewGlobalTestState(t)
+	ts.CmdArgs = []string{"k6", "cloud", "runs", "list", "--project", "12", "--since", "7d"}
+	ts.Env["K6_CLOUD_HOST"] = srv.URL
+	ts.Env["K6_CLOUD_TOKEN"] = "foo"
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Regexp(t, `REFERENCE ID\s+NAME\s+PROJECT\s+STATUS\s+RESULT\s+CREATED`, stdout)
+	assert.Regexp(t, `125\s+third\s+12\s+Running\s+-`, stdout)
+	assert.Regexp(t, `124\s+second\s+12\s+Finished\s+passed`, stdout)
+	assert.Regexp(t, `123\s+first\s+12\s+Finished\s+failed`, stdout)
+
+	ts = NewGlobalTestState(t)
+	ts.CmdArgs = []string{"k6", "cloud", "runs", "list", "--project", "12", "--since", "7d", "--limit", "1", "--json"}
+	ts.Env["K6_CLOUD_HOST"] = srv.URL
+	ts.Env["K6_CLOUD_TOKEN"] = "foo"
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	var runs []cloudapi.TestRunDetails
+	require.NoError(t, json.Unmarshal(ts.Stdout.Bytes(), &runs))
+	require.Len(t, runs, 1)
+	assert.Equal(t, "125", runs[0].ReferenceID)
+}
+
+func TestCloudRunsGet(t *testing.T) {
+	t.Parallel()
+
+	srv := getTestServer(t, map[string]http.Handler{
+		"GET ^/v1/tests/123$": http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			_, err := fmt.Fprint(resp, `{
+				"reference_id": "123", "name": "my test", "project_id": 12, "vus": 10,
+				"run_status": 8, "run_status_text": "Aborted (by threshold)", "result_status": 1
+			}`)
+			assert.NoError(t, err)
+		}),
+	})
+	t.Cleanup(srv.Close)
+
+	ts := NewGlobalTestState(t)
+	ts.CmdArgs = []string{"k6", "cloud", "runs", "get", "123"}
+	ts.Env["K6_CLOUD_HOST"] = srv.URL
+	ts.Env["K6_CLOUD_TOKEN"] = "foo"
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Regexp(t, `name:\s+my test`, stdout)
+	assert.Regexp(t, `status:\s+Aborted \(by threshold\)`, stdout)
+	assert.Regexp(t, `result:\s+failed`, stdout)
+	assert.Regexp(t, `started:\s+-`, stdout)
+	assert.Regexp(t, `url:\s+https://app.k6.io/runs/123`, stdout)
+}
+
+func TestCloudWatch(t *testing.T) {
+	t.Parallel()
+
+	var progressCalls int32
+	cs := func() cloudapi.TestProgressResponse {
+		if atomic.AddInt32(&progressCalls, 1) == 1 {
+			return cloudapi.TestProgressResponse{
+				RunStatusText: "Running",
+				RunStatus:     cloudapi.RunStatusRunning,
+				Progress:      0.5,
+			}
+		}
+		return cloudapi.TestProgressResponse{
+			RunStatusText: "Finished",
+			RunStatus:     cloudapi.RunStatusFinished,
+			ResultStatus:  cloudapi.ResultStatusFailed,
+			Progress:      1,
+		}
+	}
+	srv := getMockCloud(t, 123, nil, cs)
+
+	ts := NewGlobalTestState(t)
+	ts.CmdArgs = []string{"k6", "cloud", "watch", "--log-output=stdout", "123"}
+	ts.Env["K6_SHOW_CLOUD_LOGS"] = "false" // no mock for the logs yet
+	ts.Env["K6_CLOUD_HOST"] = srv.URL
+	ts.Env["K6_CLOUD_TOKEN"] = "foo"
+	ts.ExpectedExitCode = int(exitcodes.CloudTestRunFailed)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&progressCalls))
+	assert.Contains(t, stdout, `execution: cloud`)
+	assert.Contains(t, stdout, `output: https://app.k6.io/runs/123`)
+	assert.Contains(t, stdout, `test status: Finished`)
+	assert.Contains(t, stdout, `The test has failed`)
+}
+
+func TestCloudStop(t *testing.T) {
+	t.Parallel()
+
+	var stopCalled, abortCalled bool
+	srv := getTestServer(t, map[string]http.Handler{
+		"POST ^/v1/tests/123/stop$": http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			stopCalled = true
+		}),
+		"POST ^/v1/tests/123/abort$": http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			abortCalled = true
+		}),
+		"GET ^/v1/test-progress/123$": http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			_, err := fmt.Fprint(resp, `{"run_status_text": "Aborted (by user)", "run_status": 5}`)
+			assert.NoError(t, err)
+		}),
+	})
+	t.Cleanup(srv.Close)
+
+	ts := NewGlobalTestState(t)
+	ts.CmdArgs = []string{"k6", "cloud", "stop", "123"}
+	ts.Env["K6_CLOUD_HOST"] = srv.URL
+	ts.Env["K6_CLOUD_TOKEN"] = "foo"
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	assert.True(t, stopCalled)
+	assert.False(t, abortCalled)
+	assert.Contains(t, ts.Stdout.String(), "Successfully sent signal to stop the cloud test run 123")
+
+	ts = NewGlobalTestState(t)
+	ts.CmdArgs = []string{"k6", "cloud", "abort", "--wait", "--log-output=stdout", "123"}
+	ts.Env["K6_CLOUD_HOST"] = srv.URL
+	ts.Env["K6_CLOUD_TOKEN"] = "foo"
+	ts.ExpectedExitCode = int(exitcodes.ExternalAbort)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.True(t, abortCalled)
+	assert.Contains(t, stdout, "test status: Aborted (by user)")
+	assert.Contains(t, stdout, "The test was aborted by a user")
+}
+
+func TestCloudStopWaitInterrupted(t *testing.T) {
+	t.Parallel()
+
+	progressCalled := make(chan struct{}, 100)
+	srv := getTestServer(t, map[string]http.Handler{
+		"POST ^/v1/tests/123/stop$": http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {}),
+		"GET ^/v1/test-progress/123$": http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			progressCalled <- struct{}{}
+			_, err := fmt.Fprint(resp, `{"run_status_text": "Running", "run_status": 2}`)
+			assert.NoError(t, err)
+		}),
+	})
+	t.Cleanup(srv.Close)
+
+	ts := NewGlobalTestState(t)
+	ts.CmdArgs = []string{"k6", "cloud", "stop", "--wait", "--log-output=stdout", "123"}
+	ts.Env["K6_CLOUD_HOST"] = srv.URL
+	ts.Env["K6_CLOUD_TOKEN"] = "foo"
+	ts.ExpectedExitCode = int(exitcodes.ExternalAbort)
+	sendSignal := injectMockSignalNotifier(ts)
+	go func() {
+		<-progressCalled
+		sendSignal <- syscall.SIGINT
+		<-sendSignal
+	}()
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Contains(t, stdout, "stopped waiting before the cloud test run 123 had stopped")
+}
+
+func TestCloudSummary(t *testing.T) {
+	t.Parallel()
+
+	srv := getTestServer(t, map[string]http.Handler{
+		"POST ^/v1/archive-upload$": cloudTestStartSimple(t, 123),
+		"GET ^/v1/test-progress/123$": http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			_, err := fmt.Fprint(resp, `{"run_status_text": "Finished", "run_status": 3, "progress": 1}`)
+			assert.NoError(t, err)
+		}),
+		"GET ^/v1/tests/123/summary": http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, "avg,p(99)", req.URL.Query().Get("trend_stats"))
+			_, err := fmt.Fprint(resp, `{
+				"test_run_duration_ms": 30000,
+				"metrics": {
+					"http_req_duration": {
+						"type": "trend", "contains": "time",
+						"values": {"avg": 123.45, "p(99)": 456.78},
+						"thresholds": {"p(99)<500": {"ok": true}}
+					},
+					"http_reqs": {"type": "counter", "contains": "default", "values": {"count": 300, "rate": 10}},
+					"checks": {"type": "rate", "contains": "default", "values": {"rate": 0.5, "passes": 1, "fails": 1}}
+				},
+				"root_group": {
+					"name": "",
+					"checks": [{"name": "status is 200", "passes": 1, "fails": 0}],
+					"groups": [{"name": "my group", "checks": [{"name": "body is ok", "passes": 0, "fails": 1}]}]
+				}
+			}`)
+			assert.NoError(t, err)
+		}),
+	})
+	t.Cleanup(srv.Close)
+
+	script := `
+		export const options = { summaryTrendStats: ["avg", "p(99)"] };
+		export default function() {};
+	`
+	ts := getSimpleCloudTestState(t, []byte(script), []string{"--summary-export=summary.json"}, nil, nil)
+	ts.Env["K6_CLOUD_HOST"] = srv.URL
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Contains(t, stdout, `test status: Finished`)
+	assert.Contains(t, stdout, `✓ status is 200`)
+	assert.Contains(t, stdout, `█ my group`)
+	assert.Contains(t, stdout, `✗ body is ok`)
+	assert.Regexp(t, `✓ http_req_duration\.*: avg=123\.45ms p\(99\)=456\.78ms`, stdout)
+	assert.Regexp(t, `http_reqs\.*: 300\s+10/s`, stdout)
+
+	exported, err := fsext.ReadFile(ts.FS, "summary.json")
+	require.NoError(t, err)
+	var summary struct {
+		Metrics map[string]map[string]interface{} `json:"metrics"`
+	}
+	require.NoError(t, json.Unmarshal(exported, &summary))
+	assert.Equal(t, 123.45, summary.Metrics["http_req_duration"]["avg"])
+	assert.Equal(t, 300.0, summary.Metrics["http_reqs"]["count"])
+}
+
+func TestCloudProfiles(t *testing.T) {
+	t.Parallel()
+
+	srv := getTestServer(t, map[string]http.Handler{
+		"GET ^/v1/tests/123$": http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, "Token bar", req.Header.Get("Authorization"))
+			_, err := fmt.Fprint(resp, `{"reference_id": "123", "name": "my test", "project_id": 12}`)
+			assert.NoError(t, err)
+		}),
+	})
+	t.Cleanup(srv.Close)
+
+	ts := NewGlobalTestState(t)
+	ts.CmdArgs = []string{"k6", "login", "cloud", "-t", "foo"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Contains(t, ts.Stdout.String(), "token saved in .config")
+
+	ts.Stdout.Reset()
+	ts.CmdArgs = []string{"k6", "login", "cloud", "--profile", "staging", "--host", srv.URL, "-t", "bar"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Contains(t, ts.Stdout.String(), `token saved in the "staging" profile`)
+
+	ts.Stdout.Reset()
+	ts.CmdArgs = []string{"k6", "login", "cloud", "--list"}
+	ts.Env["K6_CLOUD_PROFILE"] = "staging"
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Regexp(t, `PROFILE\s+HOST\s+PROJECT ID\s+WEB APP URL\s+TOKEN`, stdout)
+	assert.Regexp(t, `\* staging\s+`+regexp.QuoteMeta(srv.URL)+`\s+-\s+-\s+set`, stdout)
+	assert.NotContains(t, stdout, "bar")
+
+	ts.Stdout.Reset()
+	delete(ts.Env, "K6_CLOUD_PROFILE")
+	ts.CmdArgs = []string{"k6", "cloud", "runs", "get", "--profile", "staging", "123"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Regexp(t, `name:\s+my test`, ts.Stdout.String())
+
+	// the login command works even if the active profile doesn't exist yet
+	ts.Stdout.Reset()
+	ts.Env["K6_CLOUD_PROFILE"] = "missing"
+	ts.CmdArgs = []string{"k6", "login", "cloud", "--list"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Regexp(t, `  staging\s+`, ts.Stdout.String())
+
+	ts.Stdout.Reset()
+	ts.CmdArgs = []string{"k6", "login", "cloud", "--profile", "missing", "--host", srv.URL, "-t", "baz"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Contains(t, ts.Stdout.String(), `token saved in the "missing" profile`)
+	delete(ts.Env, "K6_CLOUD_PROFILE")
+
+	ts.Stdout.Reset()
+	ts.CmdArgs = []string{"k6", "cloud", "runs", "get", "--log-output=stdout", "--profile", "unknown", "123"}
+	ts.ExpectedExitCode = -1
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Contains(t, ts.Stdout.String(), `the cloud profile \"unknown\" doesn't exist`)
+}
+
+func TestCloudCredentialHelper(t *testing.T) {
+	t.Parallel()
+
+	helper := testutils.WriteFakeCredentialHelper(t)
+
+	srv := getTestServer(t, map[string]http.Handler{
+		"GET ^/v1/tests/123$": http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, "Token secret", req.Header.Get("Authorization"))
+			_, err := fmt.Fprint(resp, `{"reference_id": "123", "name": "my test", "project_id": 12}`)
+			assert.NoError(t, err)
+		}),
+	})
+	t.Cleanup(srv.Close)
+
+	ts := NewGlobalTestState(t)
+	ts.Env["K6_CLOUD_HOST"] = srv.URL
+	ts.CmdArgs = []string{"k6", "login", "cloud", "--credential-helper", helper, "-t", "secret"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Contains(t, ts.Stdout.String(), "token saved in the "+helper+" credential helper")
+
+	configData, err := fsext.ReadFile(ts.FS, ts.Flags.ConfigFilePath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(configData), "secret")
+	assert.Contains(t, string(configData), helper)
+
+	ts.Stdout.Reset()
+	ts.CmdArgs = []string{"k6", "cloud", "runs", "get", "123"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Regexp(t, `name:\s+my test`, ts.Stdout.String())
+
+	ts.Stdout.Reset()
+	ts.CmdArgs = []string{"k6", "login", "cloud", "--show"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Contains(t, ts.Stdout.String(), "token: secret")
+
+	ts.Stdout.Reset()
+	ts.CmdArgs = []string{"k6", "login", "cloud", "--reset"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(helper), "token"))
+}
+
+func TestCloudValidateOnly(t *testing.T) {
+	t.Parallel()
+
+	noUpload := http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		t.Error("the archive shouldn't be uploaded")
+		resp.WriteHeader(http.StatusBadRequest)
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		ts := getSimpleCloudTestState(t, nil, []string{"--validate-only", "--log-output=stdout"}, noUpload, nil)
+		cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+		stdout := ts.Stdout.String()
+		t.Log(stdout)
+		assert.Contains(t, stdout, "The test is valid and can be run in the cloud")
+		assert.NotContains(t, stdout, "test status")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		srv := getTestServer(t, map[string]http.Handler{
+			"POST ^/v1/archive-upload$": noUpload,
+			"POST ^/v1/validate-options$": http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusBadRequest)
+				_, err := fmt.Fprint(resp, `{"error": {"code": 4, "message": "Validation failed",
+					"field_errors": {"options.vus": ["exceeds the limit of 100 VUs"]}}}`)
+				assert.NoError(t, err)
+			}),
+		})
+		t.Cleanup(srv.Close)
+
+		script := `
+			export const options = {
+				scenarios: {
+					ext: { executor: "externally-controlled", maxVUs: 10, duration: "10s" },
+				},
+				ext: {
+					loadimpact: {
+						distribution: {
+							a: { loadZone: "amazon:us:ashburn", percent: 60 },
+							b: { loadZone: "", percent: 30 },
+						},
+					},
+				},
+			};
+			export default function() {};
+		`
+		ts := NewGlobalTestState(t)
+		require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "test.js"), []byte(script), 0o644))
+		ts.CmdArgs = []string{"k6", "cloud", "--validate-only", "--log-output=stdout", "test.js"}
+		ts.Env["K6_CLOUD_HOST"] = srv.URL
+		ts.Env["K6_CLOUD_TOKEN"] = "foo"
+		ts.ExpectedExitCode = int(exitcodes.InvalidConfig)
+		cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+		stdout := ts.Stdout.String()
+		t.Log(stdout)
+		assert.Contains(t, stdout, "4 problem(s) found")
+		assert.Contains(t, stdout, "scenarios.ext.executor: the externally-controlled executor can")
+		assert.Contains(t, stdout, "ext.loadimpact.distribution.b.loadZone: the load zone is required")
+		assert.Contains(t, stdout, "ext.loadimpact.distribution: the percentages add up to 90 instead of 100")
+		assert.Contains(t, stdout, "options.vus: exceeds the limit of 100 VUs")
+	})
+}
+
+func TestCloudProgressFormatJSON(t *testing.T) {
+	t.Parallel()
+
+	var progressCalls int32
+	cs := func() cloudapi.TestProgressResponse {
+		if atomic.AddInt32(&progressCalls, 1) == 1 {
+			return cloudapi.TestProgressResponse{
+				RunStatusText: "Running",
+				RunStatus:     cloudapi.RunStatusRunning,
+				Progress:      0.5,
+			}
+		}
+		return cloudapi.TestProgressResponse{
+			RunStatusText: "Finished",
+			RunStatus:     cloudapi.RunStatusFinished,
+			ResultStatus:  cloudapi.ResultStatusFailed,
+			Progress:      1,
+		}
+	}
+
+	// the elapsed time is the one of the test run, as returned by the cloud
+	started := time.Now().Add(-time.Minute)
+	srv := getTestServer(t, map[string]http.Handler{
+		"POST ^/v1/archive-upload$": cloudTestStartSimple(t, 123),
+		"GET ^/v1/test-progress/123$": http.HandlerFunc(func(resp http.ResponseWriter, _ *http.Request) {
+			assert.NoError(t, json.NewEncoder(resp).Encode(cs()))
+		}),
+		"GET ^/v1/tests/123$": http.HandlerFunc(func(resp http.ResponseWriter, _ *http.Request) {
+			testRun := map[string]interface{}{"reference_id": "123", "started": started}
+			if atomic.LoadInt32(&progressCalls) > 1 {
+				testRun["ended"] = started.Add(90 * time.Second)
+			}
+			assert.NoError(t, json.NewEncoder(resp).Encode(testRun))
+		}),
+	})
+	t.Cleanup(srv.Close)
+
+	ts := getCloudTestState(t, srv, []byte(`export default function() {}`), []string{"--progress-format=json"})
+	ts.ExpectedExitCode = int(exitcodes.CloudTestRunFailed)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+
+	type event struct {
+		Type          string             `json:"type"`
+		RefID         string             `json:"ref_id"`
+		URL           string             `json:"url"`
+		RunStatus     cloudapi.RunStatus `json:"run_status"`
+		ResultStatus  int                `json:"result_status"`
+		Progress      float64            `json:"progress"`
+		Elapsed       *float64           `json:"elapsed_seconds"`
+		ExitCode      *int               `json:"exit_code"`
+		Error         string             `json:"error"`
+		RunStatusText string             `json:"run_status_text"`
+	}
+	var events []event
+	dec := json.NewDecoder(strings.NewReader(stdout))
+	for dec.More() {
+		var e event
+		require.NoError(t, dec.Decode(&e))
+		events = append(events, e)
+	}
+	require.Len(t, events, 3)
+
+	assert.Equal(t, "progress", events[0].Type)
+	assert.Equal(t, "123", events[0].RefID)
+	assert.Equal(t, "https://app.k6.io/runs/123", events[0].URL)
+	assert.Equal(t, cloudapi.RunStatusRunning, events[0].RunStatus)
+	assert.Equal(t, 0.5, events[0].Progress)
+	require.NotNil(t, events[0].Elapsed)
+	assert.GreaterOrEqual(t, *events[0].Elapsed, 60.0)
+	assert.Less(t, *events[0].Elapsed, 90.0)
+	assert.Nil(t, events[0].ExitCode)
+
+	assert.Equal(t, "progress", events[1].Type)
+	assert.Equal(t, cloudapi.RunStatusFinished, events[1].RunStatus)
+
+	assert.Equal(t, "summary", events[2].Type)
+	assert.Equal(t, cloudapi.RunStatusFinished, events[2].RunStatus)
+	require.NotNil(t, events[2].Elapsed)
+	assert.InDelta(t, 90.0, *events[2].Elapsed, 0.001)
+	assert.Equal(t, int(cloudapi.ResultStatusFailed), events[2].ResultStatus)
+	require.NotNil(t, events[2].ExitCode)
+	assert.Equal(t, int(exitcodes.CloudTestRunFailed), *events[2].ExitCode)
+	assert.Equal(t, "The test has failed", events[2].Error)
+}
+
+func TestCloudProgressFormatInvalid(t *testing.T) {
+	t.Parallel()
+
+	ts := getSimpleCloudTestState(t, nil, []string{"--progress-format=xml", "--log-output=stdout"}, nil, nil)
+	ts.ExpectedExitCode = -1
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Contains(t, ts.Stdout.String(), `invalid progress format \"xml\"`)
+}
+
+func TestCloudLogs(t *testing.T) {
+	t.Parallel()
+
+	upgrader := websocket.Upgrader{}
+	srv := getTestServer(t, map[string]http.Handler{
+		"GET ^/v1/tests/123$": http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			_, err := fmt.Fprint(resp, `{"reference_id": "123", "created": "2020-08-24T15:25:50Z"}`)
+			assert.NoError(t, err)
+		}),
+		"GET ^/api/v1/tail": http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, "1598282750000000000", req.URL.Query().Get("start"))
+			conn, err := upgrader.Upgrade(resp, req, nil)
+			require.NoError(t, err)
+			defer func() { _ = conn.Close() }()
+
+			err = conn.WriteMessage(websocket.TextMessage, []byte(`{"streams": [
+				{"stream": {"level": "info", "scenario": "foo"}, "values": [["1598282752000000000", "first"]]},
+				{"stream": {"level": "error", "scenario": "foo"}, "values": [["1598282753000000000", "second"]]},
+				{"stream": {"level": "error", "scenario": "bar"}, "values": [["1598282754000000000", "third"]]}
+			]}`))
+			require.NoError(t, err)
+			_, _, _ = conn.ReadMessage()
+		}),
+	})
+	t.Cleanup(srv.Close)
+
+	ts := NewGlobalTestState(t)
+	ts.CmdArgs = []string{"k6", "cloud", "logs", "123"}
+	ts.Env["K6_CLOUD_HOST"] = srv.URL
+	ts.Env["K6_CLOUD_LOGS_TAIL_URL"] = "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/v1/tail"
+	ts.Env["K6_CLOUD_TOKEN"] = "foo"
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Equal(t, ""+
+		"2020-08-24T15:25:52Z [info] first scenario=\"foo\"\n"+
+		"2020-08-24T15:25:53Z [error] second scenario=\"foo\"\n"+
+		"2020-08-24T15:25:54Z [error] third scenario=\"bar\"\n", stdout)
+
+	ts = NewGlobalTestState(t)
+	ts.CmdArgs = []string{"k6", "cloud", "logs", "--level", "error", "-l", `scenario="foo"`, "-o", "logs.ndjson", "123"}
+	ts.Env["K6_CLOUD_HOST"] = srv.URL
+	ts.Env["K6_CLOUD_LOGS_TAIL_URL"] = "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/v1/tail"
+	ts.Env["K6_CLOUD_TOKEN"] = "foo"
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Empty(t, ts.Stdout.String())
+
+	data, err := fsext.ReadFile(ts.FS, "logs.ndjson")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 1)
+	var entry cloudapi.TestRunLogEntry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "second", entry.Message)
+	assert.Equal(t, "error", entry.Level)
+	assert.Equal(t, map[string]string{"scenario": "foo"}, entry.Labels)
+}
+
+func TestCloudReplayMetrics(t *testing.T) {
+	t.Parallel()
+
+	var pushes int
+	srv := getTestServer(t, map[string]http.Handler{
+		"POST ^/v2/metrics/123$": http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, "application/x-protobuf", req.Header.Get("Content-Type"))
+			pushes++
+		}),
+	})
+	t.Cleanup(srv.Close)
+
+	ts := NewGlobalTestState(t)
+	for i, name := range []string{"123-1-000001.pbspool", "123-2-000002.pbspool"} {
+		b, err := proto.Marshal(&pbcloud.MetricSet{TestRunId: "123", AggregationPeriod: uint32(i + 1)})
+		require.NoError(t, err)
+		data := binary.BigEndian.AppendUint32(nil, uint32(len(b)))
+		require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join("spool", name), append(data, b...), 0o644))
+	}
+
+	ts.CmdArgs = []string{"k6", "cloud", "replay-metrics", "spool", "--ref-id", "123"}
+	ts.Env["K6_CLOUD_HOST"] = srv.URL
+	ts.Env["K6_CLOUD_TOKEN"] = "foo"
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	assert.Equal(t, 2, pushes)
+	assert.Contains(t, ts.Stdout.String(), "Pushed 2 spooled batches of metrics to the cloud test run 123")
+	entries, err := fsext.ReadDir(ts.FS, "spool")
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the replayed files should be removed")
+
+	ts.Stdout.Reset()
+	ts.CmdArgs = []string{"k6", "cloud", "replay-metrics", "spool", "--ref-id", "123", "--log-output=stdout"}
+	ts.ExpectedExitCode = -1
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Contains(t, ts.Stdout.String(), "there are no spooled metrics for the test run 123")
+}
+
+func TestCloudOutputToMetricsDir(t *testing.T) {
+	t.Parallel()
+
+	script := `
+		import { Trend } from 'k6/metrics';
+		const myTrend = new Trend('my_trend');
+		export const options = { iterations: 10 };
+		export default function () { myTrend.add(__ITER + 1); }
+	`
+	ts := getSingleFileTestState(t, script, []string{"-o", "cloud", "--quiet"}, 0)
+	ts.Env["K6_CLOUD_METRICS_DIR"] = "metrics"
+	ts.Env["K6_CLOUD_AGGREGATION_PERIOD"] = "1s"
+	// there is no cloud, so any request would fail
+	ts.Env["K6_CLOUD_HOST"] = "http://127.0.0.1:1"
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	entries, err := fsext.ReadDir(ts.FS, "metrics")
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+
+	ts.Stdout.Reset()
+	ts.CmdArgs = []string{"k6", "cloud", "decode-metrics", "metrics"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	var decoded []struct {
+		File      string `json:"file"`
+		TestRunID string `json:"test_run_id"`
+		Metrics   []struct {
+			Name       string `json:"name"`
+			Type       string `json:"type"`
+			TimeSeries []struct {
+				Samples []struct {
+					Count       int                `json:"count"`
+					Percentiles map[string]float64 `json:"percentiles"`
+				} `json:"samples"`
+			} `json:"time_series"`
+		} `json:"metrics"`
+	}
+	require.NoError(t, json.Unmarshal(ts.Stdout.Bytes(), &decoded))
+	require.Len(t, decoded, len(entries))
+
+	var trendCount int
+	for _, file := range decoded {
+		assert.Equal(t, "offline", file.TestRunID)
+		for _, m := range file.Metrics {
+			if m.Name != "my_trend" {
+				continue
+			}
+			assert.Equal(t, "trend", m.Type)
+			for _, series := range m.TimeSeries {
+				for _, sample := range series.Samples {
+					trendCount += sample.Count
+					assert.Contains(t, sample.Percentiles, "p(95)")
+				}
+			}
+		}
+	}
+	assert.Equal(t, 10, trendCount)
+}
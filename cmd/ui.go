@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -380,3 +381,12 @@ func yamlPrint(w io.Writer, v interface{}) error {
 	}
 	return nil
 }
+
+func jsonPrint(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("could not marshal JSON: %w", err)
+	}
+	return nil
+}
@@ -53,4 +53,16 @@ const (
 
 	// GoPanic indicates the script was aborted by a panic in the Go runtime.
 	GoPanic ExitCode = 109
+
+	// CloudTestRunAbortedBySystem indicates that the cloud test run was
+	// aborted by the cloud service, e.g. because of an infrastructure issue.
+	CloudTestRunAbortedBySystem ExitCode = 110
+
+	// CloudTestRunAbortedByLimit indicates that the cloud test run was
+	// aborted because it exceeded a limit of the cloud subscription.
+	CloudTestRunAbortedByLimit ExitCode = 111
+
+	// CloudTestRunTimedOut indicates that the cloud test run did not finish
+	// in the time expected by the cloud service.
+	CloudTestRunTimedOut ExitCode = 112
 )
@@ -27,6 +27,13 @@ type Scheduler struct {
 	maxDuration     time.Duration // cached value derived from the execution plan
 	maxPossibleVUs  uint64        // cached value derived from the execution plan
 	state           *lib.ExecutionState
+
+	// executorCancels has the functions for stopping the executors which are
+	// waiting for their start time or running, by their names, and
+	// stoppedExecutors has the names of the ones stopped with StopExecutor.
+	executorCancels   map[string]context.CancelFunc
+	stoppedExecutors  map[string]struct{}
+	executorCancelsMx sync.Mutex
 }
 
 // NewScheduler creates and returns a new Scheduler instance, without
@@ -74,13 +81,15 @@ func NewScheduler(trs *lib.TestRunState) (*Scheduler, error) {
 	}
 
 	return &Scheduler{
-		initProgress:    pb.New(pb.WithConstLeft("Init")),
-		executors:       executors,
-		executorConfigs: executorConfigs,
-		executionPlan:   executionPlan,
-		maxDuration:     maxDuration,
-		maxPossibleVUs:  maxPossibleVUs,
-		state:           executionState,
+		initProgress:     pb.New(pb.WithConstLeft("Init")),
+		executors:        executors,
+		executorConfigs:  executorConfigs,
+		executionPlan:    executionPlan,
+		maxDuration:      maxDuration,
+		maxPossibleVUs:   maxPossibleVUs,
+		state:            executionState,
+		executorCancels:  make(map[string]context.CancelFunc),
+		stoppedExecutors: make(map[string]struct{}),
 	}, nil
 }
 
@@ -330,6 +339,18 @@ func (e *Scheduler) runExecutor(
 ) {
 	executorConfig := executor.GetConfig()
 	executorStartTime := executorConfig.GetStartTime()
+
+	runCtx, cancel := context.WithCancel(runCtx)
+	e.executorCancelsMx.Lock()
+	e.executorCancels[executorConfig.GetName()] = cancel
+	e.executorCancelsMx.Unlock()
+	defer func() {
+		e.executorCancelsMx.Lock()
+		delete(e.executorCancels, executorConfig.GetName())
+		e.executorCancelsMx.Unlock()
+		cancel()
+	}()
+
 	executorLogger := e.state.Test.Logger.WithFields(logrus.Fields{
 		"executor":  executorConfig.GetName(),
 		"type":      executorConfig.GetType(),
@@ -351,6 +372,9 @@ func (e *Scheduler) runExecutor(
 		executorLogger.Debugf("Waiting for executor start time...")
 		select {
 		case <-runCtx.Done():
+			if e.IsExecutorStopped(executorConfig.GetName()) {
+				executorProgress.Modify(pb.WithStatus(pb.Interrupted), pb.WithConstProgress(0, "stopped"))
+			}
 			runResults <- nil // no error since executor hasn't started yet
 			return
 		case <-time.After(executorStartTime):
@@ -372,6 +396,33 @@ func (e *Scheduler) runExecutor(
 	runResults <- err
 }
 
+// StopExecutor stops the executor with the given name before its end, without
+// stopping the rest of the test. Its iterations are interrupted, like when the
+// whole test is stopped, and it doesn't start if it's still waiting for its
+// start time.
+func (e *Scheduler) StopExecutor(name string) error {
+	e.executorCancelsMx.Lock()
+	defer e.executorCancelsMx.Unlock()
+
+	cancel, ok := e.executorCancels[name]
+	if !ok {
+		return fmt.Errorf("the scenario %q isn't running", name)
+	}
+	e.stoppedExecutors[name] = struct{}{}
+	cancel()
+	return nil
+}
+
+// IsExecutorStopped returns whether the executor with the given name has been
+// stopped with StopExecutor.
+func (e *Scheduler) IsExecutorStopped(name string) bool {
+	e.executorCancelsMx.Lock()
+	defer e.executorCancelsMx.Unlock()
+
+	_, ok := e.stoppedExecutors[name]
+	return ok
+}
+
 // Init concurrently initializes all of the planned VUs and then sequentially
 // initializes all of the configured executors. It also starts the measurement
 // and emission of the `vus` and `vus_max` metrics.
@@ -2,6 +2,7 @@ package execution_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -1024,6 +1025,46 @@ func TestSchedulerIsRunning(t *testing.T) {
 	assert.NoError(t, <-err)
 }
 
+func TestSchedulerStopExecutor(t *testing.T) {
+	t.Parallel()
+	runner := &minirunner.MiniRunner{
+		Fn: func(ctx context.Context, _ *lib.State, out chan<- metrics.SampleContainer) error {
+			<-ctx.Done()
+			return nil
+		},
+	}
+	scenarios := lib.ScenarioConfigs{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"long": {"executor": "constant-vus", "vus": 1, "duration": "1h"},
+		"short": {"executor": "constant-vus", "vus": 1, "duration": "1s", "gracefulStop": "0s"}
+	}`), &scenarios))
+	ctx, cancel, execScheduler, _ := newTestScheduler(t, runner, nil, lib.Options{Scenarios: scenarios})
+	defer cancel()
+	state := execScheduler.GetState()
+
+	require.ErrorContains(t, execScheduler.StopExecutor("long"), `the scenario "long" isn't running`)
+
+	err := make(chan error)
+	go func() { err <- execScheduler.Run(ctx, ctx, nil) }()
+	for !state.HasStarted() {
+		time.Sleep(10 * time.Microsecond)
+	}
+	assert.False(t, execScheduler.IsExecutorStopped("long"))
+	require.Eventually(t, func() bool {
+		return execScheduler.StopExecutor("long") == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, execScheduler.IsExecutorStopped("long"))
+	assert.False(t, execScheduler.IsExecutorStopped("short"))
+
+	select {
+	case runErr := <-err:
+		assert.NoError(t, runErr)
+	case <-time.After(10 * time.Second):
+		t.Fatal("the test run didn't end after stopping the long scenario")
+	}
+	assert.Error(t, execScheduler.StopExecutor("long"))
+}
+
 // TestDNSResolverCache checks the DNS resolution behavior at the Scheduler level.
 func TestDNSResolverCache(t *testing.T) {
 	t.Parallel()
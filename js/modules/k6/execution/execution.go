@@ -174,6 +174,23 @@ func (mi *ModuleInstance) newTestInfo() (*goja.Object, error) {
 				rt.Interrupt(&errext.InterruptError{Reason: reason})
 			}
 		},
+		// mark a moment of the test run, for all the outputs and the summary
+		"annotate": func() interface{} {
+			return func(text string, tags map[string]string) {
+				state := mi.vu.State()
+				if state == nil {
+					common.Throw(rt, errors.New("annotating the test run in the init context is not supported"))
+				}
+				if text == "" {
+					common.Throw(rt, errors.New("the annotation text can't be empty"))
+				}
+				metrics.PushIfNotDone(mi.vu.Context(), state.Samples, metrics.Annotation{
+					Time: time.Now(),
+					Text: text,
+					Tags: tags,
+				})
+			}
+		},
 		"options": func() interface{} {
 			if optionsObject == nil {
 				opts, err := optionsAsObject(rt, mi.vu.State().Options)
@@ -501,3 +501,49 @@ func TestTagsDynamicObjectSet(t *testing.T) {
 	require.NotNil(t, val)
 	assert.Equal(t, val.String(), "v1")
 }
+
+func TestAnnotateTest(t *testing.T) {
+	t.Parallel()
+
+	samples := make(chan metrics.SampleContainer, 10)
+	rt := goja.New()
+	m, ok := New().NewModuleInstance(
+		&modulestest.VU{
+			RuntimeField: rt,
+			CtxField:     context.Background(),
+			StateField:   &lib.State{Samples: samples},
+		},
+	).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, rt.Set("exec", m.Exports().Default))
+
+	_, err := rt.RunString(`exec.test.annotate("DB failover started", {team: "db"})`)
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	annotation, ok := (<-samples).(metrics.Annotation)
+	require.True(t, ok)
+	assert.Equal(t, "DB failover started", annotation.Text)
+	assert.Equal(t, map[string]string{"team": "db"}, annotation.Tags)
+	assert.WithinDuration(t, time.Now(), annotation.Time, time.Minute)
+
+	_, err = rt.RunString(`exec.test.annotate("")`)
+	require.ErrorContains(t, err, "the annotation text can't be empty")
+}
+
+func TestAnnotateTestInitContext(t *testing.T) {
+	t.Parallel()
+
+	rt := goja.New()
+	m, ok := New().NewModuleInstance(
+		&modulestest.VU{
+			RuntimeField: rt,
+			InitEnvField: &common.InitEnvironment{},
+			CtxField:     context.Background(),
+		},
+	).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, rt.Set("exec", m.Exports().Default))
+
+	_, err := rt.RunString(`exec.test.annotate("nope")`)
+	require.ErrorContains(t, err, "annotating the test run in the init context is not supported")
+}
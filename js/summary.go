@@ -51,6 +51,10 @@ func metricValueGetter(summaryTrendStats []string) func(metrics.Sink, time.Durat
 			for _, col := range summaryTrendStats {
 				result[col] = trendResolvers[col](sink)
 			}
+		default:
+			// e.g. sinks with values that were already aggregated elsewhere,
+			// like the results of cloud test runs
+			result = sink.Format(t)
 		}
 
 		return result
@@ -97,6 +101,17 @@ func summarizeMetricsToObject(data *lib.Summary, options lib.Options, setupData
 	}
 	m["metrics"] = metricsData
 
+	// the annotations are only added if there are any, for keeping the
+	// summary data of the test runs without them as it was before
+	if len(data.Annotations) > 0 {
+		m["annotations"] = exportAnnotations(data.Annotations)
+	}
+
+	// the same goes for the changes of the thresholds made during the test
+	if len(data.ThresholdsChanges) > 0 {
+		m["thresholds_changes"] = exportThresholdsChanges(data.ThresholdsChanges)
+	}
+
 	var setupDataI interface{}
 	if setupData != nil {
 		if err := json.Unmarshal(setupData, &setupDataI); err != nil {
@@ -112,6 +127,51 @@ func summarizeMetricsToObject(data *lib.Summary, options lib.Options, setupData
 	return m
 }
 
+func exportAnnotations(annotations []metrics.Annotation) []map[string]interface{} {
+	exported := make([]map[string]interface{}, 0, len(annotations))
+	for _, a := range annotations {
+		tags := make(map[string]interface{}, len(a.Tags))
+		for k, v := range a.Tags {
+			tags[k] = v
+		}
+		exported = append(exported, map[string]interface{}{
+			"time": a.Time.Format(time.RFC3339Nano),
+			"text": a.Text,
+			"tags": tags,
+		})
+	}
+	return exported
+}
+
+func exportThresholdsChanges(changes []metrics.ThresholdsChange) []map[string]interface{} {
+	exportThresholds := func(ts metrics.Thresholds) []map[string]interface{} {
+		exported := make([]map[string]interface{}, 0, len(ts.Thresholds))
+		for _, t := range ts.Thresholds {
+			var delayAbortEval interface{}
+			if t.AbortGracePeriod.Valid {
+				delayAbortEval = t.AbortGracePeriod.String()
+			}
+			exported = append(exported, map[string]interface{}{
+				"threshold":      t.Source,
+				"abortOnFail":    t.AbortOnFail,
+				"delayAbortEval": delayAbortEval,
+			})
+		}
+		return exported
+	}
+
+	exported := make([]map[string]interface{}, 0, len(changes))
+	for _, c := range changes {
+		exported = append(exported, map[string]interface{}{
+			"time":     c.Time.Format(time.RFC3339Nano),
+			"metric":   c.Metric,
+			"previous": exportThresholds(c.Previous),
+			"current":  exportThresholds(c.Current),
+		})
+	}
+	return exported
+}
+
 func exportGroup(group *lib.Group) map[string]interface{} {
 	subGroups := make([]map[string]interface{}, len(group.OrderedGroups))
 	for i, subGroup := range group.OrderedGroups {
@@ -382,6 +382,36 @@ function summarizeMetrics(options, data, decorate) {
   return result
 }
 
+function summarizeThresholds(thresholds) {
+  if (thresholds.length == 0) {
+    return 'none'
+  }
+  return thresholds
+    .map(function (threshold) {
+      return threshold.abortOnFail ? threshold.threshold + ' (abortOnFail)' : threshold.threshold
+    })
+    .join(', ')
+}
+
+function summarizeThresholdsChanges(indent, changes, decorate) {
+  var result = ['', indent + decorate('thresholds changed during the test:', palette.faint)]
+  for (var i = 0; i < changes.length; i++) {
+    var change = changes[i]
+    result.push(
+      indent +
+      '  ' +
+      change.time +
+      ' ' +
+      change.metric +
+      ': ' +
+      summarizeThresholds(change.previous) +
+      ' → ' +
+      summarizeThresholds(change.current)
+    )
+  }
+  return result
+}
+
 function generateTextSummary(data, options) {
   var mergedOpts = Object.assign({}, defaultOptions, data.options, options)
   var lines = []
@@ -407,6 +437,13 @@ function generateTextSummary(data, options) {
 
   Array.prototype.push.apply(lines, summarizeMetrics(mergedOpts, data, decorate))
 
+  if (data.thresholds_changes) {
+    Array.prototype.push.apply(
+      lines,
+      summarizeThresholdsChanges(mergedOpts.indent + ' ', data.thresholds_changes, decorate)
+    )
+  }
+
   return lines.join('\n')
 }
 
@@ -15,6 +15,7 @@ import (
 
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 )
 
@@ -619,6 +620,93 @@ func TestRawHandleSummaryDataWithSetupData(t *testing.T) {
 	assert.JSONEq(t, expectedHandleSummaryDataWithSetup, string(dataWithSetup))
 }
 
+func TestRawHandleSummaryDataWithAnnotations(t *testing.T) {
+	t.Parallel()
+	runner, err := getSimpleRunner(
+		t, "/script.js",
+		`
+		exports.default = function() { /* we don't run this, metrics are mocked */ };
+		exports.handleSummary = function(data) {
+			return {'annotations.json': JSON.stringify(data.annotations)};
+		};
+		`,
+	)
+	require.NoError(t, err)
+
+	summary := createTestSummary(t)
+	summary.Annotations = []metrics.Annotation{
+		{Time: time.Date(2023, 10, 1, 10, 0, 0, 0, time.UTC), Text: "DB failover started", Tags: map[string]string{"team": "db"}},
+		{Time: time.Date(2023, 10, 1, 10, 5, 0, 0, time.UTC), Text: "DB failover ended"},
+	}
+	result, err := runner.HandleSummary(context.Background(), summary)
+	require.NoError(t, err)
+	annotations, err := io.ReadAll(result["annotations.json"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"time": "2023-10-01T10:00:00Z", "text": "DB failover started", "tags": {"team": "db"}},
+		{"time": "2023-10-01T10:05:00Z", "text": "DB failover ended", "tags": {}}
+	]`, string(annotations))
+}
+
+func TestRawHandleSummaryDataWithThresholdsChanges(t *testing.T) {
+	t.Parallel()
+	runner, err := getSimpleRunner(
+		t, "/script.js",
+		`
+		exports.default = function() { /* we don't run this, metrics are mocked */ };
+		exports.handleSummary = function(data) {
+			return {'changes.json': JSON.stringify(data.thresholds_changes)};
+		};
+		`,
+	)
+	require.NoError(t, err)
+
+	summary := createTestSummary(t)
+	summary.ThresholdsChanges = createTestThresholdsChanges()
+	result, err := runner.HandleSummary(context.Background(), summary)
+	require.NoError(t, err)
+	changes, err := io.ReadAll(result["changes.json"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{
+		"time": "2023-10-01T10:00:00Z",
+		"metric": "my_trend",
+		"previous": [{"threshold": "p(95)<10", "abortOnFail": false, "delayAbortEval": null}],
+		"current": [{"threshold": "p(95)<20", "abortOnFail": true, "delayAbortEval": "10s"}]
+	}]`, string(changes))
+}
+
+func TestTextSummaryWithThresholdsChanges(t *testing.T) {
+	t.Parallel()
+	runner, err := getSimpleRunner(
+		t, "/script.js",
+		`exports.default = function() {/* we don't run this, metrics are mocked */};`,
+		lib.RuntimeOptions{CompatibilityMode: null.NewString("base", true)},
+	)
+	require.NoError(t, err)
+
+	summary := createTestSummary(t)
+	summary.ThresholdsChanges = createTestThresholdsChanges()
+	result, err := runner.HandleSummary(context.Background(), summary)
+	require.NoError(t, err)
+	summaryOut, err := io.ReadAll(result["stdout"])
+	require.NoError(t, err)
+	assert.Contains(t, string(summaryOut), "\n\n  thresholds changed during the test:\n"+
+		"    2023-10-01T10:00:00Z my_trend: p(95)<10 → p(95)<20 (abortOnFail)\n")
+}
+
+func createTestThresholdsChanges() []metrics.ThresholdsChange {
+	previous := metrics.NewThresholds([]string{"p(95)<10"})
+	current := metrics.NewThresholds([]string{"p(95)<20"})
+	current.Thresholds[0].AbortOnFail = true
+	current.Thresholds[0].AbortGracePeriod = types.NullDurationFrom(10 * time.Second)
+	return []metrics.ThresholdsChange{{
+		Time:     time.Date(2023, 10, 1, 10, 0, 0, 0, time.UTC),
+		Metric:   "my_trend",
+		Previous: previous,
+		Current:  current,
+	}}
+}
+
 func TestRawHandleSummaryPromise(t *testing.T) {
 	t.Parallel()
 	runner, err := getSimpleRunner(
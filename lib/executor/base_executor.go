@@ -4,6 +4,7 @@ import (
 	"context"
 	"strconv"
 	"sync"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
 
@@ -23,6 +24,7 @@ type BaseExecutor struct {
 	iterSegIndex   *lib.SegmentedIndex
 	logger         *logrus.Entry
 	progress       *pb.ProgressBar
+	activeVUs      *int64
 }
 
 // NewBaseExecutor returns an initialized BaseExecutor
@@ -34,6 +36,7 @@ func NewBaseExecutor(config lib.ExecutorConfig, es *lib.ExecutionState, logger *
 		logger:         logger,
 		iterSegIndexMx: new(sync.Mutex),
 		iterSegIndex:   segIdx,
+		activeVUs:      new(int64),
 		progress: pb.New(
 			pb.WithLeft(config.GetName),
 			pb.WithLogger(logger),
@@ -70,6 +73,33 @@ func (bs *BaseExecutor) GetProgress() *pb.ProgressBar {
 	return bs.progress
 }
 
+// GetActiveVUsCount returns the number of VUs currently activated by the
+// executor, including the ones waiting to run an iteration.
+//
+// IMPORTANT: for UI/information purposes only, don't use for synchronization.
+func (bs *BaseExecutor) GetActiveVUsCount() int64 {
+	return atomic.LoadInt64(bs.activeVUs)
+}
+
+// modActiveVUsCount changes the number of VUs currently activated by the executor.
+func (bs *BaseExecutor) modActiveVUsCount(mod int64) {
+	atomic.AddInt64(bs.activeVUs, mod)
+}
+
+// trackActiveVU counts the VU activated with the params as active for the
+// executor, until it's deactivated.
+func (bs *BaseExecutor) trackActiveVU(params *lib.VUActivationParams) *lib.VUActivationParams {
+	bs.modActiveVUsCount(+1)
+	deactivateCallback := params.DeactivateCallback
+	params.DeactivateCallback = func(vu lib.InitializedVU) {
+		bs.modActiveVUsCount(-1)
+		if deactivateCallback != nil {
+			deactivateCallback(vu)
+		}
+	}
+	return params
+}
+
 // getMetricTags returns a tag set that can be used to emit metrics by the
 // executor. The VU ID is optional.
 func (bs *BaseExecutor) getMetricTags(vuID *uint64) *metrics.TagSet {
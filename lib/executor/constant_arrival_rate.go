@@ -2,9 +2,11 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/big"
+	"reflect"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -147,9 +149,12 @@ func (carc ConstantArrivalRateConfig) GetExecutionRequirements(et *lib.Execution
 func (carc ConstantArrivalRateConfig) NewExecutor(
 	es *lib.ExecutionState, logger *logrus.Entry,
 ) (lib.Executor, error) {
+	rate := carc.Rate.Int64
 	return &ConstantArrivalRate{
 		BaseExecutor: NewBaseExecutor(&carc, es, logger),
 		config:       carc,
+		rate:         &rate,
+		rateUpdates:  make(chan struct{}, 1),
 	}, nil
 }
 
@@ -164,10 +169,61 @@ type ConstantArrivalRate struct {
 	*BaseExecutor
 	config ConstantArrivalRateConfig
 	et     *lib.ExecutionTuple
+
+	// rate is the current rate, which can be changed with UpdateConfig while
+	// the executor is running, and rateUpdates notifies Run of the changes.
+	rate        *int64
+	rateUpdates chan struct{}
+}
+
+// Make sure we implement the lib.Executor and lib.LiveUpdatableExecutor interfaces.
+var (
+	_ lib.Executor              = &ConstantArrivalRate{}
+	_ lib.LiveUpdatableExecutor = &ConstantArrivalRate{}
+)
+
+// GetCurrentConfig returns the executor's current configuration, with the
+// rate that it's currently using.
+func (car *ConstantArrivalRate) GetCurrentConfig() ConstantArrivalRateConfig {
+	config := car.config
+	config.Rate = null.NewInt(atomic.LoadInt64(car.rate), config.Rate.Valid)
+	return config
 }
 
-// Make sure we implement the lib.Executor interface.
-var _ lib.Executor = &ConstantArrivalRate{}
+// GetConfig just returns the executor's current configuration, it's basically
+// an alias of GetCurrentConfig that implements the more generic interface.
+func (car *ConstantArrivalRate) GetConfig() lib.ExecutorConfig {
+	config := car.GetCurrentConfig()
+	return &config
+}
+
+// UpdateConfig validates the supplied config and updates the rate in real
+// time. Only the rate can be changed, the iterations that are already due are
+// started as planned and the following ones are rescheduled for the new rate.
+func (car *ConstantArrivalRate) UpdateConfig(_ context.Context, newConf interface{}) error {
+	newConfig, ok := newConf.(ConstantArrivalRateConfig)
+	if !ok {
+		return errors.New("invalid config type")
+	}
+	if errs := newConfig.Validate(); len(errs) != 0 {
+		return fmt.Errorf("invalid configuration supplied: %s", lib.ConcatErrors(errs, ", "))
+	}
+
+	newRate := newConfig.Rate.Int64
+	currentConfig := car.GetCurrentConfig()
+	newConfig.Rate = currentConfig.Rate
+	if !reflect.DeepEqual(newConfig, currentConfig) {
+		return errors.New("only the rate of the constant arrival rate executor can be changed")
+	}
+
+	atomic.StoreInt64(car.rate, newRate)
+	select {
+	case car.rateUpdates <- struct{}{}:
+	default: // Run hasn't handled the previous update yet, it will read the new rate too
+	}
+	car.logger.WithField("rate", newRate).Debug("The rate of the executor was updated")
+	return nil
+}
 
 // Init values needed for the execution
 func (car *ConstantArrivalRate) Init(ctx context.Context) error {
@@ -198,7 +254,8 @@ func (car ConstantArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 	preAllocatedVUs := car.config.GetPreAllocatedVUs(car.executionState.ExecutionTuple)
 	maxVUs := car.config.GetMaxVUs(car.executionState.ExecutionTuple)
 	// TODO: refactor and simplify
-	arrivalRate := getScaledArrivalRate(car.et.Segment, car.config.Rate.Int64, car.config.TimeUnit.TimeDuration())
+	rate := atomic.LoadInt64(car.rate)
+	arrivalRate := getScaledArrivalRate(car.et.Segment, rate, car.config.TimeUnit.TimeDuration())
 	tickerPeriod := getTickerPeriod(arrivalRate).TimeDuration()
 	arrivalRatePerSec, _ := getArrivalRatePerSec(arrivalRate).Float64()
 
@@ -231,15 +288,19 @@ func (car ConstantArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 	activeVUsCount := uint64(0)
 
 	vusFmt := pb.GetFixedLengthIntFormat(maxVUs)
-	progIters := fmt.Sprintf(
-		pb.GetFixedLengthFloatFormat(arrivalRatePerSec, 2)+" iters/s", arrivalRatePerSec)
+	progIters := &atomic.Value{}
+	setProgIters := func(arrivalRatePerSec float64) {
+		progIters.Store(fmt.Sprintf(
+			pb.GetFixedLengthFloatFormat(arrivalRatePerSec, 2)+" iters/s", arrivalRatePerSec))
+	}
+	setProgIters(arrivalRatePerSec)
 	progressFn := func() (float64, []string) {
 		spent := time.Since(startTime)
 		currActiveVUs := atomic.LoadUint64(&activeVUsCount)
 		progVUs := fmt.Sprintf(vusFmt+"/"+vusFmt+" VUs",
 			vusPool.Running(), currActiveVUs)
 
-		right := []string{progVUs, duration.String(), progIters}
+		right := []string{progVUs, duration.String(), progIters.Load().(string)} //nolint:forcetypeassert
 
 		if spent > duration {
 			return 1, right
@@ -276,10 +337,10 @@ func (car ConstantArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 	runIterationBasic := getIterationRunner(car.executionState, car.logger)
 	activateVU := func(initVU lib.InitializedVU) lib.ActiveVU {
 		activeVUsWg.Add(1)
-		activeVU := initVU.Activate(getVUActivationParams(
+		activeVU := initVU.Activate(car.trackActiveVU(getVUActivationParams(
 			maxDurationCtx, car.config.BaseConfig, returnVU,
 			car.nextIterationCounters,
-		))
+		)))
 		atomic.AddUint64(&activeVUsCount, 1)
 		vusPool.AddVU(maxDurationCtx, activeVU, runIterationBasic)
 		return activeVU
@@ -317,18 +378,50 @@ func (car ConstantArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 	// here the we need the not scaled one
 	notScaledTickerPeriod := getTickerPeriod(
 		big.NewRat(
-			car.config.Rate.Int64,
+			rate,
 			int64(car.config.TimeUnit.TimeDuration()),
 		)).TimeDuration()
 
 	droppedIterationMetric := car.executionState.Test.BuiltinMetrics.DroppedIterations
 	shownWarning := false
 	metricTags := car.getMetricTags(nil)
-	for li, gi := 0, start; ; li, gi = li+1, gi+offsets[li%len(offsets)] {
-		t := notScaledTickerPeriod*time.Duration(gi) - time.Since(startTime)
+	// The iteration gi is started at periodStart + (gi - periodBase) periods,
+	// they are moved forward when the rate is changed.
+	periodStart, periodBase := startTime, int64(0)
+	li, gi := 0, start
+	for {
+		t := notScaledTickerPeriod*time.Duration(gi-periodBase) - time.Since(periodStart)
 		timer.Reset(t)
 		select {
+		case <-car.rateUpdates:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			rate = atomic.LoadInt64(car.rate)
+			newTickerPeriod := getTickerPeriod(
+				big.NewRat(rate, int64(car.config.TimeUnit.TimeDuration()))).TimeDuration()
+
+			// The wait for the next iteration is scaled to the new rate, so
+			// the change is applied without a burst or a gap.
+			now := time.Now()
+			remaining := notScaledTickerPeriod*time.Duration(gi-periodBase) - now.Sub(periodStart)
+			if remaining < 0 {
+				remaining = 0
+			}
+			periodStart = now.Add(time.Duration(
+				float64(remaining) * float64(newTickerPeriod) / float64(notScaledTickerPeriod)))
+			periodBase = gi
+			notScaledTickerPeriod = newTickerPeriod
+
+			arrivalRate = getScaledArrivalRate(car.et.Segment, rate, car.config.TimeUnit.TimeDuration())
+			arrivalRatePerSec, _ = getArrivalRatePerSec(arrivalRate).Float64()
+			setProgIters(arrivalRatePerSec)
+			car.logger.WithFields(logrus.Fields{
+				"rate": rate, "tickerPeriod": getTickerPeriod(arrivalRate).TimeDuration(),
+			}).Debug("Rescheduled the iterations for the new rate")
+
 		case <-timer.C:
+			li, gi = li+1, gi+offsets[li%len(offsets)]
 			if vusPool.TryRunIteration() {
 				continue
 			}
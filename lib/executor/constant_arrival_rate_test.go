@@ -393,3 +393,67 @@ func TestConstantArrivalRateActiveVUs(t *testing.T) {
 	assert.GreaterOrEqual(t, running, int64(5))
 	assert.LessOrEqual(t, running, int64(10))
 }
+
+func TestConstantArrivalRateUpdateRate(t *testing.T) {
+	t.Parallel()
+
+	var count int64
+	runner := simpleRunner(func(ctx context.Context, _ *lib.State) error {
+		atomic.AddInt64(&count, 1)
+		return nil
+	})
+
+	config := getTestConstantArrivalRateConfig()
+	config.Name, config.Type = "test", constantArrivalRateType
+	config.Duration = types.NullDurationFrom(3 * time.Second)
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+	defer test.cancel()
+
+	car, ok := test.executor.(*ConstantArrivalRate)
+	require.True(t, ok)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		time.Sleep(time.Second)
+		assert.InDelta(t, 50, atomic.SwapInt64(&count, 0), 5)
+
+		newConfig := car.GetCurrentConfig()
+		newConfig.Rate = null.IntFrom(100)
+		assert.NoError(t, car.UpdateConfig(test.ctx, newConfig))
+		assert.Equal(t, int64(100), car.GetCurrentConfig().Rate.Int64)
+
+		time.Sleep(time.Second)
+		assert.InDelta(t, 100, atomic.SwapInt64(&count, 0), 10)
+	}()
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	require.NoError(t, test.executor.Run(test.ctx, engineOut))
+	wg.Wait()
+	require.Empty(t, test.logHook.Drain())
+}
+
+func TestConstantArrivalRateUpdateConfigInvalid(t *testing.T) {
+	t.Parallel()
+
+	runner := simpleRunner(func(ctx context.Context, _ *lib.State) error { return nil })
+	config := getTestConstantArrivalRateConfig()
+	config.Name, config.Type = "test", constantArrivalRateType
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+	defer test.cancel()
+
+	car, ok := test.executor.(*ConstantArrivalRate)
+	require.True(t, ok)
+
+	newConfig := car.GetCurrentConfig()
+	newConfig.Rate = null.IntFrom(0)
+	assert.ErrorContains(t, car.UpdateConfig(test.ctx, newConfig), "invalid configuration supplied")
+
+	newConfig = car.GetCurrentConfig()
+	newConfig.Rate = null.IntFrom(100)
+	newConfig.MaxVUs = null.IntFrom(30)
+	assert.ErrorContains(t, car.UpdateConfig(test.ctx, newConfig), "only the rate")
+
+	assert.Error(t, car.UpdateConfig(test.ctx, RampingArrivalRateConfig{}))
+	assert.Equal(t, int64(50), car.GetCurrentConfig().Rate.Int64)
+}
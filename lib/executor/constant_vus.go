@@ -179,9 +179,9 @@ func (clv ConstantVUs) Run(parentCtx context.Context, out chan<- metrics.SampleC
 		ctx, cancel := context.WithCancel(maxDurationCtx)
 		defer cancel()
 
-		activeVU := initVU.Activate(
+		activeVU := initVU.Activate(clv.trackActiveVU(
 			getVUActivationParams(ctx, clv.config.BaseConfig, returnVU,
-				clv.nextIterationCounters))
+				clv.nextIterationCounters)))
 
 		for {
 			select {
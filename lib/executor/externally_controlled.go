@@ -332,11 +332,13 @@ func (rs *externallyControlledRunState) newManualVUHandle(
 		wg.Add(1)
 		state.ModCurrentlyActiveVUsCount(+1)
 		atomic.AddInt64(rs.activeVUsCount, +1)
+		rs.executor.modActiveVUsCount(+1)
 		return initVU, nil
 	}
 	returnVU := func(_ lib.InitializedVU) {
 		state.ModCurrentlyActiveVUsCount(-1)
 		atomic.AddInt64(rs.activeVUsCount, -1)
+		rs.executor.modActiveVUsCount(-1)
 		wg.Done()
 	}
 	ctx, cancel := context.WithCancel(rs.ctx)
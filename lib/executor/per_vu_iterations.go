@@ -206,9 +206,9 @@ func (pvi PerVUIterations) Run(parentCtx context.Context, out chan<- metrics.Sam
 		defer cancel()
 
 		vuID := initVU.GetID()
-		activeVU := initVU.Activate(
+		activeVU := initVU.Activate(pvi.trackActiveVU(
 			getVUActivationParams(ctx, pvi.config.BaseConfig, returnVU,
-				pvi.nextIterationCounters))
+				pvi.nextIterationCounters)))
 
 		for i := int64(0); i < iterations; i++ {
 			select {
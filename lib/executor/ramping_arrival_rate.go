@@ -397,10 +397,10 @@ func (varr RampingArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 
 	activateVU := func(initVU lib.InitializedVU) lib.ActiveVU {
 		activeVUsWg.Add(1)
-		activeVU := initVU.Activate(
+		activeVU := initVU.Activate(varr.trackActiveVU(
 			getVUActivationParams(
 				maxDurationCtx, varr.config.BaseConfig, returnVU,
-				varr.nextIterationCounters))
+				varr.nextIterationCounters)))
 		atomic.AddUint64(&activeVUsCount, 1)
 
 		vusPool.AddVU(maxDurationCtx, activeVU, runIterationBasic)
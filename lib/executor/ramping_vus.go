@@ -596,12 +596,14 @@ func (rs *rampingVUsRunState) runLoopsIfPossible(ctx context.Context, cancel fun
 		}
 		rs.wg.Add(1)
 		atomic.AddInt64(rs.activeVUsCount, 1)
+		rs.executor.modActiveVUsCount(+1)
 		rs.executor.executionState.ModCurrentlyActiveVUsCount(+1)
 		return pvu, err
 	}
 	returnVU := func(initVU lib.InitializedVU) {
 		rs.executor.executionState.ReturnVU(initVU, false)
 		atomic.AddInt64(rs.activeVUsCount, -1)
+		rs.executor.modActiveVUsCount(-1)
 		rs.wg.Done()
 		rs.executor.executionState.ModCurrentlyActiveVUsCount(-1)
 	}
@@ -240,8 +240,8 @@ func (si SharedIterations) Run(parentCtx context.Context, out chan<- metrics.Sam
 		ctx, cancel := context.WithCancel(maxDurationCtx)
 		defer cancel()
 
-		activeVU := initVU.Activate(getVUActivationParams(
-			ctx, si.config.BaseConfig, returnVU, si.nextIterationCounters))
+		activeVU := initVU.Activate(si.trackActiveVU(getVUActivationParams(
+			ctx, si.config.BaseConfig, returnVU, si.nextIterationCounters)))
 
 		for {
 			select {
@@ -98,9 +98,11 @@ type UIState struct {
 
 // Summary contains all of the data the summary handler gets.
 type Summary struct {
-	Metrics         map[string]*metrics.Metric
-	RootGroup       *Group
-	TestRunDuration time.Duration // TODO: use lib.ExecutionState-based interface instead?
-	NoColor         bool          // TODO: drop this when noColor is part of the (runtime) options
-	UIState         UIState
+	Metrics           map[string]*metrics.Metric
+	Annotations       []metrics.Annotation
+	ThresholdsChanges []metrics.ThresholdsChange
+	RootGroup         *Group
+	TestRunDuration   time.Duration // TODO: use lib.ExecutionState-based interface instead?
+	NoColor           bool          // TODO: drop this when noColor is part of the (runtime) options
+	UIState           UIState
 }
@@ -0,0 +1,55 @@
+package testutils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// fakeCredentialHelper is a k6 cloud credential helper that keeps a single
+// token in a file next to it, regardless of the server URL. Every action it
+// executes is appended to a calls file next to it too.
+const fakeCredentialHelper = `#!/bin/sh
+dir="$(dirname "$0")"
+store="$dir/token"
+echo "$1" >> "$dir/calls"
+case "$1" in
+get)
+	url=$(cat)
+	if [ ! -f "$store" ]; then
+		echo "credentials not found in native keychain"
+		exit 1
+	fi
+	printf '{"ServerURL":"%s","Secret":"%s"}' "$url" "$(cat "$store")"
+	;;
+store)
+	sed -n 's/.*"Secret":"\([^"]*\)".*/\1/p' > "$store"
+	;;
+erase)
+	rm "$store" 2>/dev/null || { echo "credentials not found in native keychain"; exit 1; }
+	;;
+*)
+	echo "unknown action $1" >&2
+	exit 2
+	;;
+esac
+`
+
+// WriteFakeCredentialHelper writes a fake k6 cloud credential helper in a new
+// temporary directory and returns its path. The token it stores is in the
+// token file and the actions it executed, one per line, in the calls file of
+// the same directory. The test is skipped on Windows, since the helper is a
+// shell script.
+func WriteFakeCredentialHelper(t testing.TB) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake credential helper is a shell script")
+	}
+
+	path := filepath.Join(t.TempDir(), "k6-credential-fake")
+	if err := os.WriteFile(path, []byte(fakeCredentialHelper), 0o700); err != nil { //nolint:forbidigo,gosec
+		t.Fatalf("failed to write the fake credential helper: %s", err)
+	}
+	return path
+}
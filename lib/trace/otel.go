@@ -166,6 +166,18 @@ func TracerProviderFromConfigLine(ctx context.Context, line string) (*TracerProv
 	return NewTracerProvider(ctx, params)
 }
 
+// ClientFromConfigLine initializes a new OTLP exporter client, without a
+// TracerProvider, based on the configuration specified through input line.
+// The format is the same of TracerProviderFromConfigLine.
+func ClientFromConfigLine(line string) (otlptrace.Client, error) {
+	params, err := tracerProviderParamsFromConfigLine(line)
+	if err != nil {
+		return nil, err
+	}
+
+	return newClient(params)
+}
+
 func tracerProviderParamsFromConfigLine(line string) (tracerProviderParams, error) {
 	params := defaultTracerProviderParams()
 
@@ -38,6 +38,12 @@ type MetricsEngine struct {
 	//     the metrics are decoupled from their types
 	MetricsLock     sync.Mutex
 	ObservedMetrics map[string]*metrics.Metric
+	// Annotations are the annotations of the test run, in the order they
+	// were received, for the end-of-test summary.
+	Annotations []metrics.Annotation
+	// ThresholdsChanges are the changes of the thresholds made while the
+	// test was running, in the order they were made.
+	ThresholdsChanges []metrics.ThresholdsChange
 }
 
 // NewMetricsEngine creates a new metrics Engine with the given parameters.
@@ -156,6 +162,103 @@ func (me *MetricsEngine) InitSubMetricsAndThresholds(options lib.Options, onlyLo
 	return nil
 }
 
+// SetThresholds replaces the thresholds of all the metrics while the test is
+// running, the metrics which aren't in the given ones lose their thresholds.
+// The thresholds of the new sub-metrics only consider the samples received
+// after they are set. The changes are recorded in ThresholdsChanges and
+// returned. If any of the thresholds is invalid, nothing is changed.
+func (me *MetricsEngine) SetThresholds(thresholds map[string]metrics.Thresholds) ([]metrics.ThresholdsChange, error) {
+	for metricName, ths := range thresholds {
+		ths := ths
+		if err := ths.Parse(); err != nil {
+			return nil, fmt.Errorf("invalid threshold on metric '%s': %w", metricName, err)
+		}
+		if err := ths.Validate(metricName, me.registry); err != nil {
+			return nil, err
+		}
+	}
+
+	me.MetricsLock.Lock()
+	defer me.MetricsLock.Unlock()
+
+	updated := make(map[*metrics.Metric]metrics.Thresholds, len(thresholds))
+	for metricName, ths := range thresholds {
+		metric, err := me.getThresholdMetricOrSubmetric(metricName)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric '%s' in threshold definitions: %w", metricName, err)
+		}
+		updated[metric] = ths
+	}
+	for _, metric := range me.metricsWithThresholds {
+		if _, ok := updated[metric]; !ok {
+			updated[metric] = metrics.Thresholds{}
+		}
+	}
+
+	now := time.Now()
+	var changes []metrics.ThresholdsChange
+	for metric, ths := range updated {
+		if equalThresholds(metric.Thresholds, ths) {
+			continue
+		}
+		changes = append(changes, metrics.ThresholdsChange{
+			Time:     now,
+			Metric:   metric.Name,
+			Previous: metric.Thresholds,
+			Current:  ths,
+		})
+
+		metric.Thresholds = ths
+		if len(ths.Thresholds) == 0 {
+			// it isn't evaluated anymore, so it can't be breached
+			metric.Tainted = null.Bool{}
+		}
+
+		me.markObserved(metric)
+		if metric.Sub != nil {
+			me.markObserved(metric.Sub.Parent)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Metric < changes[j].Metric })
+
+	me.metricsWithThresholds = make([]*metrics.Metric, 0, len(updated))
+	for metric := range updated {
+		if len(metric.Thresholds.Thresholds) > 0 {
+			me.metricsWithThresholds = append(me.metricsWithThresholds, metric)
+		}
+	}
+
+	for _, change := range changes {
+		me.logger.WithField("metric_name", change.Metric).Infof(
+			"The thresholds have been changed from %s to %s", formatThresholds(change.Previous), formatThresholds(change.Current),
+		)
+	}
+	me.ThresholdsChanges = append(me.ThresholdsChanges, changes...)
+
+	return changes, nil
+}
+
+func equalThresholds(a, b metrics.Thresholds) bool {
+	if len(a.Thresholds) != len(b.Thresholds) {
+		return false
+	}
+	for i, t := range a.Thresholds {
+		o := b.Thresholds[i]
+		if t.Source != o.Source || t.AbortOnFail != o.AbortOnFail || t.AbortGracePeriod != o.AbortGracePeriod {
+			return false
+		}
+	}
+	return true
+}
+
+func formatThresholds(ts metrics.Thresholds) string {
+	data, err := ts.MarshalJSON()
+	if err != nil {
+		return fmt.Sprintf("%v", ts.Thresholds)
+	}
+	return strings.TrimSpace(string(data))
+}
+
 // StartThresholdCalculations spins up a new goroutine to crunch thresholds and
 // returns a callback that will stop the goroutine and finalizes calculations.
 func (me *MetricsEngine) StartThresholdCalculations(
@@ -163,10 +266,8 @@ func (me *MetricsEngine) StartThresholdCalculations(
 	abortRun func(error),
 	getCurrentTestRunDuration func() time.Duration,
 ) (finalize func() (breached []string)) {
-	if len(me.metricsWithThresholds) == 0 {
-		return nil // no thresholds were defined
-	}
-
+	// the calculations are started even if no thresholds were defined,
+	// since they can be added with SetThresholds while the test is running
 	stop := make(chan struct{})
 	done := make(chan struct{})
 
@@ -207,6 +308,12 @@ func (me *MetricsEngine) StartThresholdCalculations(
 		close(stop)
 		<-done
 
+		me.MetricsLock.Lock()
+		hasThresholds := len(me.metricsWithThresholds) > 0
+		me.MetricsLock.Unlock()
+		if hasThresholds {
+			me.logger.Debug("Finalizing thresholds...")
+		}
 		breached, _ := me.evaluateThresholds(false, getCurrentTestRunDuration)
 		return breached
 	}
@@ -222,6 +329,12 @@ func (me *MetricsEngine) evaluateThresholds(
 	me.MetricsLock.Lock()
 	defer me.MetricsLock.Unlock()
 
+	if len(me.metricsWithThresholds) == 0 {
+		// none were defined or all of them were removed with SetThresholds
+		atomic.StoreUint32(&me.breachedThresholdsCount, 0)
+		return nil, false
+	}
+
 	t := getCurrentTestRunDuration()
 
 	me.logger.Debugf("Running thresholds on %d metrics...", len(me.metricsWithThresholds))
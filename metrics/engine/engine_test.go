@@ -153,6 +153,85 @@ func TestMetricsEngineEvaluateIgnoreEmptySink(t *testing.T) {
 	assert.Empty(t, breached)
 }
 
+func TestMetricsEngineSetThresholds(t *testing.T) {
+	t.Parallel()
+
+	me := newTestMetricsEngine(t)
+	m1, err := me.registry.NewMetric("m1", metrics.Counter)
+	require.NoError(t, err)
+	m2, err := me.registry.NewMetric("m2", metrics.Trend)
+	require.NoError(t, err)
+
+	ths := metrics.NewThresholds([]string{"count<5"})
+	require.NoError(t, ths.Parse())
+	require.NoError(t, me.InitSubMetricsAndThresholds(lib.Options{
+		Thresholds: map[string]metrics.Thresholds{"m1": ths},
+	}, false))
+	m1.Sink.Add(metrics.Sample{Value: 6.0})
+	breached, _ := me.evaluateThresholds(false, zeroTestRunDuration)
+	require.Equal(t, []string{"m1"}, breached)
+
+	// relax the threshold of m1 and add an aborting one on a sub-metric of m2
+	abortingThresholds := metrics.NewThresholds([]string{"max<100"})
+	abortingThresholds.Thresholds[0].AbortOnFail = true
+	changes, err := me.SetThresholds(map[string]metrics.Thresholds{
+		"m1":          metrics.NewThresholds([]string{"count<10"}),
+		"m2{tag:val}": abortingThresholds,
+	})
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "m1", changes[0].Metric)
+	assert.Equal(t, "count<5", changes[0].Previous.Thresholds[0].Source)
+	assert.Equal(t, "count<10", changes[0].Current.Thresholds[0].Source)
+	assert.Equal(t, "m2{tag:val}", changes[1].Metric)
+	assert.Empty(t, changes[1].Previous.Thresholds)
+	assert.Equal(t, changes, me.ThresholdsChanges)
+	assert.True(t, m2.Observed)
+
+	require.Len(t, m2.Submetrics, 1)
+	m2.Submetrics[0].Metric.Sink.Add(metrics.Sample{Value: 200})
+	breached, abort := me.evaluateThresholds(false, zeroTestRunDuration)
+	assert.Equal(t, []string{"m2{tag:val}"}, breached)
+	assert.True(t, abort)
+	assert.False(t, m1.Tainted.Bool)
+
+	// the unchanged thresholds aren't recorded and the missing ones are removed
+	changes, err = me.SetThresholds(map[string]metrics.Thresholds{
+		"m1": metrics.NewThresholds([]string{"count<10"}),
+	})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "m2{tag:val}", changes[0].Metric)
+	assert.Empty(t, changes[0].Current.Thresholds)
+	assert.Len(t, me.ThresholdsChanges, 3)
+	assert.False(t, m2.Submetrics[0].Metric.Tainted.Valid)
+	breached, abort = me.evaluateThresholds(false, zeroTestRunDuration)
+	assert.Empty(t, breached)
+	assert.False(t, abort)
+}
+
+func TestMetricsEngineSetThresholdsInvalid(t *testing.T) {
+	t.Parallel()
+
+	me := newTestMetricsEngine(t)
+	m1, err := me.registry.NewMetric("m1", metrics.Counter)
+	require.NoError(t, err)
+	require.NoError(t, me.InitSubMetricsAndThresholds(lib.Options{
+		Thresholds: map[string]metrics.Thresholds{"m1": metrics.NewThresholds([]string{"count<5"})},
+	}, false))
+
+	for _, thresholds := range []map[string]metrics.Thresholds{
+		{"m1": metrics.NewThresholds([]string{"count<"})},
+		{"m1": metrics.NewThresholds([]string{"p(95)<5"})},
+		{"nope": metrics.NewThresholds([]string{"count<5"})},
+	} {
+		_, err = me.SetThresholds(thresholds)
+		assert.Error(t, err)
+	}
+	assert.Empty(t, me.ThresholdsChanges)
+	assert.Equal(t, "count<5", m1.Thresholds.Thresholds[0].Source)
+}
+
 func newTestMetricsEngine(t *testing.T) *MetricsEngine {
 	m, err := NewMetricsEngine(metrics.NewRegistry(), testutils.NewLogger(t))
 	require.NoError(t, err)
@@ -78,6 +78,11 @@ func (oi *OutputIngester) flushMetrics() {
 	// and eliminate the map loopkups altogether!
 
 	for _, sampleContainer := range sampleContainers {
+		if annotation, ok := sampleContainer.(metrics.Annotation); ok {
+			oi.metricsEngine.Annotations = append(oi.metricsEngine.Annotations, annotation)
+			continue
+		}
+
 		samples := sampleContainer.GetSamples()
 
 		if len(samples) == 0 {
@@ -3,6 +3,7 @@ package engine
 import (
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -98,6 +99,34 @@ func TestIngesterOutputFlushSubmetrics(t *testing.T) {
 	assert.IsType(t, &metrics.GaugeSink{}, metric.Sink)
 }
 
+func TestIngesterOutputFlushAnnotations(t *testing.T) {
+	t.Parallel()
+
+	piState := newTestPreInitState(t)
+	testMetric, err := piState.Registry.NewMetric("test_metric", metrics.Counter)
+	require.NoError(t, err)
+
+	ingester := OutputIngester{
+		logger: piState.Logger,
+		metricsEngine: &MetricsEngine{
+			ObservedMetrics: make(map[string]*metrics.Metric),
+		},
+		cardinality: newCardinalityControl(),
+	}
+	require.NoError(t, ingester.Start())
+	first := metrics.Annotation{Time: time.Unix(1, 0), Text: "first"}
+	second := metrics.Annotation{Time: time.Unix(2, 0), Text: "second", Tags: map[string]string{"a": "b"}}
+	ingester.AddMetricSamples([]metrics.SampleContainer{
+		first,
+		metrics.Sample{TimeSeries: metrics.TimeSeries{Metric: testMetric}, Value: 1},
+	})
+	ingester.AddMetricSamples([]metrics.SampleContainer{second})
+	require.NoError(t, ingester.Stop())
+
+	assert.Equal(t, []metrics.Annotation{first, second}, ingester.metricsEngine.Annotations)
+	assert.Len(t, ingester.metricsEngine.ObservedMetrics, 1)
+}
+
 func TestOutputFlushMetricsTimeSeriesWarning(t *testing.T) {
 	t.Parallel()
 
@@ -81,6 +81,22 @@ func (cs ConnectedSamples) GetTime() time.Time {
 	return cs.Time
 }
 
+// Annotation marks a moment of the test run, e.g. when something happened in
+// the system under test. It's a SampleContainer without any samples, so it's
+// received by all the outputs, and the ones which don't support annotations
+// just ignore it.
+type Annotation struct {
+	Time time.Time         `json:"time"`
+	Text string            `json:"text"`
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// GetSamples implements the SampleContainer interface, an annotation doesn't
+// have any samples.
+func (a Annotation) GetSamples() []Sample {
+	return nil
+}
+
 // GetSamples implement the ConnectedSampleContainer interface
 // for a single Sample, since it's obviously connected with itself :)
 func (s Sample) GetSamples() []Sample {
@@ -120,6 +120,15 @@ type Thresholds struct {
 	sinked     map[string]float64
 }
 
+// ThresholdsChange is a change of the thresholds of a metric made while the
+// test was running, it's kept for reporting it in the end-of-test summary.
+type ThresholdsChange struct {
+	Time     time.Time
+	Metric   string
+	Previous Thresholds
+	Current  Thresholds
+}
+
 // NewThresholds returns Thresholds objects representing the provided source strings
 func NewThresholds(sources []string) Thresholds {
 	tcs := make([]thresholdConfig, len(sources))
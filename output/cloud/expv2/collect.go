@@ -54,6 +54,9 @@ type collector struct {
 	aggregationPeriod time.Duration
 	waitPeriod        time.Duration
 
+	// labels drops the tags that shouldn't be sent to the cloud, if it's set
+	labels *labelsFilter
+
 	// we should no longer have to handle metrics that have times long in the past. So instead of a
 	// map, we can probably use a simple slice (or even an array!) as a ring buffer to store the
 	// aggregation buckets. This should save us a some time, since it would make the lookups and WaitPeriod
@@ -107,6 +110,10 @@ func (c *collector) DropExpiringDelay() {
 }
 
 func (c *collector) collectSample(s metrics.Sample) {
+	if c.labels != nil {
+		s.TimeSeries = c.labels.apply(s.TimeSeries)
+	}
+
 	bucketID := c.bucketID(s.Time)
 
 	// Get or create a time bucket
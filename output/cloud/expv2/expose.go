@@ -0,0 +1,391 @@
+package expv2
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	// exposedMetricPrefix is the prefix of the names of the exposed metrics,
+	// the same used by the Prometheus remote write output.
+	exposedMetricPrefix = "k6_"
+
+	// nativeHistogramSchema is the schema of the native histograms exposed
+	// for the trends. It has 128 buckets for every power of two, as many as
+	// the histogram has above 256 times its minimum resolution.
+	nativeHistogramSchema = 7
+)
+
+// exposedSeries holds the values of a time series accumulated since the
+// beginning of the test.
+type exposedSeries struct {
+	// time is the time of the most recent bucket, the value of a gauge is
+	// the last one from it.
+	time int64
+
+	// value is the sum of a counter, or the last value of a gauge.
+	value float64
+
+	// nonZero and total are the counts of a rate.
+	nonZero uint64
+	total   uint64
+
+	// hist is the histogram of a trend.
+	hist *cumulativeHistogram
+}
+
+// cumulativeHistogram is the sum of the histograms of a trend. Its counts are
+// uint64, unlike the ones of the histograms that are flushed, so they don't
+// wrap around in long tests.
+type cumulativeHistogram struct {
+	MinimumResolution float64
+	Buckets           map[uint32]uint64
+	ExtraLowBucket    uint64
+	ExtraHighBucket   uint64
+	Max               float64
+	Min               float64
+	Sum               float64
+	Count             uint64
+}
+
+func newCumulativeHistogram(minimumResolution float64) *cumulativeHistogram {
+	return &cumulativeHistogram{
+		MinimumResolution: minimumResolution,
+		Buckets:           make(map[uint32]uint64),
+		Max:               -math.MaxFloat64,
+		Min:               math.MaxFloat64,
+	}
+}
+
+// add adds the values counted by the histogram, it must have the same
+// minimum resolution.
+func (h *cumulativeHistogram) add(other *histogram) {
+	for index, count := range other.Buckets {
+		h.Buckets[index] += uint64(count)
+	}
+	h.ExtraLowBucket += uint64(other.ExtraLowBucket)
+	h.ExtraHighBucket += uint64(other.ExtraHighBucket)
+	if other.Max > h.Max {
+		h.Max = other.Max
+	}
+	if other.Min < h.Min {
+		h.Min = other.Min
+	}
+	h.Sum += other.Sum
+	h.Count += uint64(other.Count)
+}
+
+// metricsExposer keeps the values of the aggregated time series, accumulated
+// from all the flushed buckets, and serves them for being scraped by
+// Prometheus in the OpenMetrics, the Prometheus text, or the Protobuf format.
+type metricsExposer struct {
+	logger logrus.FieldLogger
+
+	mu     sync.Mutex
+	series map[metrics.TimeSeries]*exposedSeries
+}
+
+func newMetricsExposer(logger logrus.FieldLogger) *metricsExposer {
+	return &metricsExposer{
+		logger: logger,
+		series: make(map[metrics.TimeSeries]*exposedSeries),
+	}
+}
+
+// add accumulates the values of the buckets.
+func (e *metricsExposer) add(buckets []timeBucket) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, bucket := range buckets {
+		for timeSeries, sink := range bucket.Sinks {
+			series, ok := e.series[timeSeries]
+			if !ok {
+				series = &exposedSeries{}
+				e.series[timeSeries] = series
+			}
+
+			switch value := sink.(type) {
+			case *counter:
+				series.value += value.Sum
+			case *gauge:
+				if bucket.Time >= series.time {
+					series.value = value.Last
+				}
+			case *rate:
+				series.nonZero += uint64(value.NonZeroCount)
+				series.total += uint64(value.Total)
+			case *histogram:
+				if series.hist == nil {
+					series.hist = newCumulativeHistogram(value.MinimumResolution)
+				}
+				series.hist.add(value)
+			}
+			if bucket.Time > series.time {
+				series.time = bucket.Time
+			}
+		}
+	}
+}
+
+// gather returns the current values as Prometheus metric families, sorted by
+// their name.
+func (e *metricsExposer) gather() []*dto.MetricFamily {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	families := make(map[string]*dto.MetricFamily)
+	for timeSeries, series := range e.series {
+		m := &dto.Metric{Label: mapTimeSeriesLabelsPrometheus(timeSeries.Tags)}
+
+		name := exposedMetricPrefix + sanitizePrometheusName(timeSeries.Metric.Name)
+		var metricType dto.MetricType
+		switch timeSeries.Metric.Type {
+		case metrics.Counter:
+			name += "_total"
+			metricType = dto.MetricType_COUNTER
+			m.Counter = &dto.Counter{Value: proto.Float64(series.value)}
+		case metrics.Gauge:
+			metricType = dto.MetricType_GAUGE
+			m.Gauge = &dto.Gauge{Value: proto.Float64(series.value)}
+		case metrics.Rate:
+			name += "_rate"
+			metricType = dto.MetricType_GAUGE
+			var value float64
+			if series.total > 0 {
+				value = float64(series.nonZero) / float64(series.total)
+			}
+			m.Gauge = &dto.Gauge{Value: proto.Float64(value)}
+		case metrics.Trend:
+			metricType = dto.MetricType_HISTOGRAM
+			m.Histogram = histogramAsPrometheus(series.hist)
+		default:
+			continue
+		}
+
+		family, ok := families[name]
+		if !ok {
+			family = &dto.MetricFamily{Name: proto.String(name), Type: metricType.Enum()}
+			families[name] = family
+		}
+		family.Metric = append(family.Metric, m)
+	}
+
+	sorted := make([]*dto.MetricFamily, 0, len(families))
+	for _, family := range families {
+		sort.Slice(family.Metric, func(i, j int) bool {
+			return lessPrometheusLabels(family.Metric[i].Label, family.Metric[j].Label)
+		})
+		sorted = append(sorted, family)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].GetName() < sorted[j].GetName()
+	})
+	return sorted
+}
+
+// ServeHTTP implements http.Handler. It uses the format requested by the
+// Accept header, which is OpenMetrics if there isn't one.
+func (e *metricsExposer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	format := expfmt.FmtOpenMetrics
+	if r.Header.Get("Accept") != "" {
+		format = expfmt.NegotiateIncludingOpenMetrics(r.Header)
+	}
+	w.Header().Set("Content-Type", string(format))
+
+	enc := expfmt.NewEncoder(w, format)
+	for _, family := range e.gather() {
+		if err := enc.Encode(family); err != nil {
+			e.logger.WithError(err).Debug("Failed to encode the exposed metrics")
+			return
+		}
+	}
+	if closer, ok := enc.(expfmt.Closer); ok {
+		if err := closer.Close(); err != nil {
+			e.logger.WithError(err).Debug("Failed to encode the exposed metrics")
+		}
+	}
+}
+
+// serve starts serving the metrics on the /metrics path of the address, until
+// the returned server is closed.
+func (e *metricsExposer) serve(addr string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s for exposing the metrics: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			e.logger.WithError(err).Error("Failed to serve the exposed metrics")
+		}
+	}()
+
+	e.logger.Infof("Exposing the aggregated metrics on http://%s/metrics", listener.Addr())
+	return srv, nil
+}
+
+func mapTimeSeriesLabelsPrometheus(tags *metrics.TagSet) []*dto.LabelPair {
+	tagsMap := tags.Map()
+	labels := make([]*dto.LabelPair, 0, len(tagsMap))
+	for key, value := range tagsMap {
+		if isReservedLabelName(key) {
+			continue
+		}
+		labels = append(labels, &dto.LabelPair{
+			Name:  proto.String(sanitizePrometheusName(key)),
+			Value: proto.String(value),
+		})
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].GetName() < labels[j].GetName()
+	})
+	return labels
+}
+
+func lessPrometheusLabels(a, b []*dto.LabelPair) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i].GetName() != b[i].GetName() {
+			return a[i].GetName() < b[i].GetName()
+		}
+		if a[i].GetValue() != b[i].GetValue() {
+			return a[i].GetValue() < b[i].GetValue()
+		}
+	}
+	return len(a) < len(b)
+}
+
+// sanitizePrometheusName replaces the characters that aren't allowed in the
+// names of the Prometheus metrics and labels with underscores.
+func sanitizePrometheusName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		b[i] = '_'
+	}
+	return string(b)
+}
+
+// histogramAsPrometheus converts the histogram into a Prometheus native
+// histogram. The values of every bucket are counted in the native bucket of
+// its upper bound, the untrackable ones in the native buckets of the minimum
+// and the maximum. It has classic buckets too, at the powers of two, for the
+// formats that don't support the native histograms.
+func histogramAsPrometheus(h *cumulativeHistogram) *dto.Histogram {
+	var (
+		zeroCount uint64
+		positive  = make(map[int32]uint64)
+		negative  = make(map[int32]uint64)
+		classic   = make(map[float64]uint64)
+	)
+	add := func(v float64, count uint64) {
+		switch {
+		case v > 0:
+			positive[nativeBucketIndex(v)] += count
+			classic[math.Exp2(math.Ceil(math.Log2(v)))] += count
+		case v < 0:
+			negative[nativeBucketIndex(-v)] += count
+			classic[v] += count
+		default:
+			zeroCount += count
+			classic[0] += count
+		}
+	}
+
+	for index, count := range h.Buckets {
+		add(math.Min(bucketUpperBound(index)*h.MinimumResolution, h.Max), count)
+	}
+	if h.ExtraLowBucket > 0 {
+		add(h.Min, h.ExtraLowBucket)
+	}
+	if h.ExtraHighBucket > 0 {
+		add(h.Max, h.ExtraHighBucket)
+	}
+
+	phist := &dto.Histogram{
+		SampleCount: proto.Uint64(h.Count),
+		SampleSum:   proto.Float64(h.Sum),
+		Schema:      proto.Int32(nativeHistogramSchema),
+		ZeroCount:   proto.Uint64(zeroCount),
+	}
+	phist.PositiveSpan, phist.PositiveDelta = nativeHistogramBuckets(positive)
+	phist.NegativeSpan, phist.NegativeDelta = nativeHistogramBuckets(negative)
+
+	bounds := make([]float64, 0, len(classic))
+	for bound := range classic {
+		bounds = append(bounds, bound)
+	}
+	sort.Float64s(bounds)
+	var cumulative uint64
+	for _, bound := range bounds {
+		cumulative += classic[bound]
+		phist.Bucket = append(phist.Bucket, &dto.Bucket{
+			UpperBound:      proto.Float64(bound),
+			CumulativeCount: proto.Uint64(cumulative),
+		})
+	}
+	return phist
+}
+
+// nativeBucketIndex returns the index of the native histogram bucket of the
+// positive value, the bucket with the index i counts the values in the range
+// (2^((i-1)/2^schema), 2^(i/2^schema)].
+func nativeBucketIndex(v float64) int32 {
+	return int32(math.Ceil(math.Log2(v) * (1 << nativeHistogramSchema)))
+}
+
+// nativeHistogramBuckets encodes the counts of the native histogram buckets
+// by their index as spans of consecutive buckets, and the deltas between the
+// count of every bucket and the previous one.
+func nativeHistogramBuckets(counts map[int32]uint64) ([]*dto.BucketSpan, []int64) {
+	if len(counts) == 0 {
+		return nil, nil
+	}
+
+	indexes := make([]int32, 0, len(counts))
+	for index := range counts {
+		indexes = append(indexes, index)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+
+	var (
+		spans    []*dto.BucketSpan
+		deltas   = make([]int64, 0, len(indexes))
+		previous int64
+	)
+	for i, index := range indexes {
+		switch {
+		case i == 0:
+			spans = append(spans, &dto.BucketSpan{Offset: proto.Int32(index), Length: proto.Uint32(1)})
+		case index == indexes[i-1]+1:
+			*spans[len(spans)-1].Length++
+		default:
+			spans = append(spans, &dto.BucketSpan{
+				Offset: proto.Int32(index - indexes[i-1] - 1),
+				Length: proto.Uint32(1),
+			})
+		}
+		count := int64(counts[index])
+		deltas = append(deltas, count-previous)
+		previous = count
+	}
+	return spans, deltas
+}
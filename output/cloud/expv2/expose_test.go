@@ -0,0 +1,176 @@
+package expv2
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/metrics"
+)
+
+func TestMetricsExposerServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	r := metrics.NewRegistry()
+	tags := r.RootTagSet().WithTagsFromMap(map[string]string{
+		"name": "home", "expected-response": "true", "test_run_id": "123",
+	})
+	reqs := metrics.TimeSeries{Metric: r.MustNewMetric("http_reqs", metrics.Counter), Tags: tags}
+	vus := metrics.TimeSeries{Metric: r.MustNewMetric("vus", metrics.Gauge), Tags: tags}
+	checks := metrics.TimeSeries{Metric: r.MustNewMetric("checks", metrics.Rate), Tags: tags}
+	duration := metrics.TimeSeries{Metric: r.MustNewMetric("http_req_duration", metrics.Trend), Tags: tags}
+
+	bucket := func(time int64, values map[metrics.TimeSeries][]float64) timeBucket {
+		tb := timeBucket{Time: time, Sinks: make(map[metrics.TimeSeries]metricValue)}
+		for ts, vs := range values {
+			sink := newMetricValue(ts.Metric.Type)
+			for _, v := range vs {
+				sink.Add(v)
+			}
+			tb.Sinks[ts] = sink
+		}
+		return tb
+	}
+
+	e := newMetricsExposer(testutils.NewLogger(t))
+	// the buckets aren't in order, the gauge has the value of the most recent one
+	e.add([]timeBucket{
+		bucket(2, map[metrics.TimeSeries][]float64{reqs: {1, 1}, vus: {5}, checks: {1, 0}, duration: {1}}),
+		bucket(1, map[metrics.TimeSeries][]float64{reqs: {1}, vus: {3}, checks: {1}, duration: {0}}),
+	})
+	e.add([]timeBucket{
+		bucket(3, map[metrics.TimeSeries][]float64{duration: {2}}),
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, string(expfmt.FmtOpenMetrics), rec.Header().Get("Content-Type"))
+	assert.Equal(t, `# TYPE k6_checks_rate gauge
+k6_checks_rate{expected_response="true",name="home"} 0.6666666666666666
+# TYPE k6_http_req_duration histogram
+k6_http_req_duration_bucket{expected_response="true",name="home",le="0.0"} 1
+k6_http_req_duration_bucket{expected_response="true",name="home",le="2.0"} 3
+k6_http_req_duration_bucket{expected_response="true",name="home",le="+Inf"} 3
+k6_http_req_duration_sum{expected_response="true",name="home"} 3.0
+k6_http_req_duration_count{expected_response="true",name="home"} 3
+# TYPE k6_http_reqs counter
+k6_http_reqs_total{expected_response="true",name="home"} 3.0
+# TYPE k6_vus gauge
+k6_vus{expected_response="true",name="home"} 5.0
+# EOF
+`, rec.Body.String())
+
+	// the native histograms are only in the Protobuf format
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", string(expfmt.FmtProtoDelim))
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, string(expfmt.FmtProtoDelim), rec.Header().Get("Content-Type"))
+
+	dec := expfmt.NewDecoder(rec.Body, expfmt.FmtProtoDelim)
+	var hist *dto.Histogram
+	for {
+		var family dto.MetricFamily
+		if err := dec.Decode(&family); err != nil {
+			break
+		}
+		if family.GetName() == "k6_http_req_duration" {
+			require.Len(t, family.Metric, 1)
+			hist = family.Metric[0].Histogram
+		}
+	}
+	require.NotNil(t, hist)
+	assert.Equal(t, int32(nativeHistogramSchema), hist.GetSchema())
+	assert.Equal(t, uint64(3), hist.GetSampleCount())
+	assert.Equal(t, uint64(1), hist.GetZeroCount())
+	assert.Equal(t, []int64{1, 0}, hist.PositiveDelta)
+}
+
+func TestHistogramAsPrometheus(t *testing.T) {
+	t.Parallel()
+
+	h := newHistogram()
+	for _, v := range []float64{0, 1, 2, -1} {
+		h.Add(v)
+	}
+	ch := newCumulativeHistogram(h.MinimumResolution)
+	ch.add(h)
+	phist := histogramAsPrometheus(ch)
+
+	assert.Equal(t, uint64(4), phist.GetSampleCount())
+	assert.Equal(t, 2.0, phist.GetSampleSum())
+	assert.Equal(t, int32(7), phist.GetSchema())
+	assert.Equal(t, uint64(1), phist.GetZeroCount())
+
+	// 1 is counted in the bucket of 1.003, the upper bound of its bucket in
+	// the histogram, and 2 in the bucket of the maximum
+	require.Len(t, phist.PositiveSpan, 2)
+	assert.Equal(t, int32(1), phist.PositiveSpan[0].GetOffset())
+	assert.Equal(t, uint32(1), phist.PositiveSpan[0].GetLength())
+	assert.Equal(t, int32(126), phist.PositiveSpan[1].GetOffset())
+	assert.Equal(t, uint32(1), phist.PositiveSpan[1].GetLength())
+	assert.Equal(t, []int64{1, 0}, phist.PositiveDelta)
+
+	// the untrackable -1 is counted in the bucket of the minimum
+	require.Len(t, phist.NegativeSpan, 1)
+	assert.Equal(t, int32(0), phist.NegativeSpan[0].GetOffset())
+	assert.Equal(t, []int64{1}, phist.NegativeDelta)
+
+	bounds := make(map[float64]uint64)
+	for _, b := range phist.Bucket {
+		bounds[b.GetUpperBound()] = b.GetCumulativeCount()
+	}
+	assert.Equal(t, map[float64]uint64{-1: 1, 0: 2, 2: 4}, bounds)
+}
+
+func TestCumulativeHistogramDoesNotWrap(t *testing.T) {
+	t.Parallel()
+
+	h := newHistogram()
+	h.Add(1)
+	h.Add(1000)
+	for index := range h.Buckets {
+		h.Buckets[index] = math.MaxUint32 / 2
+	}
+	h.Count = math.MaxUint32
+
+	ch := newCumulativeHistogram(h.MinimumResolution)
+	ch.add(h)
+	ch.add(h)
+
+	phist := histogramAsPrometheus(ch)
+	assert.Equal(t, uint64(2*math.MaxUint32), phist.GetSampleCount())
+	require.NotEmpty(t, phist.Bucket)
+	assert.Equal(t, uint64(4*(math.MaxUint32/2)), phist.Bucket[len(phist.Bucket)-1].GetCumulativeCount())
+}
+
+func TestNativeHistogramBuckets(t *testing.T) {
+	t.Parallel()
+
+	spans, deltas := nativeHistogramBuckets(nil)
+	assert.Nil(t, spans)
+	assert.Nil(t, deltas)
+
+	spans, deltas = nativeHistogramBuckets(map[int32]uint64{-2: 3, -1: 1, 0: 4, 5: 2})
+	require.Len(t, spans, 2)
+	assert.Equal(t, int32(-2), spans[0].GetOffset())
+	assert.Equal(t, uint32(3), spans[0].GetLength())
+	assert.Equal(t, int32(4), spans[1].GetOffset())
+	assert.Equal(t, uint32(1), spans[1].GetLength())
+	assert.Equal(t, []int64{3, -2, 3, -2}, deltas)
+}
+
+func TestSanitizePrometheusName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "http_req_duration", sanitizePrometheusName("http_req_duration"))
+	assert.Equal(t, "my_metric_1", sanitizePrometheusName("my-metric.1"))
+	assert.Equal(t, "_xx", sanitizePrometheusName("1xx"))
+}
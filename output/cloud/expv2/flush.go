@@ -18,7 +18,9 @@ type metricsFlusher struct {
 	bq                         *bucketQ
 	client                     pusher
 	logger                     logrus.FieldLogger
-	discardedLabels            map[string]struct{}
+	labelsFilter               *labelsFilter
+	exposer                    *metricsExposer
+	discardedLabels            map[string]uint64
 	aggregationPeriodInSeconds uint32
 	maxSeriesInBatch           int
 	batchPushConcurrency       int
@@ -33,6 +35,9 @@ func (f *metricsFlusher) flush() error {
 	if len(buckets) < 1 {
 		return nil
 	}
+	if f.exposer != nil {
+		f.exposer.add(buckets)
+	}
 
 	// Pivot the data structure from a slice of Timebuckets
 	// to a metric set of time series where each has nested samples.
@@ -68,7 +73,7 @@ func (f *metricsFlusher) flush() error {
 			// We hit the batch size, let's flush
 			seriesCount += len(msb.seriesIndex)
 			batches = append(batches, msb.MetricSet)
-			f.reportDiscardedLabels(msb.discardedLabels)
+			f.reportDiscardedLabels(msb.discardedLabels, discardedReserved)
 
 			// Reset the builder
 			msb = newMetricSetBuilder(f.testRunID, f.aggregationPeriodInSeconds)
@@ -79,7 +84,10 @@ func (f *metricsFlusher) flush() error {
 	if len(msb.seriesIndex) != 0 {
 		seriesCount += len(msb.seriesIndex)
 		batches = append(batches, msb.MetricSet)
-		f.reportDiscardedLabels(msb.discardedLabels)
+		f.reportDiscardedLabels(msb.discardedLabels, discardedReserved)
+	}
+	if f.labelsFilter != nil {
+		f.reportDiscardedLabels(f.labelsFilter.popDropped(), discardedByRules)
 	}
 
 	return f.flushBatches(batches)
@@ -135,14 +143,21 @@ outer:
 	return finalErr
 }
 
-func (f *metricsFlusher) reportDiscardedLabels(discardedLabels map[string]struct{}) {
-	for key := range discardedLabels {
-		if _, ok := f.discardedLabels[key]; ok {
+// reportDiscardedLabels warns about every tag the first time it's discarded,
+// and then only logs the updated count. The count is of the time series for
+// the reserved tags, and of the samples for the ones discarded by the rules.
+func (f *metricsFlusher) reportDiscardedLabels(discardedLabels map[string]uint64, reason string) {
+	for key, count := range discardedLabels {
+		total, ok := f.discardedLabels[key]
+		total += count
+		f.discardedLabels[key] = total
+
+		logger := f.logger.WithField("count", total)
+		if ok {
+			logger.Debugf("Tag %s has been discarded since %s.", key, reason)
 			continue
 		}
-
-		f.discardedLabels[key] = struct{}{}
-		f.logger.Warnf("Tag %s has been discarded since it is reserved for Cloud operations.", key)
+		logger.Warnf("Tag %s has been discarded since %s.", key, reason)
 	}
 }
 
@@ -172,9 +187,9 @@ type metricSetBuilder struct {
 	// the aggregated measurements for each time series.
 	seriesIndex map[metrics.TimeSeries]uint
 
-	// discardedLabels tracks the labels that have been discarded
-	// since they are reserved for internal usage by the Cloud service.
-	discardedLabels map[string]struct{}
+	// discardedLabels counts the time series from which the labels have been
+	// discarded since they are reserved for internal usage by the Cloud service.
+	discardedLabels map[string]uint64
 }
 
 func newMetricSetBuilder(testRunID string, aggrPeriodSec uint32) metricSetBuilder {
@@ -224,14 +239,10 @@ func (msb *metricSetBuilder) recordDiscardedLabels(labels []string) {
 	}
 
 	if msb.discardedLabels == nil {
-		msb.discardedLabels = make(map[string]struct{})
+		msb.discardedLabels = make(map[string]uint64)
 	}
 
 	for _, key := range labels {
-		if _, ok := msb.discardedLabels[key]; ok {
-			continue
-		}
-
-		msb.discardedLabels[key] = struct{}{}
+		msb.discardedLabels[key]++
 	}
 }
@@ -63,7 +63,7 @@ func TestMetricsFlusherFlushInBatchWithinBucket(t *testing.T) {
 			bq:                   bq,
 			client:               pm,
 			logger:               logger,
-			discardedLabels:      make(map[string]struct{}),
+			discardedLabels:      make(map[string]uint64),
 			maxSeriesInBatch:     3,
 			batchPushConcurrency: 5,
 		}
@@ -109,7 +109,7 @@ func TestMetricsFlusherFlushInBatchAcrossBuckets(t *testing.T) {
 			bq:                   bq,
 			client:               pm,
 			logger:               logger,
-			discardedLabels:      make(map[string]struct{}),
+			discardedLabels:      make(map[string]uint64),
 			maxSeriesInBatch:     3,
 			batchPushConcurrency: 5,
 		}
@@ -159,7 +159,7 @@ func TestFlushWithReservedLabels(t *testing.T) {
 		client:               pm,
 		maxSeriesInBatch:     2,
 		logger:               logger,
-		discardedLabels:      make(map[string]struct{}),
+		discardedLabels:      make(map[string]uint64),
 		batchPushConcurrency: 5,
 	}
 
@@ -237,7 +237,7 @@ func TestFlushMaxSeriesInBatch(t *testing.T) {
 		client:               pm,
 		maxSeriesInBatch:     2,
 		logger:               logger,
-		discardedLabels:      make(map[string]struct{}),
+		discardedLabels:      make(map[string]uint64),
 		batchPushConcurrency: 5,
 	}
 
@@ -349,7 +349,7 @@ func TestMetricsFlusherErrorCase(t *testing.T) {
 		bq:                   bq,
 		client:               pm,
 		logger:               logger,
-		discardedLabels:      make(map[string]struct{}),
+		discardedLabels:      make(map[string]uint64),
 		maxSeriesInBatch:     3,
 		batchPushConcurrency: 2,
 	}
@@ -224,3 +224,60 @@ func resolveBucketIndex(val float64) uint32 {
 func (h *histogram) Add(v float64) {
 	h.addToBucket(v)
 }
+
+// bucketUpperBound returns the highest value, in units of the minimum
+// resolution, that is counted in the bucket with the index. It's the inverse
+// of resolveBucketIndex.
+func bucketUpperBound(index uint32) float64 {
+	const k = uint32(7)
+	if index < 256 {
+		return float64(index)
+	}
+
+	// see resolveBucketIndex for the derivation: index = (n-k)<<k + u>>(n-k)
+	nkdiff := index>>k - 1
+	subBucket := uint64(index - nkdiff<<k)
+	return float64((subBucket+1)<<nkdiff - 1)
+}
+
+// histogramPercentile returns an approximation of the percentile p, between
+// 0 and 1, of the values counted by the histogram: the upper bound of the
+// bucket in which it falls, limited by the observed minimum and maximum.
+func histogramPercentile(hval *pbcloud.TrendHdrValue, p float64) float64 {
+	if hval.Count == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(p * float64(hval.Count)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	cumulative := uint64(hval.GetExtraLowValuesCounter())
+	if cumulative >= rank {
+		return hval.MinValue
+	}
+
+	var index uint32
+	counter := 0
+	for i, span := range hval.Spans {
+		if i == 0 {
+			index = span.Offset
+		} else {
+			index += span.Offset + 1
+		}
+		for j := uint32(0); j < span.Length && counter < len(hval.Counters); j++ {
+			if j > 0 {
+				index++
+			}
+			cumulative += uint64(hval.Counters[counter])
+			counter++
+			if cumulative >= rank {
+				value := bucketUpperBound(index) * hval.MinResolution
+				return math.Max(hval.MinValue, math.Min(value, hval.MaxValue))
+			}
+		}
+	}
+
+	// the rest are the extra high values
+	return hval.MaxValue
+}
@@ -501,3 +501,36 @@ func TestHistogramAsProto(t *testing.T) {
 		})
 	}
 }
+
+func TestBucketUpperBound(t *testing.T) {
+	t.Parallel()
+
+	for _, index := range []uint32{0, 1, 255, 256, 257, 383, 384, 1000, 2047, 3000} {
+		upper := bucketUpperBound(index)
+		assert.Equal(t, index, resolveBucketIndex(upper), "index %d", index)
+		assert.Equal(t, index+1, resolveBucketIndex(upper+1), "index %d", index)
+	}
+}
+
+func TestHistogramPercentile(t *testing.T) {
+	t.Parallel()
+
+	h := newHistogram()
+	for i := 1; i <= 1000; i++ {
+		h.Add(float64(i))
+	}
+	hval := histogramAsProto(h, time.Unix(1, 0).UnixNano())
+
+	for p, exp := range map[float64]float64{.5: 500, .9: 900, .99: 990, 1: 1000} {
+		// the precision of the buckets is better than 1% of the values
+		assert.InEpsilon(t, exp, histogramPercentile(hval, p), .01, "p(%g)", p*100)
+	}
+	assert.InEpsilon(t, 1, histogramPercentile(hval, 0), .01)
+
+	h = newHistogram()
+	h.Add(-10)
+	h.Add(5)
+	hval = histogramAsProto(h, time.Unix(1, 0).UnixNano())
+	assert.Equal(t, -10.0, histogramPercentile(hval, .5), "the extra low values are the minimum")
+	assert.Equal(t, 5.0, histogramPercentile(hval, 1))
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/lib/testutils"
 	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
@@ -52,7 +53,7 @@ func TestOutputFlush(t *testing.T) {
 		"expv2/integration", conf.Timeout.TimeDuration())
 
 	// init and start the output
-	o, err := expv2.New(logger, conf, cc)
+	o, err := expv2.New(logger, conf, cc, fsext.NewMemMapFs())
 	require.NoError(t, err)
 	o.SetTestRunID("my-test-run-id-123")
 	require.NoError(t, o.Start())
@@ -0,0 +1,152 @@
+package expv2
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/metrics"
+)
+
+// labelsFilterCacheSize is the maximum number of time series whose filtered
+// version is cached, the cache is reset once it's full.
+const labelsFilterCacheSize = 10000
+
+// The reasons for which the tags are discarded, for reportDiscardedLabels.
+const (
+	discardedReserved = "it is reserved for Cloud operations"
+	discardedByRules  = "it isn't allowed by the cloud labels rules"
+)
+
+type filteredTimeSeries struct {
+	timeSeries metrics.TimeSeries
+	dropped    []string
+}
+
+// labelsFilter drops the tags that shouldn't be sent to the cloud as labels
+// from the time series, before their samples are aggregated, so the series
+// that differ only by the dropped tags are aggregated together.
+type labelsFilter struct {
+	allow []string
+	deny  []string
+	rules map[string]cloudapi.LabelRule
+
+	// cache is used only by the collector, so it doesn't need to be guarded.
+	cache map[metrics.TimeSeries]filteredTimeSeries
+
+	// dropped counts, for every tag key, how many samples it was dropped
+	// from, since the last time it was popped.
+	mu      sync.Mutex
+	dropped map[string]uint64
+}
+
+// newLabelsFilter returns a filter for the labels rules of the config, or nil
+// if there are none.
+func newLabelsFilter(conf cloudapi.Config) (*labelsFilter, error) {
+	f := &labelsFilter{
+		allow:   splitLabelPatterns(conf.LabelsAllow.String),
+		deny:    splitLabelPatterns(conf.LabelsDeny.String),
+		rules:   conf.LabelRules,
+		cache:   make(map[metrics.TimeSeries]filteredTimeSeries),
+		dropped: make(map[string]uint64),
+	}
+	if len(f.allow) == 0 && len(f.deny) == 0 && len(f.rules) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	patterns := append(append([]string{}, f.allow...), f.deny...)
+	for _, rule := range f.rules {
+		patterns = append(append(patterns, rule.Allow...), rule.Deny...)
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid cloud labels pattern %q: %w", pattern, err)
+		}
+	}
+	return f, nil
+}
+
+func splitLabelPatterns(s string) []string {
+	var patterns []string
+	for _, pattern := range strings.Split(s, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// apply returns the time series without the tags that aren't allowed.
+func (f *labelsFilter) apply(ts metrics.TimeSeries) metrics.TimeSeries {
+	filtered, ok := f.cache[ts]
+	if !ok {
+		filtered = f.filter(ts)
+		if len(f.cache) >= labelsFilterCacheSize {
+			f.cache = make(map[metrics.TimeSeries]filteredTimeSeries)
+		}
+		f.cache[ts] = filtered
+	}
+
+	if len(filtered.dropped) > 0 {
+		f.mu.Lock()
+		for _, key := range filtered.dropped {
+			f.dropped[key]++
+		}
+		f.mu.Unlock()
+	}
+	return filtered.timeSeries
+}
+
+func (f *labelsFilter) filter(ts metrics.TimeSeries) filteredTimeSeries {
+	allow, deny := f.allow, f.deny
+	if rule, ok := f.rules[ts.Metric.Name]; ok {
+		if rule.Allow != nil {
+			allow = rule.Allow
+		}
+		if rule.Deny != nil {
+			deny = rule.Deny
+		}
+	}
+
+	filtered := filteredTimeSeries{timeSeries: ts}
+	for key := range ts.Tags.Map() {
+		if isLabelAllowed(key, allow, deny) {
+			continue
+		}
+		filtered.timeSeries.Tags = filtered.timeSeries.Tags.Without(key)
+		filtered.dropped = append(filtered.dropped, key)
+	}
+	return filtered
+}
+
+// isLabelAllowed returns whether the name matches any of the allow patterns,
+// or there are none, and doesn't match any of the deny patterns.
+func isLabelAllowed(name string, allow, deny []string) bool {
+	for _, pattern := range deny {
+		if matched, _ := path.Match(pattern, name); matched {
+			return false
+		}
+	}
+	if len(allow) == 0 {
+		return true
+	}
+	for _, pattern := range allow {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// popDropped returns how many samples every tag was dropped from, since the
+// last time it was called.
+func (f *labelsFilter) popDropped() map[string]uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	dropped := f.dropped
+	f.dropped = make(map[string]uint64, len(dropped))
+	return dropped
+}
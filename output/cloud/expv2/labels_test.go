@@ -0,0 +1,116 @@
+package expv2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/metrics"
+)
+
+func TestNewLabelsFilter(t *testing.T) {
+	t.Parallel()
+
+	f, err := newLabelsFilter(cloudapi.Config{LabelsAllow: null.StringFrom(" , ")})
+	require.NoError(t, err)
+	assert.Nil(t, f)
+
+	_, err = newLabelsFilter(cloudapi.Config{LabelsDeny: null.StringFrom("url,[")})
+	assert.ErrorContains(t, err, `invalid cloud labels pattern "["`)
+
+	_, err = newLabelsFilter(cloudapi.Config{
+		LabelRules: map[string]cloudapi.LabelRule{"my_metric": {Allow: []string{"[a-"}}},
+	})
+	assert.Error(t, err)
+}
+
+func TestLabelsFilterApply(t *testing.T) {
+	t.Parallel()
+
+	r := metrics.NewRegistry()
+	trend := r.MustNewMetric("http_req_duration", metrics.Trend)
+	counter := r.MustNewMetric("http_reqs", metrics.Counter)
+	tags := r.RootTagSet().WithTagsFromMap(map[string]string{
+		"name": "home", "status": "200", "method": "GET", "url": "https://example.com/?id=1", "iter_id": "1",
+	})
+
+	f, err := newLabelsFilter(cloudapi.Config{
+		LabelsAllow: null.StringFrom("name,status,url,iter_*"),
+		LabelsDeny:  null.StringFrom("url, iter_*"),
+		LabelRules: map[string]cloudapi.LabelRule{
+			"http_req_duration": {Allow: []string{"name", "method"}},
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, f)
+
+	ts := f.apply(metrics.TimeSeries{Metric: counter, Tags: tags})
+	assert.Equal(t, map[string]string{"name": "home", "status": "200"}, ts.Tags.Map())
+
+	// the rule overrides only the allow list
+	ts = f.apply(metrics.TimeSeries{Metric: trend, Tags: tags})
+	assert.Equal(t, map[string]string{"name": "home", "method": "GET"}, ts.Tags.Map())
+
+	// the cached time series are the same
+	other := f.apply(metrics.TimeSeries{Metric: counter, Tags: tags})
+	assert.Equal(t, metrics.TimeSeries{Metric: counter, Tags: r.RootTagSet().With("name", "home").With("status", "200")},
+		other)
+
+	assert.Equal(t, map[string]uint64{"url": 3, "iter_id": 3, "method": 2, "status": 1}, f.popDropped())
+	assert.Empty(t, f.popDropped())
+}
+
+func TestCollectorWithLabelsFilter(t *testing.T) {
+	t.Parallel()
+
+	r := metrics.NewRegistry()
+	m1 := r.MustNewMetric("metric1", metrics.Counter)
+
+	f, err := newLabelsFilter(cloudapi.Config{LabelsDeny: null.StringFrom("url")})
+	require.NoError(t, err)
+	c := collector{
+		aggregationPeriod: 3 * time.Second,
+		waitPeriod:        1 * time.Second,
+		timeBuckets:       make(map[int64]map[metrics.TimeSeries]metricValue),
+		nowFunc:           func() time.Time { return time.Unix(31, 0) },
+		labels:            f,
+	}
+	for _, url := range []string{"/1", "/2", "/3"} {
+		c.collectSample(metrics.Sample{
+			TimeSeries: metrics.TimeSeries{Metric: m1, Tags: r.RootTagSet().With("name", "foo").With("url", url)},
+			Value:      1.0,
+			Time:       time.Unix(10, 0),
+		})
+	}
+
+	// the series that differ only by the dropped tag are aggregated together
+	require.Len(t, c.timeBuckets, 1)
+	for _, bucket := range c.timeBuckets {
+		require.Len(t, bucket, 1)
+		for ts, sink := range bucket {
+			assert.Equal(t, map[string]string{"name": "foo"}, ts.Tags.Map())
+			assert.Equal(t, 3.0, sink.(*counter).Sum) //nolint:forcetypeassert
+		}
+	}
+
+	logger, hook := testutils.NewLoggerWithHook(t)
+	flusher := metricsFlusher{
+		logger:          logger,
+		labelsFilter:    f,
+		discardedLabels: make(map[string]uint64),
+		bq:              &bucketQ{},
+	}
+	flusher.bq.Push([]timeBucket{{Time: 1, Sinks: map[metrics.TimeSeries]metricValue{}}})
+	require.NoError(t, flusher.flush())
+
+	entries := testutils.FilterEntries(hook.Drain(), logrus.WarnLevel,
+		"Tag url has been discarded since it isn't allowed by the cloud labels rules.")
+	require.Len(t, entries, 1)
+	assert.Equal(t, uint64(3), entries[0].Data["count"])
+}
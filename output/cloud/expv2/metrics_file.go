@@ -0,0 +1,182 @@
+package expv2
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"google.golang.org/protobuf/proto"
+
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/output/cloud/expv2/pbcloud"
+)
+
+// MetricsFileExt is the extension of the files written by the cloud output
+// when cloudapi.Config.MetricsDir is set.
+const MetricsFileExt = ".pb.sz"
+
+// metricsFileSink is a pusher that writes every batch of metrics to its own
+// file in a directory, exactly as it would be sent to the cloud, i.e. as a
+// snappy-compressed Protobuf message.
+type metricsFileSink struct {
+	fs  fsext.Fs
+	dir string
+	seq uint64
+}
+
+func newMetricsFileSink(fs fsext.Fs, dir string) (*metricsFileSink, error) {
+	if err := fs.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create the metrics directory: %w", err)
+	}
+	return &metricsFileSink{fs: fs, dir: dir}, nil
+}
+
+func (s *metricsFileSink) push(samples *pbcloud.MetricSet) error {
+	b, err := newRequestBody(samples)
+	if err != nil {
+		return err
+	}
+	seq := atomic.AddUint64(&s.seq, 1)
+	name := filepath.Join(s.dir, fmt.Sprintf("%019d-%06d%s", time.Now().UnixNano(), seq, MetricsFileExt))
+	return fsext.WriteFile(s.fs, name, b, 0o640)
+}
+
+// DecodedMetricSet is the human-readable version of a batch of metrics, as
+// it's sent to the cloud.
+type DecodedMetricSet struct {
+	TestRunID         string          `json:"test_run_id"`
+	AggregationPeriod uint32          `json:"aggregation_period"`
+	Metrics           []DecodedMetric `json:"metrics"`
+}
+
+// DecodedMetric is a metric of a DecodedMetricSet.
+type DecodedMetric struct {
+	Name       string              `json:"name"`
+	Type       string              `json:"type"`
+	TimeSeries []DecodedTimeSeries `json:"time_series"`
+}
+
+// DecodedTimeSeries is a time series of a DecodedMetric, its Samples depend
+// on the type of the metric.
+type DecodedTimeSeries struct {
+	Labels  map[string]string `json:"labels"`
+	Samples []any             `json:"samples"`
+}
+
+type decodedCounterSample struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+type decodedGaugeSample struct {
+	Time  time.Time `json:"time"`
+	Last  float64   `json:"last"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Avg   float64   `json:"avg"`
+	Count uint32    `json:"count"`
+}
+
+type decodedRateSample struct {
+	Time         time.Time `json:"time"`
+	NonZeroCount uint32    `json:"nonzero_count"`
+	TotalCount   uint32    `json:"total_count"`
+}
+
+// decodedTrendSample has the approximate percentiles of the histogram,
+// instead of its buckets.
+type decodedTrendSample struct {
+	Time        time.Time          `json:"time"`
+	Count       uint32             `json:"count"`
+	Sum         float64            `json:"sum"`
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Avg         float64            `json:"avg"`
+	Percentiles map[string]float64 `json:"percentiles"`
+	ExtraLow    uint32             `json:"extra_low_values,omitempty"`
+	ExtraHigh   uint32             `json:"extra_high_values,omitempty"`
+}
+
+// The percentiles of the trends that DecodeMetricsFile approximates.
+var decodedPercentiles = []float64{.5, .9, .95, .99} //nolint:gochecknoglobals
+
+// DecodeMetricsFile decodes a batch of metrics written by the cloud output
+// when cloudapi.Config.MetricsDir is set.
+func DecodeMetricsFile(data []byte) (*DecodedMetricSet, error) {
+	b, err := snappy.Decode(nil, data)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing the metrics failed: %w", err)
+	}
+	ms := &pbcloud.MetricSet{}
+	if err = proto.Unmarshal(b, ms); err != nil {
+		return nil, fmt.Errorf("decoding the metrics as Protobuf failed: %w", err)
+	}
+
+	decoded := &DecodedMetricSet{
+		TestRunID:         ms.TestRunId,
+		AggregationPeriod: ms.AggregationPeriod,
+		Metrics:           make([]DecodedMetric, 0, len(ms.Metrics)),
+	}
+	for _, m := range ms.Metrics {
+		dm := DecodedMetric{
+			Name:       m.Name,
+			Type:       strings.ToLower(strings.TrimPrefix(m.Type.String(), "METRIC_TYPE_")),
+			TimeSeries: make([]DecodedTimeSeries, 0, len(m.TimeSeries)),
+		}
+		for _, ts := range m.TimeSeries {
+			dm.TimeSeries = append(dm.TimeSeries, decodeTimeSeries(ts))
+		}
+		decoded.Metrics = append(decoded.Metrics, dm)
+	}
+	return decoded, nil
+}
+
+func decodeTimeSeries(ts *pbcloud.TimeSeries) DecodedTimeSeries {
+	dts := DecodedTimeSeries{Labels: make(map[string]string, len(ts.Labels))}
+	for _, l := range ts.Labels {
+		dts.Labels[l.Name] = l.Value
+	}
+
+	switch samples := ts.Samples.(type) {
+	case *pbcloud.TimeSeries_CounterSamples:
+		for _, v := range samples.CounterSamples.Values {
+			dts.Samples = append(dts.Samples, decodedCounterSample{Time: v.Time.AsTime(), Value: v.Value})
+		}
+	case *pbcloud.TimeSeries_GaugeSamples:
+		for _, v := range samples.GaugeSamples.Values {
+			dts.Samples = append(dts.Samples, decodedGaugeSample{
+				Time: v.Time.AsTime(), Last: v.Last, Min: v.Min, Max: v.Max, Avg: v.Avg, Count: v.Count,
+			})
+		}
+	case *pbcloud.TimeSeries_RateSamples:
+		for _, v := range samples.RateSamples.Values {
+			dts.Samples = append(dts.Samples, decodedRateSample{
+				Time: v.Time.AsTime(), NonZeroCount: v.NonzeroCount, TotalCount: v.TotalCount,
+			})
+		}
+	case *pbcloud.TimeSeries_TrendHdrSamples:
+		for _, v := range samples.TrendHdrSamples.Values {
+			sample := decodedTrendSample{
+				Time:        v.Time.AsTime(),
+				Count:       v.Count,
+				Sum:         v.Sum,
+				Min:         v.MinValue,
+				Max:         v.MaxValue,
+				Percentiles: make(map[string]float64, len(decodedPercentiles)),
+				ExtraLow:    v.GetExtraLowValuesCounter(),
+				ExtraHigh:   v.GetExtraHighValuesCounter(),
+			}
+			if v.Count > 0 {
+				sample.Avg = v.Sum / float64(v.Count)
+			}
+			for _, p := range decodedPercentiles {
+				sample.Percentiles[fmt.Sprintf("p(%g)", p*100)] = histogramPercentile(v, p)
+			}
+			dts.Samples = append(dts.Samples, sample)
+		}
+	}
+	return dts
+}
@@ -0,0 +1,76 @@
+package expv2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/output/cloud/expv2/pbcloud"
+)
+
+func TestMetricsFileSink(t *testing.T) {
+	t.Parallel()
+
+	r := metrics.NewRegistry()
+	trend := r.MustNewMetric("my_trend", metrics.Trend)
+	counterMetric := r.MustNewMetric("my_counter", metrics.Counter)
+	tags := r.RootTagSet().With("key1", "val1")
+
+	h := newHistogram()
+	for i := 1; i <= 100; i++ {
+		h.Add(float64(i))
+	}
+	c := &counter{}
+	c.Add(42)
+
+	msb := newMetricSetBuilder("123", 3)
+	msb.addTimeSeries(time.Unix(10, 0).UnixNano(), metrics.TimeSeries{Metric: trend, Tags: tags}, h)
+	msb.addTimeSeries(time.Unix(10, 0).UnixNano(), metrics.TimeSeries{Metric: counterMetric, Tags: tags}, c)
+
+	fs := fsext.NewMemMapFs()
+	sink, err := newMetricsFileSink(fs, "/metrics")
+	require.NoError(t, err)
+	require.NoError(t, sink.push(msb.MetricSet))
+	require.NoError(t, sink.push(&pbcloud.MetricSet{TestRunId: "123"}))
+
+	entries, err := fsext.ReadDir(fs, "/metrics")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// the file has exactly the body of the request to the cloud
+	data, err := fsext.ReadFile(fs, "/metrics/"+entries[0].Name())
+	require.NoError(t, err)
+	body, err := newRequestBody(msb.MetricSet)
+	require.NoError(t, err)
+	assert.Equal(t, body, data)
+
+	decoded, err := DecodeMetricsFile(data)
+	require.NoError(t, err)
+	assert.Equal(t, "123", decoded.TestRunID)
+	assert.EqualValues(t, 3, decoded.AggregationPeriod)
+	require.Len(t, decoded.Metrics, 2)
+
+	assert.Equal(t, "my_trend", decoded.Metrics[0].Name)
+	assert.Equal(t, "trend", decoded.Metrics[0].Type)
+	require.Len(t, decoded.Metrics[0].TimeSeries, 1)
+	assert.Equal(t, map[string]string{"key1": "val1"}, decoded.Metrics[0].TimeSeries[0].Labels)
+	require.Len(t, decoded.Metrics[0].TimeSeries[0].Samples, 1)
+	trendSample, ok := decoded.Metrics[0].TimeSeries[0].Samples[0].(decodedTrendSample)
+	require.True(t, ok)
+	assert.Equal(t, time.Unix(10, 0).UTC(), trendSample.Time)
+	assert.EqualValues(t, 100, trendSample.Count)
+	assert.Equal(t, 50.5, trendSample.Avg)
+	assert.InEpsilon(t, 95, trendSample.Percentiles["p(95)"], .01)
+	assert.Len(t, trendSample.Percentiles, 4)
+
+	assert.Equal(t, "counter", decoded.Metrics[1].Type)
+	assert.Equal(t, []any{decodedCounterSample{Time: time.Unix(10, 0).UTC(), Value: 42}},
+		decoded.Metrics[1].TimeSeries[0].Samples)
+
+	_, err = DecodeMetricsFile([]byte("not snappy"))
+	assert.Error(t, err)
+}
@@ -4,7 +4,6 @@ package expv2
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -12,10 +11,10 @@ import (
 	"time"
 
 	"go.k6.io/k6/cloudapi"
-	"go.k6.io/k6/cloudapi/insights"
 	"go.k6.io/k6/errext"
 	"go.k6.io/k6/errext/exitcodes"
 	"go.k6.io/k6/lib/consts"
+	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/metrics"
 	"go.k6.io/k6/output"
 	insightsOutput "go.k6.io/k6/output/cloud/insights"
@@ -36,13 +35,24 @@ type Output struct {
 	config      cloudapi.Config
 	cloudClient *cloudapi.Client
 	testRunID   string
+	fs          fsext.Fs
 
 	collector *collector
 	flushing  flusher
 
+	// spool is set if the metrics that fail to be pushed should be written to disk
+	spool *metricsSpool
+
+	// exposer and exposeServer are set if the aggregated metrics are exposed
+	// for being scraped by Prometheus
+	exposer      *metricsExposer
+	exposeServer *http.Server
+
 	insightsClient            insightsOutput.Client
 	requestMetadatasCollector insightsOutput.RequestMetadatasCollector
 	requestMetadatasFlusher   insightsOutput.RequestMetadatasFlusher
+	requestMetadatasStats     *insightsOutput.Stats
+	requestMetadatasReady     <-chan struct{}
 
 	// wg tracks background goroutines
 	wg sync.WaitGroup
@@ -56,9 +66,11 @@ type Output struct {
 	testStopFunc func(error)
 }
 
-// New creates a new cloud output.
-func New(logger logrus.FieldLogger, conf cloudapi.Config, _ *cloudapi.Client) (*Output, error) {
+// New creates a new cloud output. The file system is used only for writing
+// the metrics to the cloudapi.Config.SpoolDir or MetricsDir.
+func New(logger logrus.FieldLogger, conf cloudapi.Config, _ *cloudapi.Client, fs fsext.Fs) (*Output, error) {
 	return &Output{
+		fs:     fs,
 		config: conf,
 		logger: logger.WithField("output", "cloudv2"),
 		abort:  make(chan struct{}),
@@ -99,17 +111,43 @@ func (o *Output) Start() error {
 	if err != nil {
 		return fmt.Errorf("failed to initialize the samples collector: %w", err)
 	}
+	o.collector.labels, err = newLabelsFilter(o.config)
+	if err != nil {
+		return err
+	}
 
 	mc, err := newMetricsClient(o.cloudClient, o.testRunID)
 	if err != nil {
 		return fmt.Errorf("failed to initialize the http metrics flush client: %w", err)
 	}
+	var client pusher = mc
+	if o.config.MetricsDir.String != "" {
+		client, err = newMetricsFileSink(o.fs, o.config.MetricsDir.String)
+		if err != nil {
+			return err
+		}
+	} else if o.config.SpoolDir.String != "" {
+		o.spool, err = newMetricsSpool(o.fs, o.config.SpoolDir.String, o.testRunID)
+		if err != nil {
+			return err
+		}
+		client = &spoolingPusher{pusher: mc, spool: o.spool, logger: o.logger}
+	}
+	if o.config.MetricsExpose.String != "" {
+		o.exposer = newMetricsExposer(o.logger)
+		o.exposeServer, err = o.exposer.serve(o.config.MetricsExpose.String)
+		if err != nil {
+			return err
+		}
+	}
 	o.flushing = &metricsFlusher{
 		testRunID:                  o.testRunID,
 		bq:                         &o.collector.bq,
-		client:                     mc,
+		client:                     client,
 		logger:                     o.logger,
-		discardedLabels:            make(map[string]struct{}),
+		labelsFilter:               o.collector.labels,
+		exposer:                    o.exposer,
+		discardedLabels:            make(map[string]uint64),
 		aggregationPeriodInSeconds: uint32(o.config.AggregationPeriod.TimeDuration().Seconds()),
 		maxSeriesInBatch:           int(o.config.MaxTimeSeriesInBatch.Int64),
 		// TODO: when the migration from v1 is over
@@ -121,25 +159,33 @@ func (o *Output) Start() error {
 	o.periodicInvoke(o.config.AggregationPeriod.TimeDuration(), o.collectSamples)
 
 	if insightsOutput.Enabled(o.config) {
+		// The test run ID of the offline test runs may not be a number, the spans
+		// sent to an OpenTelemetry collector have 0 as it in that case.
 		testRunID, err := strconv.ParseInt(o.testRunID, 10, 64)
+		if err != nil && o.config.TracesExporter.String != insightsOutput.ExporterOTLP {
+			return err
+		}
+		bufferConfig, err := insightsOutput.NewBufferConfig(o.config)
 		if err != nil {
 			return err
 		}
-		o.requestMetadatasCollector = insightsOutput.NewCollector(testRunID)
+		o.requestMetadatasStats = &insightsOutput.Stats{}
+		collector := insightsOutput.NewBoundedCollector(testRunID, bufferConfig, o.requestMetadatasStats)
+		o.requestMetadatasCollector = collector
+		o.requestMetadatasReady = collector.BatchReady()
 
-		insightsClientConfig := insights.NewDefaultClientConfigForTestRun(
-			o.config.TracesHost.String,
-			o.config.Token.String,
-			testRunID,
-		)
-		insightsClient := insights.NewClient(insightsClientConfig)
+		insightsClient, err := insightsOutput.NewClient(o.config, testRunID)
+		if err != nil {
+			return err
+		}
 
 		if err := insightsClient.Dial(context.Background()); err != nil {
 			return err
 		}
 
 		o.insightsClient = insightsClient
-		o.requestMetadatasFlusher = insightsOutput.NewFlusher(insightsClient, o.requestMetadatasCollector)
+		o.requestMetadatasFlusher = insightsOutput.NewBatchingFlusher(
+			insightsClient, collector, bufferConfig.MaxBatchBytes, o.requestMetadatasStats)
 		o.runFlushRequestMetadatas()
 	}
 
@@ -156,6 +202,16 @@ func (o *Output) StopWithTestError(_ error) error {
 	close(o.stop)
 	o.wg.Wait()
 
+	if o.exposeServer != nil {
+		// it's closed after the final flush, so the final values are exposed
+		// until the end of the test
+		defer func() {
+			if err := o.exposeServer.Close(); err != nil {
+				o.logger.WithError(err).Error("Failed to close the server of the exposed metrics")
+			}
+		}()
+	}
+
 	select {
 	case <-o.abort:
 		return nil
@@ -169,12 +225,19 @@ func (o *Output) StopWithTestError(_ error) error {
 	o.collectSamples()
 	o.flushMetrics()
 
+	if o.spool != nil && o.spool.count() > 0 {
+		o.logger.Warnf("%d batches of metrics couldn't be pushed to the cloud and were spooled to %s, "+
+			"push them with `k6 cloud replay-metrics %s --ref-id %s`",
+			o.spool.count(), o.config.SpoolDir.String, o.config.SpoolDir.String, o.testRunID)
+	}
+
 	// Flush all the remaining request metadatas.
 	if insightsOutput.Enabled(o.config) {
 		o.flushRequestMetadatas()
 		if err := o.insightsClient.Close(); err != nil {
 			o.logger.WithError(err).Error("Failed to close the insights client")
 		}
+		o.requestMetadatasStats.LogSummary(o.logger)
 	}
 
 	return nil
@@ -283,6 +346,8 @@ func (o *Output) runFlushRequestMetadatas() {
 				select {
 				case <-t.C:
 					o.flushRequestMetadatas()
+				case <-o.requestMetadatasReady:
+					o.flushRequestMetadatas()
 				case <-o.stop:
 					return
 				case <-o.abort:
@@ -304,7 +369,7 @@ func (o *Output) flushRequestMetadatas() {
 		return
 	}
 
-	o.logger.WithField("t", time.Since(start)).Debug("Successfully flushed buffered trace samples to the cloud")
+	o.requestMetadatasStats.LogFlush(o.logger.WithField("t", time.Since(start)))
 }
 
 // handleFlushError handles errors generated from the flushing operation.
@@ -325,13 +390,8 @@ func (o *Output) handleFlushError(err error) {
 
 	o.logger.WithError(err).Error("Failed to push metrics to the cloud")
 
-	var errResp cloudapi.ErrorResponse
-	if !errors.As(err, &errResp) || errResp.Response == nil {
-		return
-	}
-	// The Cloud service returns the error code 4 when it doesn't accept any more metrics.
-	// So, when k6 sees that, the cloud output just stops prematurely.
-	if errResp.Response.StatusCode != http.StatusForbidden || errResp.Code != 4 {
+	// When the cloud doesn't accept any more metrics, the cloud output just stops prematurely.
+	if !isMetricsRefusedError(err) {
 		return
 	}
 
@@ -363,6 +423,11 @@ func printableConfig(c cloudapi.Config) map[string]any {
 		"webAppURL":             c.WebAppURL.String,
 		"projectID":             c.ProjectID.Int64,
 		"pushRefID":             c.PushRefID.String,
+		"spoolDir":              c.SpoolDir.String,
+		"metricsDir":            c.MetricsDir.String,
+		"metricsExpose":         c.MetricsExpose.String,
+		"labelsAllow":           c.LabelsAllow.String,
+		"labelsDeny":            c.LabelsDeny.String,
 		"stopOnError":           c.StopOnError.Bool,
 		"testRunDetails":        c.TestRunDetails.String,
 		"aggregationPeriod":     c.AggregationPeriod.String(),
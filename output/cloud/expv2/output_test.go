@@ -14,9 +14,11 @@ import (
 	"gopkg.in/guregu/null.v3"
 
 	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/lib/testutils"
 	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
+	insightsOutput "go.k6.io/k6/output/cloud/insights"
 )
 
 func TestNew(t *testing.T) {
@@ -24,7 +26,7 @@ func TestNew(t *testing.T) {
 
 	logger, hook := testutils.NewLoggerWithHook(t)
 	c := cloudapi.NewClient(logger, "my-token", "the-host", "v/foo", 1*time.Second)
-	o, err := New(logger, cloudapi.Config{APIVersion: null.IntFrom(99)}, c)
+	o, err := New(logger, cloudapi.Config{APIVersion: null.IntFrom(99)}, c, fsext.NewMemMapFs())
 	require.NoError(t, err)
 	require.NotNil(t, o)
 
@@ -44,7 +46,7 @@ func TestNewWithConfigOverwritten(t *testing.T) {
 	logger := testutils.NewLogger(t)
 	c := cloudapi.NewClient(logger, "my-token", "the-host", "v/foo", 1*time.Second)
 	conf := cloudapi.Config{Host: null.StringFrom("the-new-host")}
-	o, err := New(logger, conf, c)
+	o, err := New(logger, conf, c, fsext.NewMemMapFs())
 	require.NoError(t, err)
 	require.NotNil(t, o)
 	assert.Equal(t, "the-new-host/v1", o.cloudClient.BaseURL())
@@ -84,7 +86,7 @@ func TestOutputCollectSamples(t *testing.T) {
 	logger := testutils.NewLogger(t)
 	cc := cloudapi.NewClient(
 		logger, conf.Token.String, conf.Host.String, "v/test", conf.Timeout.TimeDuration())
-	o, err := New(logger, conf, cc)
+	o, err := New(logger, conf, cc, fsext.NewMemMapFs())
 	require.NoError(t, err)
 
 	o.SetTestRunID("ref-id-123")
@@ -297,7 +299,7 @@ func TestOutputStopWithTestError(t *testing.T) {
 	logger := testutils.NewLogger(t)
 	cc := cloudapi.NewClient(
 		logger, config.Token.String, config.Host.String, "v/test", config.Timeout.TimeDuration())
-	o, err := New(logger, config, cc)
+	o, err := New(logger, config, cc, fsext.NewMemMapFs())
 	require.NoError(t, err)
 
 	o.SetTestRunID("ref-id-123")
@@ -421,6 +423,7 @@ func TestOutputFlushRequestMetadatasConcurrently(t *testing.T) {
 	o.config.TracesPushConcurrency = null.IntFrom(2)
 	o.config.TracesPushInterval = types.NullDurationFrom(1) // loop
 	o.requestMetadatasFlusher = flusherFunc(flusherMock)
+	o.requestMetadatasStats = &insightsOutput.Stats{}
 	o.runFlushRequestMetadatas()
 
 	select {
@@ -447,6 +450,7 @@ func TestOutputFlushRequestMetadatasStop(t *testing.T) {
 	}
 
 	o.requestMetadatasFlusher = flusherFunc(flusherMock)
+	o.requestMetadatasStats = &insightsOutput.Stats{}
 	o.runFlushRequestMetadatas()
 
 	// it asserts that all flushers exit
@@ -478,6 +482,7 @@ func TestOutputFlushRequestMetadatasAbort(t *testing.T) {
 	}
 
 	o.requestMetadatasFlusher = flusherFunc(flusherMock)
+	o.requestMetadatasStats = &insightsOutput.Stats{}
 	o.runFlushRequestMetadatas()
 
 	// it asserts that all flushers exit
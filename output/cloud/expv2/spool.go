@@ -0,0 +1,176 @@
+package expv2
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+
+	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/output/cloud/expv2/pbcloud"
+)
+
+// spoolFileExt is the extension of the files with the spooled metric sets.
+const spoolFileExt = ".pbspool"
+
+// metricsSpool writes the metric sets that couldn't be pushed to the cloud
+// into a directory, so they can be pushed later with ReplaySpooledMetrics.
+//
+// Every metric set is written to its own file, as a big-endian uint32 length
+// followed by the Protobuf encoded message, so a replay that fails midway
+// can be resumed without pushing the same metrics twice.
+type metricsSpool struct {
+	fs        fsext.Fs
+	dir       string
+	testRunID string
+
+	seq     uint64
+	written uint64
+}
+
+func newMetricsSpool(fs fsext.Fs, dir, testRunID string) (*metricsSpool, error) {
+	if err := fs.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create the metrics spool directory: %w", err)
+	}
+	return &metricsSpool{fs: fs, dir: dir, testRunID: testRunID}, nil
+}
+
+// write writes the metric set to a new file in the spool directory and
+// returns its path. The name of the file starts with the test run ID and
+// sorts after the ones that were written before it.
+func (s *metricsSpool) write(ms *pbcloud.MetricSet) (string, error) {
+	b, err := proto.Marshal(ms)
+	if err != nil {
+		return "", fmt.Errorf("encoding metrics as Protobuf failed: %w", err)
+	}
+	if uint64(len(b)) > 0xffffffff {
+		return "", fmt.Errorf("the Protobuf message is too large to be spooled; size: %d", len(b))
+	}
+
+	seq := atomic.AddUint64(&s.seq, 1)
+	name := filepath.Join(s.dir, fmt.Sprintf("%s-%019d-%06d%s", s.testRunID, time.Now().UnixNano(), seq, spoolFileExt))
+
+	data := make([]byte, 4+len(b))
+	binary.BigEndian.PutUint32(data, uint32(len(b)))
+	copy(data[4:], b)
+
+	// The file is renamed only once it's complete, so a replay never
+	// reads a partially written one.
+	if err = fsext.WriteFile(s.fs, name+".tmp", data, 0o640); err != nil {
+		return "", err
+	}
+	if err = s.fs.Rename(name+".tmp", name); err != nil {
+		return "", err
+	}
+	atomic.AddUint64(&s.written, 1)
+	return name, nil
+}
+
+// count returns how many metric sets were spooled.
+func (s *metricsSpool) count() uint64 {
+	return atomic.LoadUint64(&s.written)
+}
+
+func readSpoolFile(fs fsext.Fs, name string) (*pbcloud.MetricSet, error) {
+	data, err := fsext.ReadFile(fs, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 4 || uint64(binary.BigEndian.Uint32(data)) != uint64(len(data)-4) {
+		return nil, fmt.Errorf("the spooled metrics file %s is truncated or corrupted", name)
+	}
+	ms := &pbcloud.MetricSet{}
+	if err = proto.Unmarshal(data[4:], ms); err != nil {
+		return nil, fmt.Errorf("decoding the spooled metrics file %s failed: %w", name, err)
+	}
+	return ms, nil
+}
+
+// spoolingPusher pushes the metric sets with the wrapped pusher and spools
+// the ones that failed to be pushed, instead of returning the error.
+type spoolingPusher struct {
+	pusher
+	spool  *metricsSpool
+	logger logrus.FieldLogger
+}
+
+func (p *spoolingPusher) push(samples *pbcloud.MetricSet) error {
+	err := p.pusher.push(samples)
+	// There is no point in spooling the metrics that the cloud refused for
+	// good, they would be refused when they are replayed too.
+	if err == nil || isMetricsRefusedError(err) {
+		return err
+	}
+
+	name, serr := p.spool.write(samples)
+	if serr != nil {
+		p.logger.WithError(serr).Error("Failed to spool the metrics that couldn't be pushed to the cloud")
+		return err
+	}
+	p.logger.WithError(err).WithField("file", name).Warn("Failed to push metrics to the cloud, spooled them to disk")
+	return nil
+}
+
+// ReplaySpooledMetrics pushes the metric sets that were spooled in dir for
+// the test run to the cloud, in the order in which they were spooled. Every
+// file is removed once it has been pushed, so a failed replay can be retried.
+// It returns how many metric sets were pushed.
+func ReplaySpooledMetrics(
+	logger logrus.FieldLogger, client *cloudapi.Client, fs fsext.Fs, dir, testRunID string,
+) (int, error) {
+	mc, err := newMetricsClient(client, testRunID)
+	if err != nil {
+		return 0, err
+	}
+
+	entries, err := fsext.ReadDir(fs, dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the metrics spool directory: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, testRunID+"-") && strings.HasSuffix(name, spoolFileExt) {
+			names = append(names, filepath.Join(dir, name))
+		}
+	}
+	if len(names) == 0 {
+		return 0, fmt.Errorf("there are no spooled metrics for the test run %s in %s", testRunID, dir)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		ms, err := readSpoolFile(fs, name)
+		if err != nil {
+			return i, err
+		}
+		if err = mc.push(ms); err != nil {
+			return i, fmt.Errorf("failed to push the spooled metrics file %s: %w", name, err)
+		}
+		if err = fs.Remove(name); err != nil {
+			return i + 1, fmt.Errorf("failed to remove the replayed metrics file %s: %w", name, err)
+		}
+		logger.WithField("file", name).Debug("Pushed the spooled metrics")
+	}
+	return len(names), nil
+}
+
+// isMetricsRefusedError returns whether the error means that the cloud
+// doesn't accept any more metrics for the test run.
+func isMetricsRefusedError(err error) bool {
+	var errResp cloudapi.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil {
+		return false
+	}
+	// The Cloud service returns the error code 4 when it doesn't accept any more metrics.
+	return errResp.Response.StatusCode == http.StatusForbidden && errResp.Code == 4
+}
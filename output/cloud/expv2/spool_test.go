@@ -0,0 +1,135 @@
+package expv2
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/output/cloud/expv2/pbcloud"
+)
+
+type pusherFunc func(*pbcloud.MetricSet) error
+
+func (f pusherFunc) push(ms *pbcloud.MetricSet) error { return f(ms) }
+
+func TestSpoolingPusher(t *testing.T) {
+	t.Parallel()
+
+	fs := fsext.NewMemMapFs()
+	spool, err := newMetricsSpool(fs, "/spool", "123")
+	require.NoError(t, err)
+
+	var pushErr error
+	p := &spoolingPusher{
+		pusher: pusherFunc(func(*pbcloud.MetricSet) error { return pushErr }),
+		spool:  spool,
+		logger: testutils.NewLogger(t),
+	}
+
+	// the pushed metrics aren't spooled
+	require.NoError(t, p.push(&pbcloud.MetricSet{TestRunId: "123"}))
+	assert.Zero(t, spool.count())
+
+	pushErr = errors.New("connection refused")
+	require.NoError(t, p.push(&pbcloud.MetricSet{TestRunId: "123", AggregationPeriod: 1}))
+	require.NoError(t, p.push(&pbcloud.MetricSet{TestRunId: "123", AggregationPeriod: 2}))
+	assert.EqualValues(t, 2, spool.count())
+
+	// the metrics that the cloud refused for good aren't spooled
+	pushErr = cloudapi.ErrorResponse{Response: &http.Response{StatusCode: http.StatusForbidden}, Code: 4}
+	assert.Equal(t, pushErr, p.push(&pbcloud.MetricSet{TestRunId: "123"}))
+	assert.EqualValues(t, 2, spool.count())
+
+	entries, err := fsext.ReadDir(fs, "/spool")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	for i, entry := range entries {
+		ms, err := readSpoolFile(fs, "/spool/"+entry.Name())
+		require.NoError(t, err)
+		assert.Equal(t, "123", ms.TestRunId)
+		assert.EqualValues(t, i+1, ms.AggregationPeriod)
+	}
+}
+
+func TestReadSpoolFileTruncated(t *testing.T) {
+	t.Parallel()
+
+	fs := fsext.NewMemMapFs()
+	require.NoError(t, fsext.WriteFile(fs, "/spool/123-1.pbspool", []byte{0, 0, 0, 10, 1, 2}, 0o644))
+	_, err := readSpoolFile(fs, "/spool/123-1.pbspool")
+	assert.ErrorContains(t, err, "truncated or corrupted")
+}
+
+func TestReplaySpooledMetrics(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		requests int
+		received []uint32
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Equal(t, "/v2/metrics/123", r.URL.Path)
+		requests++
+		if requests == 2 {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		b, err = snappy.Decode(nil, b)
+		require.NoError(t, err)
+		ms := &pbcloud.MetricSet{}
+		require.NoError(t, proto.Unmarshal(b, ms))
+		received = append(received, ms.AggregationPeriod)
+	}))
+	defer srv.Close()
+
+	fs := fsext.NewMemMapFs()
+	spool, err := newMetricsSpool(fs, "/spool", "123")
+	require.NoError(t, err)
+	for i := uint32(1); i <= 3; i++ {
+		_, err = spool.write(&pbcloud.MetricSet{TestRunId: "123", AggregationPeriod: i})
+		require.NoError(t, err)
+	}
+	other, err := newMetricsSpool(fs, "/spool", "456")
+	require.NoError(t, err)
+	_, err = other.write(&pbcloud.MetricSet{TestRunId: "456"})
+	require.NoError(t, err)
+
+	client := cloudapi.NewClient(testutils.NewLogger(t), "token", srv.URL, "1.0", time.Second)
+	logger := testutils.NewLogger(t)
+
+	// the first replay fails midway, so the second one pushes only the rest
+	pushed, err := ReplaySpooledMetrics(logger, client, fs, "/spool", "123")
+	require.Error(t, err)
+	assert.Equal(t, 1, pushed)
+
+	pushed, err = ReplaySpooledMetrics(logger, client, fs, "/spool", "123")
+	require.NoError(t, err)
+	assert.Equal(t, 2, pushed)
+	assert.Equal(t, []uint32{1, 2, 3}, received)
+
+	_, err = ReplaySpooledMetrics(logger, client, fs, "/spool", "123")
+	assert.ErrorContains(t, err, "there are no spooled metrics for the test run 123")
+
+	// the files of the other test runs are left alone
+	entries, err := fsext.ReadDir(fs, "/spool")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
@@ -0,0 +1,160 @@
+package insights
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/cloudapi"
+)
+
+// DropPolicy selects which request metadatas are dropped when the buffer is full.
+type DropPolicy string
+
+const (
+	// DropOldest drops the request metadatas that have been buffered for the
+	// longest time, for keeping the most recent ones.
+	DropOldest DropPolicy = "oldest"
+
+	// DropNewest drops the request metadatas that are collected while the
+	// buffer is full.
+	DropNewest DropPolicy = "newest"
+)
+
+// BufferConfig is the configuration for buffering the request metadatas
+// before sending them.
+type BufferConfig struct {
+	// MaxQueued is the maximum number of request metadatas waiting to be
+	// sent, 0 means no limit.
+	MaxQueued int
+
+	// DropPolicy selects which request metadatas are dropped when there are
+	// already MaxQueued of them.
+	DropPolicy DropPolicy
+
+	// MaxBatchBytes is the maximum size of a batch of request metadatas sent
+	// at once, 0 means no limit. When the queued ones reach it, they are ready
+	// to be flushed without waiting for the next push interval.
+	MaxBatchBytes int
+}
+
+// NewBufferConfig creates the BufferConfig from the cloud config.
+func NewBufferConfig(config cloudapi.Config) (BufferConfig, error) {
+	bc := BufferConfig{
+		MaxQueued:     int(config.TracesBufferSize.Int64),
+		DropPolicy:    DropPolicy(config.TracesDropPolicy.String),
+		MaxBatchBytes: int(config.TracesBatchSize.Int64),
+	}
+	if bc.DropPolicy == "" {
+		bc.DropPolicy = DropOldest
+	}
+	if bc.DropPolicy != DropOldest && bc.DropPolicy != DropNewest {
+		return bc, fmt.Errorf("invalid traces drop policy %q, it must be %q or %q", bc.DropPolicy, DropOldest, DropNewest)
+	}
+	if bc.MaxQueued < 0 || bc.MaxBatchBytes < 0 {
+		return bc, errors.New("the traces buffer size and batch size can't be negative")
+	}
+
+	return bc, nil
+}
+
+// Stats counts the request metadatas going through the collector and the
+// flusher, for making visible the backpressure from the ingester.
+// It is expected to be used concurrently.
+type Stats struct {
+	queued  int64
+	dropped uint64
+	sent    uint64
+	failed  uint64
+
+	// the dropped and failed counts when the stats were last logged by LogFlush
+	loggedDropped uint64
+	loggedFailed  uint64
+}
+
+// Queued returns the number of the request metadatas waiting to be sent.
+func (s *Stats) Queued() int64 {
+	return atomic.LoadInt64(&s.queued)
+}
+
+// Dropped returns the number of the request metadatas dropped since the buffer was full.
+func (s *Stats) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Sent returns the number of the request metadatas successfully sent.
+func (s *Stats) Sent() uint64 {
+	return atomic.LoadUint64(&s.sent)
+}
+
+// Failed returns the number of the request metadatas that failed to be sent.
+func (s *Stats) Failed() uint64 {
+	return atomic.LoadUint64(&s.failed)
+}
+
+// The updates are no-ops on nil stats, so a Collector or a Flusher doesn't
+// require them.
+
+func (s *Stats) setQueued(n int) {
+	if s != nil {
+		atomic.StoreInt64(&s.queued, int64(n))
+	}
+}
+
+func (s *Stats) addDropped(n int) {
+	if s != nil {
+		atomic.AddUint64(&s.dropped, uint64(n))
+	}
+}
+
+func (s *Stats) addSent(n int) {
+	if s != nil {
+		atomic.AddUint64(&s.sent, uint64(n))
+	}
+}
+
+func (s *Stats) addFailed(n int) {
+	if s != nil {
+		atomic.AddUint64(&s.failed, uint64(n))
+	}
+}
+
+// Fields returns the stats as log fields.
+func (s *Stats) Fields() logrus.Fields {
+	return logrus.Fields{
+		"queued":  s.Queued(),
+		"dropped": s.Dropped(),
+		"sent":    s.Sent(),
+		"failed":  s.Failed(),
+	}
+}
+
+// LogFlush logs the stats after a flush, with a warning if any of the request
+// metadatas was dropped or failed to be sent since the previous one, so the
+// backpressure from the ingester is visible while the test is running.
+func (s *Stats) LogFlush(logger logrus.FieldLogger) {
+	dropped, failed := s.Dropped(), s.Failed()
+	newDropped := dropped - atomic.SwapUint64(&s.loggedDropped, dropped)
+	newFailed := failed - atomic.SwapUint64(&s.loggedFailed, failed)
+
+	logger = logger.WithFields(s.Fields())
+	if newDropped > 0 || newFailed > 0 {
+		logger.Warnf("%d traces were dropped since the buffer was full and %d failed to be sent "+
+			"since the previous flush", newDropped, newFailed)
+		return
+	}
+	logger.Debug("Successfully flushed buffered trace samples to the cloud")
+}
+
+// LogSummary logs the stats at the end of the test, with a warning if any of
+// the request metadatas was dropped or failed to be sent.
+func (s *Stats) LogSummary(logger logrus.FieldLogger) {
+	logger = logger.WithFields(s.Fields())
+	if s.Dropped() > 0 || s.Failed() > 0 {
+		logger.Warn("Some traces couldn't be sent to the cloud, since the buffer was full or pushing them failed")
+		return
+	}
+	logger.Debug("All the traces have been sent to the cloud")
+}
@@ -0,0 +1,87 @@
+package insights
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/lib/testutils"
+)
+
+func TestNewBufferConfig(t *testing.T) {
+	t.Parallel()
+
+	bc, err := NewBufferConfig(cloudapi.Config{})
+	require.NoError(t, err)
+	require.Equal(t, BufferConfig{DropPolicy: DropOldest}, bc)
+
+	bc, err = NewBufferConfig(cloudapi.Config{
+		TracesBufferSize: null.IntFrom(10),
+		TracesDropPolicy: null.StringFrom("newest"),
+		TracesBatchSize:  null.IntFrom(1024),
+	})
+	require.NoError(t, err)
+	require.Equal(t, BufferConfig{MaxQueued: 10, DropPolicy: DropNewest, MaxBatchBytes: 1024}, bc)
+
+	_, err = NewBufferConfig(cloudapi.Config{TracesDropPolicy: null.StringFrom("random")})
+	require.ErrorContains(t, err, `invalid traces drop policy "random"`)
+
+	_, err = NewBufferConfig(cloudapi.Config{TracesBufferSize: null.IntFrom(-1)})
+	require.ErrorContains(t, err, "can't be negative")
+}
+
+func TestStatsLogSummary(t *testing.T) {
+	t.Parallel()
+
+	logger, hook := testutils.NewLoggerWithHook(t)
+	stats := &Stats{sent: 2}
+	stats.LogSummary(logger)
+	entries := hook.Drain()
+	require.Len(t, entries, 1)
+	require.Equal(t, "All the traces have been sent to the cloud", entries[0].Message)
+
+	stats.dropped = 1
+	stats.LogSummary(logger)
+	entries = hook.Drain()
+	require.Len(t, entries, 1)
+	require.Contains(t, entries[0].Message, "Some traces couldn't be sent")
+	require.Equal(t, uint64(1), entries[0].Data["dropped"])
+	require.Equal(t, uint64(2), entries[0].Data["sent"])
+}
+
+func TestStatsLogFlush(t *testing.T) {
+	t.Parallel()
+
+	logger, hook := testutils.NewLoggerWithHook(t)
+	stats := &Stats{sent: 2}
+	stats.LogFlush(logger)
+	entries := hook.Drain()
+	require.Len(t, entries, 1)
+	require.Equal(t, logrus.DebugLevel, entries[0].Level)
+
+	stats.addDropped(3)
+	stats.addFailed(1)
+	stats.LogFlush(logger)
+	entries = hook.Drain()
+	require.Len(t, entries, 1)
+	require.Equal(t, logrus.WarnLevel, entries[0].Level)
+	require.Contains(t, entries[0].Message, "3 traces were dropped")
+	require.Contains(t, entries[0].Message, "1 failed to be sent")
+
+	// only the new drops and failures are warned about
+	stats.addFailed(2)
+	stats.LogFlush(logger)
+	entries = hook.Drain()
+	require.Len(t, entries, 1)
+	require.Contains(t, entries[0].Message, "0 traces were dropped")
+	require.Contains(t, entries[0].Message, "2 failed to be sent")
+	require.Equal(t, uint64(3), entries[0].Data["failed"])
+
+	stats.LogFlush(logger)
+	entries = hook.Drain()
+	require.Len(t, entries, 1)
+	require.Equal(t, logrus.DebugLevel, entries[0].Level)
+}
@@ -19,10 +19,12 @@ const (
 )
 
 // RequestMetadatasCollector is an interface for collecting request metadatas
-// and retrieving them, so they can be flushed using a flusher.
+// and retrieving them, so they can be flushed using a flusher. PopAll returns
+// the sizes in bytes of the request metadatas too, if they were computed while
+// collecting them, otherwise nil.
 type RequestMetadatasCollector interface {
 	CollectRequestMetadatas([]metrics.SampleContainer)
-	PopAll() insights.RequestMetadatas
+	PopAll() (insights.RequestMetadatas, []int)
 }
 
 // Collector is an implementation of RequestMetadatasCollector.
@@ -32,17 +34,42 @@ type Collector struct {
 	testRunID int64
 	buffer    insights.RequestMetadatas
 	bufferMu  *sync.Mutex
+
+	config BufferConfig
+	stats  *Stats
+
+	// bufferSizes are the sizes in bytes of the buffered request metadatas,
+	// they are tracked only if there is a maximum size of the batches.
+	bufferSizes []int
+	bufferBytes int
+	ready       chan struct{}
 }
 
 // NewCollector creates a new Collector.
 func NewCollector(testRunID int64) *Collector {
+	return NewBoundedCollector(testRunID, BufferConfig{}, &Stats{})
+}
+
+// NewBoundedCollector creates a new Collector which buffers the request
+// metadatas as configured, and counts them in the stats.
+func NewBoundedCollector(testRunID int64, config BufferConfig, stats *Stats) *Collector {
 	return &Collector{
 		testRunID: testRunID,
 		buffer:    nil,
 		bufferMu:  &sync.Mutex{},
+		config:    config,
+		stats:     stats,
+		ready:     make(chan struct{}, 1),
 	}
 }
 
+// BatchReady returns a channel which receives a value when the buffered
+// request metadatas reach the maximum size of a batch, so they can be
+// flushed without waiting for the next push interval.
+func (c *Collector) BatchReady() <-chan struct{} {
+	return c.ready
+}
+
 // CollectRequestMetadatas filters httpext.Trail samples containing trace ids and stores them as
 // insights.RequestMetadatas in the buffer.
 func (c *Collector) CollectRequestMetadatas(sampleContainers []metrics.SampleContainer) {
@@ -86,20 +113,74 @@ func (c *Collector) CollectRequestMetadatas(sampleContainers []metrics.SampleCon
 		return
 	}
 
+	var newSizes []int
+	if c.config.MaxBatchBytes > 0 {
+		newSizes = make([]int, 0, len(newBuffer))
+		for _, m := range newBuffer {
+			newSizes = append(newSizes, insights.RequestMetadataSize(m))
+		}
+	}
+
 	c.bufferMu.Lock()
 	defer c.bufferMu.Unlock()
 
 	c.buffer = append(c.buffer, newBuffer...)
+	c.bufferSizes = append(c.bufferSizes, newSizes...)
+	for _, size := range newSizes {
+		c.bufferBytes += size
+	}
+	c.dropOverflow()
+	c.stats.setQueued(len(c.buffer))
+
+	if c.config.MaxBatchBytes > 0 && c.bufferBytes >= c.config.MaxBatchBytes {
+		select {
+		case c.ready <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// dropOverflow drops the buffered request metadatas over the maximum, by the
+// drop policy. It must be called with the buffer locked.
+func (c *Collector) dropOverflow() {
+	overflow := len(c.buffer) - c.config.MaxQueued
+	if c.config.MaxQueued == 0 || overflow <= 0 {
+		return
+	}
+
+	var droppedSizes []int
+	if c.config.DropPolicy == DropNewest {
+		c.buffer = c.buffer[:c.config.MaxQueued]
+		if c.bufferSizes != nil {
+			droppedSizes = c.bufferSizes[c.config.MaxQueued:]
+			c.bufferSizes = c.bufferSizes[:c.config.MaxQueued]
+		}
+	} else {
+		c.buffer = c.buffer[overflow:]
+		if c.bufferSizes != nil {
+			droppedSizes = c.bufferSizes[:overflow]
+			c.bufferSizes = c.bufferSizes[overflow:]
+		}
+	}
+	for _, size := range droppedSizes {
+		c.bufferBytes -= size
+	}
+
+	c.stats.addDropped(overflow)
 }
 
-// PopAll returns all collected insights.RequestMetadatas and clears the buffer.
-func (c *Collector) PopAll() insights.RequestMetadatas {
+// PopAll returns all collected insights.RequestMetadatas, with their sizes if
+// there is a maximum size of the batches, and clears the buffer.
+func (c *Collector) PopAll() (insights.RequestMetadatas, []int) {
 	c.bufferMu.Lock()
 	defer c.bufferMu.Unlock()
 
-	b := c.buffer
+	b, sizes := c.buffer, c.bufferSizes
 	c.buffer = nil
-	return b
+	c.bufferSizes = nil
+	c.bufferBytes = 0
+	c.stats.setQueued(0)
+	return b, sizes
 }
 
 func (c *Collector) getStringTagFromTrail(trail *httpext.Trail, key string) string {
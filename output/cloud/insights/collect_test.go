@@ -139,10 +139,107 @@ func Test_Collector_PopAll_DoesNothingWithEmptyData(t *testing.T) {
 	}
 
 	// When
-	got := col.PopAll()
+	got, _ := col.PopAll()
 
 	// Then
 	require.Nil(t, col.buffer)
 	require.Empty(t, col.buffer)
 	require.Equal(t, data, got)
 }
+
+func newTracedTrails(traceIDs ...string) []metrics.SampleContainer {
+	data := make([]metrics.SampleContainer, 0, len(traceIDs))
+	for _, traceID := range traceIDs {
+		data = append(data, &httpext.Trail{
+			EndTime:  time.Unix(10, 0),
+			Duration: time.Second,
+			Tags:     metrics.NewRegistry().RootTagSet().With(methodTag, "GET"),
+			Metadata: map[string]string{metadataTraceIDKey: traceID},
+		})
+	}
+	return data
+}
+
+func traceIDsOf(rms insights.RequestMetadatas) []string {
+	ids := make([]string, 0, len(rms))
+	for _, rm := range rms {
+		ids = append(ids, rm.TraceID)
+	}
+	return ids
+}
+
+func Test_Collector_CollectRequestMetadatas_DropsTheOldestWhenFull(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	stats := &Stats{}
+	col := NewBoundedCollector(1337, BufferConfig{MaxQueued: 2, DropPolicy: DropOldest}, stats)
+
+	// When
+	col.CollectRequestMetadatas(newTracedTrails("id-1", "id-2"))
+	col.CollectRequestMetadatas(newTracedTrails("id-3"))
+
+	// Then
+	require.Equal(t, []string{"id-2", "id-3"}, traceIDsOf(col.buffer))
+	require.Equal(t, int64(2), stats.Queued())
+	require.Equal(t, uint64(1), stats.Dropped())
+}
+
+func Test_Collector_CollectRequestMetadatas_DropsTheNewestWhenFull(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	stats := &Stats{}
+	col := NewBoundedCollector(1337, BufferConfig{MaxQueued: 2, DropPolicy: DropNewest}, stats)
+
+	// When
+	col.CollectRequestMetadatas(newTracedTrails("id-1"))
+	col.CollectRequestMetadatas(newTracedTrails("id-2", "id-3", "id-4"))
+
+	// Then
+	require.Equal(t, []string{"id-1", "id-2"}, traceIDsOf(col.buffer))
+	require.Equal(t, int64(2), stats.Queued())
+	require.Equal(t, uint64(2), stats.Dropped())
+
+	col.PopAll()
+	require.Equal(t, int64(0), stats.Queued())
+}
+
+func Test_Collector_CollectRequestMetadatas_SignalsWhenABatchIsReady(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	size := insights.RequestMetadataSize(insights.RequestMetadata{
+		TraceID:        "id-1",
+		Start:          time.Unix(9, 0),
+		End:            time.Unix(10, 0),
+		TestRunLabels:  insights.TestRunLabels{ID: 1337},
+		ProtocolLabels: insights.ProtocolHTTPLabels{Method: "GET"},
+	})
+	require.Positive(t, size)
+	col := NewBoundedCollector(1337, BufferConfig{MaxBatchBytes: 2 * size}, &Stats{})
+
+	// When
+	col.CollectRequestMetadatas(newTracedTrails("id-1"))
+
+	// Then
+	select {
+	case <-col.BatchReady():
+		t.Fatal("the batch shouldn't be ready before reaching the maximum size")
+	default:
+	}
+
+	col.CollectRequestMetadatas(newTracedTrails("id-2"))
+	select {
+	case <-col.BatchReady():
+	default:
+		t.Fatal("the batch should be ready after reaching the maximum size")
+	}
+	require.Equal(t, 2*size, col.bufferBytes)
+
+	got, sizes := col.PopAll()
+	require.Len(t, got, 2)
+	require.Equal(t, []int{size, size}, sizes)
+	require.Zero(t, col.bufferBytes)
+	require.Empty(t, col.bufferSizes)
+}
@@ -1,18 +1,54 @@
 package insights
 
 import (
+	"context"
+	"fmt"
+
 	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/cloudapi/insights"
+)
+
+const (
+	// ExporterInsights is the exporter sending the request metadatas to the
+	// k6 Insights backend service.
+	ExporterInsights = "insights"
+
+	// ExporterOTLP is the exporter sending the request metadatas as spans to
+	// an OpenTelemetry collector.
+	ExporterOTLP = "otlp"
 )
 
 // Enabled returns true if the k6 x Tempo feature is enabled.
+// The cloud output disables it in the config if the capabilities of the test
+// run say that the organization isn't eligible for it.
 func Enabled(config cloudapi.Config) bool {
-	// TODO(lukasz): Check if k6 x Tempo is enabled
-	//
-	// We want to check whether a given organization is
-	// eligible for k6 x Tempo feature. If it isn't, we may
-	// consider to skip the traces output.
-	//
-	// We currently don't have a backend API to check this
-	// information.
 	return config.TracesEnabled.ValueOrZero()
 }
+
+// DialClient is a Client which has to be connected before using it.
+type DialClient interface {
+	Client
+	Dial(context.Context) error
+}
+
+// NewClient creates the client of the exporter selected by the config.
+func NewClient(config cloudapi.Config, testRunID int64) (DialClient, error) {
+	switch config.TracesExporter.String {
+	case "", ExporterInsights:
+		insightsClientConfig := insights.NewDefaultClientConfigForTestRun(
+			config.TracesHost.String,
+			config.Token.String,
+			testRunID,
+		)
+		return insights.NewClient(insightsClientConfig), nil
+	case ExporterOTLP:
+		client, err := NewOTLPClient(config.TracesOTLPOutput.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the OTLP insights exporter: %w", err)
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("invalid insights exporter %q, it must be %q or %q",
+			config.TracesExporter.String, ExporterInsights, ExporterOTLP)
+	}
+}
@@ -21,24 +21,83 @@ type RequestMetadatasFlusher interface {
 // Its purpose is to retrieve data from a collector
 // and send it to the insights backend.
 type Flusher struct {
-	client    Client
-	collector RequestMetadatasCollector
+	client        Client
+	collector     RequestMetadatasCollector
+	maxBatchBytes int
+	stats         *Stats
 }
 
 // NewFlusher creates a new Flusher.
 func NewFlusher(client Client, collector RequestMetadatasCollector) *Flusher {
+	return NewBatchingFlusher(client, collector, 0, &Stats{})
+}
+
+// NewBatchingFlusher creates a new Flusher which sends the request metadatas
+// in batches of at most maxBatchBytes, 0 means no limit, and counts them in
+// the stats.
+func NewBatchingFlusher(
+	client Client, collector RequestMetadatasCollector, maxBatchBytes int, stats *Stats,
+) *Flusher {
 	return &Flusher{
-		client:    client,
-		collector: collector,
+		client:        client,
+		collector:     collector,
+		maxBatchBytes: maxBatchBytes,
+		stats:         stats,
 	}
 }
 
 // Flush retrieves data from the collector and sends it to the insights backend.
 func (f *Flusher) Flush() error {
-	requestMetadatas := f.collector.PopAll()
+	requestMetadatas, sizes := f.collector.PopAll()
 	if len(requestMetadatas) < 1 {
 		return nil
 	}
 
-	return f.client.IngestRequestMetadatasBatch(context.Background(), requestMetadatas)
+	batches := splitBatches(requestMetadatas, sizes, f.maxBatchBytes)
+	for i, batch := range batches {
+		if err := f.client.IngestRequestMetadatasBatch(context.Background(), batch); err != nil {
+			// The batches that haven't been sent are lost.
+			var failed int
+			for _, b := range batches[i:] {
+				failed += len(b)
+			}
+			f.stats.addFailed(failed)
+			return err
+		}
+		f.stats.addSent(len(batch))
+	}
+
+	return nil
+}
+
+// splitBatches splits the request metadatas in batches of at most maxBytes,
+// every batch has at least one request metadata even if it's bigger. The sizes
+// of the request metadatas are computed, unless they are already known.
+func splitBatches(
+	requestMetadatas insights.RequestMetadatas, sizes []int, maxBytes int,
+) []insights.RequestMetadatas {
+	if maxBytes <= 0 {
+		return []insights.RequestMetadatas{requestMetadatas}
+	}
+	if len(sizes) != len(requestMetadatas) {
+		sizes = make([]int, len(requestMetadatas))
+		for i, rm := range requestMetadatas {
+			sizes[i] = insights.RequestMetadataSize(rm)
+		}
+	}
+
+	var (
+		batches []insights.RequestMetadatas
+		start   int
+		size    int
+	)
+	for i, rmSize := range sizes {
+		if i > start && size+rmSize > maxBytes {
+			batches = append(batches, requestMetadatas[start:i])
+			start, size = i, 0
+		}
+		size += rmSize
+	}
+
+	return append(batches, requestMetadatas[start:])
 }
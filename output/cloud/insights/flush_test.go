@@ -3,12 +3,19 @@ package insights
 import (
 	"context"
 	"errors"
+	"net"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 
 	"go.k6.io/k6/cloudapi/insights"
+	"go.k6.io/k6/cloudapi/insights/proto/v1/ingester"
 	"go.k6.io/k6/metrics"
 )
 
@@ -57,8 +64,8 @@ func (m *mockRequestMetadatasCollector) CollectRequestMetadatas(_ []metrics.Samp
 	panic("implement me")
 }
 
-func (m *mockRequestMetadatasCollector) PopAll() insights.RequestMetadatas {
-	return m.data
+func (m *mockRequestMetadatasCollector) PopAll() (insights.RequestMetadatas, []int) {
+	return m.data, nil
 }
 
 func newMockRequestMetadatas() insights.RequestMetadatas {
@@ -134,3 +141,123 @@ func Test_tracesFlusher_Flush_ReturnsErrorWithFailingInsightsClientAndNonCancell
 	// Then
 	require.ErrorIs(t, err, testErr)
 }
+
+func Test_splitBatches_SplitsByTheMaximumSize(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	data := append(newMockRequestMetadatas(), newMockRequestMetadatas()...)
+	size := insights.RequestMetadataSize(data[0])
+	require.Positive(t, size)
+
+	// Then
+	require.Equal(t, []insights.RequestMetadatas{data}, splitBatches(data, nil, 0))
+	require.Equal(t, []insights.RequestMetadatas{data[:2], data[2:]}, splitBatches(data, nil, 2*size+1))
+	// a request metadata bigger than the maximum is sent alone
+	require.Len(t, splitBatches(data, nil, 1), 4)
+
+	// the known sizes are used instead of computing them again
+	sizes := []int{1, 1, 3, 1}
+	require.Equal(t, []insights.RequestMetadatas{data[:2], data[2:3], data[3:]}, splitBatches(data, sizes, 2))
+}
+
+type mockRecordingIngesterServer struct {
+	ingester.UnimplementedIngesterServiceServer
+
+	mu      sync.Mutex
+	batches []int
+	failing bool
+}
+
+func (s *mockRecordingIngesterServer) BatchCreateRequestMetadatas(
+	_ context.Context, req *ingester.BatchCreateRequestMetadatasRequest,
+) (*ingester.BatchCreateRequestMetadatasResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failing {
+		return nil, status.Error(codes.InvalidArgument, "invalid")
+	}
+	s.batches = append(s.batches, len(req.Requests))
+	return &ingester.BatchCreateRequestMetadatasResponse{}, nil
+}
+
+func (s *mockRecordingIngesterServer) receivedBatches() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.batches
+}
+
+func newInProcessInsightsClient(t *testing.T, ser ingester.IngesterServiceServer) *insights.Client {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() { _ = lis.Close() })
+
+	s := grpc.NewServer()
+	ingester.RegisterIngesterServiceServer(s, ser)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	cli := insights.NewClient(insights.ClientConfig{
+		Timeout: 1 * time.Second,
+		ConnectConfig: insights.ClientConnectConfig{
+			Timeout: 1 * time.Second,
+			Dialer: func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			},
+		},
+		TLSConfig:   insights.ClientTLSConfig{Insecure: true},
+		RetryConfig: insights.ClientRetryConfig{RetryableStatusCodes: `"UNAVAILABLE"`},
+	})
+	require.NoError(t, cli.Dial(context.Background()))
+	t.Cleanup(func() { _ = cli.Close() })
+
+	return cli
+}
+
+func Test_tracesFlusher_Flush_SendsBatchesToTheIngester(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ser := &mockRecordingIngesterServer{}
+	cli := newInProcessInsightsClient(t, ser)
+
+	data := append(newMockRequestMetadatas(), newMockRequestMetadatas()...)
+	data = append(data, newMockRequestMetadatas()[0])
+	size := insights.RequestMetadataSize(data[0])
+	stats := &Stats{}
+	flusher := NewBatchingFlusher(cli, &mockRequestMetadatasCollector{data: data}, 2*size+1, stats)
+
+	// When
+	err := flusher.Flush()
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 2, 1}, ser.receivedBatches())
+	require.Equal(t, uint64(5), stats.Sent())
+	require.Zero(t, stats.Failed())
+}
+
+func Test_tracesFlusher_Flush_CountsTheFailedWithFailingIngester(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ser := &mockRecordingIngesterServer{failing: true}
+	cli := newInProcessInsightsClient(t, ser)
+
+	data := append(newMockRequestMetadatas(), newMockRequestMetadatas()...)
+	size := insights.RequestMetadataSize(data[0])
+	stats := &Stats{}
+	flusher := NewBatchingFlusher(cli, &mockRequestMetadatasCollector{data: data}, 2*size+1, stats)
+
+	// When
+	err := flusher.Flush()
+
+	// Then
+	require.Error(t, err)
+	require.Empty(t, ser.receivedBatches())
+	require.Zero(t, stats.Sent())
+	require.Equal(t, uint64(4), stats.Failed())
+}
@@ -0,0 +1,134 @@
+package insights
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"go.k6.io/k6/cloudapi/insights"
+	"go.k6.io/k6/lib/consts"
+	"go.k6.io/k6/lib/trace"
+)
+
+// otlpScopeName is the name of the instrumentation scope of the spans.
+const otlpScopeName = "k6/insights"
+
+// OTLPClient is an implementation of Client which sends the request metadatas
+// as spans to an OpenTelemetry collector, instead of the insights backend.
+type OTLPClient struct {
+	client otlptrace.Client
+}
+
+// NewOTLPClient creates a new OTLPClient for the collector specified by the
+// config line, in the same format of the otel traces output. If it's empty,
+// the default collector of the otel traces output is used.
+func NewOTLPClient(configLine string) (*OTLPClient, error) {
+	if configLine == "" {
+		configLine = "otel"
+	}
+	client, err := trace.ClientFromConfigLine(configLine)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OTLPClient{client: client}, nil
+}
+
+// Dial connects to the collector.
+func (c *OTLPClient) Dial(ctx context.Context) error {
+	return c.client.Start(ctx)
+}
+
+// IngestRequestMetadatasBatch sends the request metadatas to the collector as spans.
+func (c *OTLPClient) IngestRequestMetadatasBatch(ctx context.Context, requestMetadatas insights.RequestMetadatas) error {
+	spans := make([]*tracepb.Span, 0, len(requestMetadatas))
+	for _, rm := range requestMetadatas {
+		span, err := newSpan(rm)
+		if err != nil {
+			return fmt.Errorf("failed to create span: %w", err)
+		}
+
+		spans = append(spans, span)
+	}
+
+	return c.client.UploadTraces(ctx, []*tracepb.ResourceSpans{{
+		Resource: &resourcepb.Resource{
+			Attributes: []*commonpb.KeyValue{stringAttribute("service.name", "k6")},
+		},
+		ScopeSpans: []*tracepb.ScopeSpans{{
+			Scope: &commonpb.InstrumentationScope{Name: otlpScopeName, Version: consts.Version},
+			Spans: spans,
+		}},
+	}})
+}
+
+// Close closes the connection to the collector.
+func (c *OTLPClient) Close() error {
+	return c.client.Stop(context.Background())
+}
+
+func newSpan(rm insights.RequestMetadata) (*tracepb.Span, error) {
+	traceID, err := hex.DecodeString(rm.TraceID)
+	if err != nil || len(traceID) != 16 {
+		return nil, fmt.Errorf("invalid trace ID %q", rm.TraceID)
+	}
+
+	// The span ID of the request isn't known, the traced services have it as
+	// the parent of their spans, so this span is a new one in the same trace.
+	spanID := make([]byte, 8)
+	if _, err := rand.Read(spanID); err != nil {
+		return nil, fmt.Errorf("failed to generate the span ID: %w", err)
+	}
+
+	span := &tracepb.Span{
+		TraceId:           traceID,
+		SpanId:            spanID,
+		Kind:              tracepb.Span_SPAN_KIND_CLIENT,
+		StartTimeUnixNano: uint64(rm.Start.UnixNano()),
+		EndTimeUnixNano:   uint64(rm.End.UnixNano()),
+		Attributes: []*commonpb.KeyValue{
+			intAttribute("k6.test_run_id", rm.TestRunLabels.ID),
+			stringAttribute("k6.scenario", rm.TestRunLabels.Scenario),
+			stringAttribute("k6.group", rm.TestRunLabels.Group),
+		},
+	}
+
+	// TODO(other-proto-support): Set other protocol attributes.
+	switch l := rm.ProtocolLabels.(type) {
+	case insights.ProtocolHTTPLabels:
+		span.Name = "HTTP " + l.Method
+		span.Attributes = append(span.Attributes,
+			stringAttribute("http.method", l.Method),
+			stringAttribute("http.url", l.URL),
+			intAttribute("http.status_code", l.StatusCode),
+		)
+		if l.StatusCode >= 400 {
+			span.Status = &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR}
+		}
+	default:
+		return nil, errors.New("unknown protocol labels type")
+	}
+
+	return span, nil
+}
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+func intAttribute(key string, value int64) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: value}},
+	}
+}
@@ -0,0 +1,118 @@
+package insights
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/cloudapi/insights"
+)
+
+func TestOTLPClientIngestRequestMetadatasBatch(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan *coltracepb.ExportTraceServiceRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		req := &coltracepb.ExportTraceServiceRequest{}
+		require.NoError(t, proto.Unmarshal(body, req))
+		received <- req
+
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client, err := NewOTLPClient("otel=" + srv.URL + "/v1/traces")
+	require.NoError(t, err)
+	require.NoError(t, client.Dial(context.Background()))
+
+	traceID := "0123456789abcdef0123456789abcdef"
+	err = client.IngestRequestMetadatasBatch(context.Background(), insights.RequestMetadatas{{
+		TraceID:        traceID,
+		Start:          time.Unix(1337, 0),
+		End:            time.Unix(1338, 0),
+		TestRunLabels:  insights.TestRunLabels{ID: 1, Scenario: "default", Group: "::group"},
+		ProtocolLabels: insights.ProtocolHTTPLabels{URL: "https://k6.io", Method: "GET", StatusCode: 404},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, client.Close())
+
+	req := <-received
+	require.Len(t, req.ResourceSpans, 1)
+	require.Len(t, req.ResourceSpans[0].ScopeSpans, 1)
+	assert.Equal(t, otlpScopeName, req.ResourceSpans[0].ScopeSpans[0].Scope.Name)
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+	assert.Equal(t, traceID, hex.EncodeToString(span.TraceId))
+	assert.Len(t, span.SpanId, 8)
+	assert.Equal(t, "HTTP GET", span.Name)
+	assert.Equal(t, tracepb.Span_SPAN_KIND_CLIENT, span.Kind)
+	assert.Equal(t, uint64(time.Unix(1337, 0).UnixNano()), span.StartTimeUnixNano)
+	assert.Equal(t, uint64(time.Unix(1338, 0).UnixNano()), span.EndTimeUnixNano)
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, span.Status.Code)
+
+	attributes := make(map[string]any)
+	for _, kv := range span.Attributes {
+		switch v := kv.Value.Value.(type) {
+		case *commonpb.AnyValue_StringValue:
+			attributes[kv.Key] = v.StringValue
+		case *commonpb.AnyValue_IntValue:
+			attributes[kv.Key] = v.IntValue
+		}
+	}
+	assert.Equal(t, map[string]any{
+		"k6.test_run_id":   int64(1),
+		"k6.scenario":      "default",
+		"k6.group":         "::group",
+		"http.method":      "GET",
+		"http.url":         "https://k6.io",
+		"http.status_code": int64(404),
+	}, attributes)
+}
+
+func TestOTLPClientInvalidTraceID(t *testing.T) {
+	t.Parallel()
+
+	_, err := newSpan(newMockRequestMetadatas()[0])
+	require.ErrorContains(t, err, `invalid trace ID "test-trace-id-1"`)
+}
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient(cloudapi.Config{}, 1)
+	require.NoError(t, err)
+	assert.IsType(t, &insights.Client{}, client)
+
+	client, err = NewClient(cloudapi.Config{TracesExporter: null.StringFrom(ExporterOTLP)}, 1)
+	require.NoError(t, err)
+	assert.IsType(t, &OTLPClient{}, client)
+
+	_, err = NewClient(cloudapi.Config{
+		TracesExporter:   null.StringFrom(ExporterOTLP),
+		TracesOTLPOutput: null.StringFrom("otel=ftp://localhost"),
+	}, 1)
+	assert.ErrorContains(t, err, "invalid URL scheme")
+
+	_, err = NewClient(cloudapi.Config{TracesExporter: null.StringFrom("jaeger")}, 1)
+	assert.ErrorContains(t, err, `invalid insights exporter "jaeger"`)
+}
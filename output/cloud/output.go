@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -13,9 +14,11 @@ import (
 	"go.k6.io/k6/errext"
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/consts"
+	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/metrics"
 	"go.k6.io/k6/output"
 	cloudv2 "go.k6.io/k6/output/cloud/expv2"
+	insightsOutput "go.k6.io/k6/output/cloud/insights"
 	cloudv1 "go.k6.io/k6/output/cloud/v1"
 	"gopkg.in/guregu/null.v3"
 )
@@ -23,6 +26,10 @@ import (
 // TestName is the default k6 Cloud test name
 const TestName = "k6 test"
 
+// offlineTestRunID is the test run ID of the metrics written to
+// cloudapi.Config.MetricsDir, if PushRefID isn't set.
+const offlineTestRunID = "offline"
+
 // versionedOutput represents an output implementing
 // metrics samples aggregation and flushing to the
 // Cloud remote service.
@@ -62,6 +69,10 @@ type Output struct {
 
 	client       *cloudapi.Client
 	testStopFunc func(error)
+	fs           fsext.Fs
+
+	// eventsWg waits for the events being sent for the annotations.
+	eventsWg sync.WaitGroup
 }
 
 // Verify that Output implements the wanted interfaces
@@ -132,6 +143,7 @@ func newOutput(params output.Params) (*Output, error) {
 		executionPlan: params.ExecutionPlan,
 		duration:      int64(duration / time.Second),
 		logger:        logger,
+		fs:            params.FS,
 	}, nil
 }
 
@@ -161,6 +173,9 @@ func validateRequiredSystemTags(scriptTags *metrics.SystemTagSet) error {
 // Start calls the k6 Cloud API to initialize the test run, and then starts the
 // goroutine that would listen for metric samples and send them to the cloud.
 func (out *Output) Start() error {
+	if out.config.MetricsDir.String != "" {
+		return out.startOffline()
+	}
 	if out.config.PushRefID.Valid {
 		out.testRunID = out.config.PushRefID.String
 		out.logger.WithField("testRunId", out.testRunID).Debug("Directly pushing metrics without init")
@@ -194,6 +209,7 @@ func (out *Output) Start() error {
 		}).Debug("overriding config options")
 		out.config = out.config.Apply(*response.ConfigOverride)
 	}
+	out.applyCapabilities(response.Capabilities)
 
 	err = out.startVersionedOutput()
 	if err != nil {
@@ -209,11 +225,88 @@ func (out *Output) Start() error {
 	return nil
 }
 
+// startOffline starts the versioned output without initializing a test run
+// in the cloud, for writing the metrics to cloudapi.Config.MetricsDir.
+func (out *Output) startOffline() error {
+	if out.config.APIVersion.Int64 != int64(apiVersion2) {
+		return fmt.Errorf("the metrics can be written to a directory only by the v%d cloud output", apiVersion2)
+	}
+	out.testRunID = offlineTestRunID
+	if out.config.PushRefID.Valid {
+		out.testRunID = out.config.PushRefID.String
+	}
+	// The traces are sent to a separate service, which isn't available offline,
+	// unless they are sent to an OpenTelemetry collector.
+	if out.config.TracesExporter.String != insightsOutput.ExporterOTLP {
+		out.config.TracesEnabled = null.BoolFrom(false)
+	}
+
+	out.logger.WithField("dir", out.config.MetricsDir.String).Debug("Writing the metrics to files instead of the cloud")
+	return out.startVersionedOutput()
+}
+
+// applyCapabilities disables the features of the config that the organization
+// of the test run doesn't have enabled in the cloud, logging every one of them.
+func (out *Output) applyCapabilities(capabilities cloudapi.Capabilities) {
+	// The traces sent to an OpenTelemetry collector don't depend on the cloud.
+	if !capabilities.HasTraces() && out.config.TracesEnabled.Bool &&
+		out.config.TracesExporter.String != insightsOutput.ExporterOTLP {
+		out.logger.Warn("The traces aren't enabled for the organization in the cloud, they won't be sent")
+		out.config.TracesEnabled = null.BoolFrom(false)
+	}
+	if !capabilities.HasMetricsV2() && out.config.APIVersion.Int64 == int64(apiVersion2) {
+		out.logger.Warnf("The v%d metrics aren't enabled for the organization in the cloud, "+
+			"the metrics will be sent with the v%d API", apiVersion2, apiVersion1)
+		out.config.APIVersion = null.IntFrom(int64(apiVersion1))
+	}
+}
+
 // Description returns the URL with the test run results.
 func (out *Output) Description() string {
+	if out.config.MetricsDir.String != "" {
+		return fmt.Sprintf("cloud (metrics written to %s)", out.config.MetricsDir.String)
+	}
 	return fmt.Sprintf("cloud (%s)", cloudapi.URLForResults(out.testRunID, out.config))
 }
 
+// AddMetricSamples sends the annotations as events of the test run, and
+// passes the rest of the samples to the versioned output.
+func (out *Output) AddMetricSamples(sampleContainers []metrics.SampleContainer) {
+	samples := sampleContainers[:0:0]
+	for _, sc := range sampleContainers {
+		annotation, ok := sc.(metrics.Annotation)
+		if !ok {
+			samples = append(samples, sc)
+			continue
+		}
+		out.sendAnnotation(annotation)
+	}
+
+	out.versionedOutput.AddMetricSamples(samples)
+}
+
+// sendAnnotation creates the event for the annotation in the background, for
+// not blocking the caller. The events are only for the test runs in the cloud.
+func (out *Output) sendAnnotation(annotation metrics.Annotation) {
+	if out.testRunID == "" || out.config.MetricsDir.String != "" {
+		return
+	}
+
+	out.eventsWg.Add(1)
+	go func() {
+		defer out.eventsWg.Done()
+		err := out.client.CreateTestRunEvent(out.testRunID, cloudapi.TestRunEvent{
+			Type: cloudapi.TestRunEventAnnotation,
+			Time: annotation.Time,
+			Text: annotation.Text,
+			Tags: annotation.Tags,
+		})
+		if err != nil {
+			out.logger.WithError(err).WithField("text", annotation.Text).Warn("Failed to send an annotation to the cloud")
+		}
+	}()
+}
+
 // SetThresholds receives the thresholds before the output is Start()-ed.
 func (out *Output) SetThresholds(scriptThresholds map[string]metrics.Thresholds) {
 	thresholds := make(map[string][]*metrics.Threshold)
@@ -240,6 +333,7 @@ func (out *Output) Stop() error {
 // all metric samples are emitted, it makes a cloud API call to finish the test
 // run. If testErr was specified, it extracts the RunStatus from it.
 func (out *Output) StopWithTestError(testErr error) error {
+	out.eventsWg.Wait()
 	err := out.versionedOutput.StopWithTestError(testErr)
 	if err != nil {
 		out.logger.WithError(err).Error("An error occurred stopping the output")
@@ -257,7 +351,7 @@ func (out *Output) StopWithTestError(testErr error) error {
 }
 
 func (out *Output) testFinished(testErr error) error {
-	if out.testRunID == "" || out.config.PushRefID.Valid {
+	if out.testRunID == "" || out.config.PushRefID.Valid || out.config.MetricsDir.String != "" {
 		return nil
 	}
 
@@ -349,7 +443,7 @@ func (out *Output) startVersionedOutput() error {
 	case int64(apiVersion1):
 		out.versionedOutput, err = cloudv1.New(out.logger, out.config, out.client)
 	case int64(apiVersion2):
-		out.versionedOutput, err = cloudv2.New(out.logger, out.config, out.client)
+		out.versionedOutput, err = cloudv2.New(out.logger, out.config, out.client, out.fs)
 	default:
 		err = fmt.Errorf("v%d is an unexpected version", out.config.APIVersion.Int64)
 	}
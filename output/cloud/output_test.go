@@ -1,6 +1,7 @@
 package cloud
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.k6.io/k6/cloudapi"
@@ -135,6 +137,56 @@ func TestOutputCreateTestWithConfigOverwrite(t *testing.T) {
 	require.NoError(t, out.StopWithTestError(nil))
 }
 
+func TestOutputCreateTestWithCapabilities(t *testing.T) {
+	t.Parallel()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/tests":
+			fmt.Fprintf(w, `{
+"reference_id": "123",
+"config": {"metricPushInterval": "1h"},
+"capabilities": {"traces": false, "metrics_v2": false}
+}`)
+		case "/v1/tests/123":
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.Error(w, "not expected path", http.StatusInternalServerError)
+		}
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	logger, hook := testutils.NewLoggerWithHook(t, logrus.WarnLevel)
+
+	out, err := newOutput(output.Params{
+		Logger:      logger,
+		Environment: map[string]string{"K6_CLOUD_HOST": ts.URL},
+		ScriptOptions: lib.Options{
+			SystemTags: &metrics.DefaultSystemTagSet,
+		},
+		ScriptPath: &url.URL{Path: "/script.js"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, out.Start())
+
+	assert.False(t, out.config.TracesEnabled.Bool)
+	assert.Equal(t, int64(1), out.config.APIVersion.Int64)
+	_, ok := out.versionedOutput.(*cloudv1.Output)
+	assert.True(t, ok)
+
+	var messages []string
+	for _, e := range hook.Drain() {
+		messages = append(messages, e.Message)
+	}
+	assert.Equal(t, []string{
+		"The traces aren't enabled for the organization in the cloud, they won't be sent",
+		"The v2 metrics aren't enabled for the organization in the cloud, the metrics will be sent with the v1 API",
+	}, messages)
+
+	require.NoError(t, out.StopWithTestError(nil))
+}
+
 func TestOutputStartVersionError(t *testing.T) {
 	t.Parallel()
 	o, err := newOutput(output.Params{
@@ -344,8 +396,50 @@ func TestOutputProxyAddMetricSamples(t *testing.T) {
 	assert.True(t, called)
 }
 
+func TestOutputAddMetricSamplesAnnotations(t *testing.T) {
+	t.Parallel()
+
+	events := make(chan cloudapi.TestRunEvent, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/tests/123/events", r.URL.Path)
+		var event cloudapi.TestRunEvent
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		events <- event
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	var received []metrics.SampleContainer
+	o := &Output{
+		logger:    testutils.NewLogger(t),
+		testRunID: "123",
+		client:    cloudapi.NewClient(testutils.NewLogger(t), "token", ts.URL, "1.0", time.Second),
+		versionedOutput: versionedOutputMock{
+			callback:     func(string) {},
+			addedSamples: func(samples []metrics.SampleContainer) { received = append(received, samples...) },
+		},
+	}
+
+	sample := metrics.Sample{Value: 1}
+	annotationTime := time.Unix(1337, 0).UTC()
+	o.AddMetricSamples([]metrics.SampleContainer{
+		sample,
+		metrics.Annotation{Time: annotationTime, Text: "DB failover started", Tags: map[string]string{"team": "db"}},
+	})
+	o.eventsWg.Wait()
+
+	assert.Equal(t, []metrics.SampleContainer{sample}, received)
+	assert.Equal(t, cloudapi.TestRunEvent{
+		Type: cloudapi.TestRunEventAnnotation,
+		Time: annotationTime,
+		Text: "DB failover started",
+		Tags: map[string]string{"team": "db"},
+	}, <-events)
+}
+
 type versionedOutputMock struct {
-	callback func(name string)
+	callback     func(name string)
+	addedSamples func(samples []metrics.SampleContainer)
 }
 
 func (o versionedOutputMock) Start() error {
@@ -368,4 +462,7 @@ func (o versionedOutputMock) SetTestRunID(id string) {
 
 func (o versionedOutputMock) AddMetricSamples(samples []metrics.SampleContainer) {
 	o.callback("AddMetricSamples")
+	if o.addedSamples != nil {
+		o.addedSamples(samples)
+	}
 }
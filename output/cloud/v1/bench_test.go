@@ -159,6 +159,7 @@ func BenchmarkMetricMarshalGzipAll(b *testing.B) {
 			level := level
 			registry := metrics.NewRegistry()
 			b.Run(fmt.Sprintf("%d_%s", count, name), func(b *testing.B) {
+				b.ReportAllocs()
 				for i := 0; i < b.N; i++ {
 					b.StopTimer()
 
@@ -213,6 +214,31 @@ func BenchmarkMetricMarshalGzipAllWriter(b *testing.B) {
 	}
 }
 
+// BenchmarkMetricEncodeGzip benchmarks the streaming encoding used by
+// PushMetric, to be compared with BenchmarkMetricMarshalGzipAll.
+func BenchmarkMetricEncodeGzip(b *testing.B) {
+	for _, count := range []int{10000, 100000, 500000} {
+		count := count
+		registry := metrics.NewRegistry()
+		b.Run(fmt.Sprintf("%d_bestspeed", count), func(b *testing.B) {
+			s := generateSamples(registry, count)
+			var buf bytes.Buffer
+			g, err := gzip.NewWriterLevel(&buf, gzip.BestSpeed)
+			require.NoError(b, err)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+				g.Reset(&buf)
+				n, err := encodeSamples(g, s)
+				require.NoError(b, err)
+				require.NoError(b, g.Close())
+				b.SetBytes(int64(n))
+			}
+		})
+	}
+}
+
 func generateSamples(registry *metrics.Registry, count int) []*Sample {
 	samples := make([]*Sample, count)
 	now := time.Now()
@@ -315,6 +341,7 @@ func BenchmarkHTTPPush(b *testing.B) {
 		registry := metrics.NewRegistry()
 		b.Run(fmt.Sprintf("count:%d", count), func(b *testing.B) {
 			samples := generateSamples(registry, count)
+			b.ReportAllocs()
 			b.ResetTimer()
 			for s := 0; s < b.N; s++ {
 				b.StopTimer()
@@ -12,12 +12,27 @@ import (
 	"sync"
 	"time"
 
-	easyjson "github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jwriter"
 	"github.com/sirupsen/logrus"
 
 	"go.k6.io/k6/cloudapi"
 )
 
+// encoderFlushSize is the size of the encoded samples after which they are
+// written out of the JSON writer, so the whole JSON payload is never in memory
+// before it's compressed.
+const encoderFlushSize = 64 * 1024
+
+// encoderBufferPool holds the buffers that the samples are encoded into,
+// before they are flushed. They are twice the flush size, so a sample rarely
+// doesn't fit and the JSON writer doesn't have to allocate any chunks.
+var encoderBufferPool = sync.Pool{ //nolint:gochecknoglobals
+	New: func() interface{} {
+		b := make([]byte, 0, 2*encoderFlushSize)
+		return &b
+	},
+}
+
 // MetricsClient is a wrapper around the cloudapi.Client that is also capable of pushing
 type MetricsClient struct {
 	*cloudapi.Client
@@ -26,6 +41,7 @@ type MetricsClient struct {
 	noCompress bool
 
 	pushBufferPool sync.Pool
+	gzipWriterPool sync.Pool
 }
 
 // NewMetricsClient creates and initializes a new MetricsClient.
@@ -40,52 +56,45 @@ func NewMetricsClient(client *cloudapi.Client, logger logrus.FieldLogger, host s
 				return &bytes.Buffer{}
 			},
 		},
+		gzipWriterPool: sync.Pool{
+			New: func() interface{} {
+				g, _ := gzip.NewWriterLevel(nil, gzip.BestSpeed)
+				return g
+			},
+		},
 	}
 }
 
-// PushMetric pushes the provided metric samples for the given referenceID
+// PushMetric pushes the provided metric samples for the given referenceID.
+// The samples are encoded, and compressed, in a single pass into a pooled
+// buffer, so only the compressed payload is held in memory.
 func (mc *MetricsClient) PushMetric(referenceID string, s []*Sample) error {
 	start := time.Now()
 	url := fmt.Sprintf("%s/v1/metrics/%s", mc.host, referenceID)
 
-	jsonStart := time.Now()
-	b, err := easyjson.Marshal(samples(s))
+	buf, ok := mc.pushBufferPool.Get().(*bytes.Buffer)
+	if !ok {
+		return errors.New("failed to convert a buffer pool item into the expected type bytes Buffer")
+	}
+	buf.Reset()
+	defer mc.pushBufferPool.Put(buf)
+
+	encodeStart := time.Now()
+	unzippedSize, gzipTime, err := mc.encodePayload(buf, s)
 	if err != nil {
 		return err
 	}
-	jsonTime := time.Since(jsonStart)
+	jsonTime := time.Since(encodeStart) - gzipTime
 
 	// TODO: change the context, maybe to one with a timeout
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, nil)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewReader(buf.Bytes()))
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("X-Payload-Sample-Count", strconv.Itoa(len(s)))
 	var additionalFields logrus.Fields
-
 	if !mc.noCompress {
-		buf, ok := mc.pushBufferPool.Get().(*bytes.Buffer)
-		if !ok {
-			return errors.New("failed to convert a buffer pool item " +
-				"into the expected type bytes Buffer for gzip compression operation")
-		}
-		buf.Reset()
-		defer mc.pushBufferPool.Put(buf)
-		unzippedSize := len(b)
-		buf.Grow(unzippedSize / expectedGzipRatio)
-		gzipStart := time.Now()
-		{
-			g, _ := gzip.NewWriterLevel(buf, gzip.BestSpeed)
-			if _, err = g.Write(b); err != nil {
-				return err
-			}
-			if err = g.Close(); err != nil {
-				return err
-			}
-		}
-		gzipTime := time.Since(gzipStart)
-
 		req.Header.Set("Content-Encoding", "gzip")
 		req.Header.Set("X-Payload-Byte-Count", strconv.Itoa(unzippedSize))
 
@@ -94,14 +103,6 @@ func (mc *MetricsClient) PushMetric(referenceID string, s []*Sample) error {
 			"gzip_t":         gzipTime,
 			"content_length": buf.Len(),
 		}
-
-		b = buf.Bytes()
-	}
-
-	req.Header.Set("Content-Length", strconv.Itoa(len(b)))
-	req.Body = io.NopCloser(bytes.NewReader(b))
-	req.GetBody = func() (io.ReadCloser, error) {
-		return io.NopCloser(bytes.NewReader(b)), nil
 	}
 
 	err = mc.Client.Do(req, nil)
@@ -114,3 +115,97 @@ func (mc *MetricsClient) PushMetric(referenceID string, s []*Sample) error {
 
 	return err
 }
+
+// encodePayload writes the encoded samples to w, compressing them unless the
+// compression is disabled. It returns the size of the uncompressed JSON and
+// the time that was spent compressing it.
+func (mc *MetricsClient) encodePayload(w io.Writer, s []*Sample) (int, time.Duration, error) {
+	if mc.noCompress {
+		n, err := encodeSamples(w, s)
+		return n, 0, err
+	}
+
+	g, ok := mc.gzipWriterPool.Get().(*gzip.Writer)
+	if !ok {
+		return 0, 0, errors.New("failed to convert a gzip pool item into the expected type gzip Writer")
+	}
+	defer mc.gzipWriterPool.Put(g)
+	g.Reset(w)
+
+	tw := &timedWriter{w: g}
+	n, err := encodeSamples(tw, s)
+	if err != nil {
+		return n, tw.elapsed, err
+	}
+	closeStart := time.Now()
+	err = g.Close()
+	return n, tw.elapsed + time.Since(closeStart), err
+}
+
+// timedWriter measures how long the writes to w take.
+type timedWriter struct {
+	w       io.Writer
+	elapsed time.Duration
+}
+
+func (tw *timedWriter) Write(p []byte) (int, error) {
+	start := time.Now()
+	n, err := tw.w.Write(p)
+	tw.elapsed += time.Since(start)
+	return n, err
+}
+
+// encodeSamples writes the samples to w as a JSON array, encoding them one
+// by one into a pooled buffer and flushing it every encoderFlushSize bytes.
+// It returns the number of the written bytes.
+func encodeSamples(w io.Writer, s []*Sample) (int, error) {
+	buf, ok := encoderBufferPool.Get().(*[]byte)
+	if !ok {
+		return 0, errors.New("failed to convert an encoder pool item into the expected type byte slice")
+	}
+	defer encoderBufferPool.Put(buf)
+
+	var (
+		jw      jwriter.Writer
+		written int
+	)
+	jw.Buffer.Buf = (*buf)[:0]
+	flush := func() error {
+		if jw.Error != nil {
+			return jw.Error
+		}
+		var (
+			n   int
+			err error
+		)
+		if jw.Size() == len(jw.Buffer.Buf) {
+			n, err = w.Write(jw.Buffer.Buf)
+		} else {
+			// a sample didn't fit in the buffer and the writer added chunks
+			n, err = jw.DumpTo(w)
+		}
+		written += n
+		jw.Buffer.Buf = (*buf)[:0]
+		return err
+	}
+
+	jw.RawByte('[')
+	for i, sample := range s {
+		if i > 0 {
+			jw.RawByte(',')
+		}
+		if sample == nil {
+			jw.RawString("null")
+		} else {
+			sample.MarshalEasyJSON(&jw)
+		}
+		if jw.Size() >= encoderFlushSize {
+			if err := flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	jw.RawByte(']')
+
+	return written, flush()
+}
@@ -0,0 +1,95 @@
+package cloud
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	easyjson "github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/metrics"
+)
+
+func TestEncodeSamples(t *testing.T) {
+	t.Parallel()
+
+	for _, count := range []int{0, 1, 1000} {
+		s := generateSamples(metrics.NewRegistry(), count)
+		expected, err := easyjson.Marshal(samples(s))
+		require.NoError(t, err)
+		if count == 0 {
+			expected = []byte("[]")
+		}
+
+		var buf bytes.Buffer
+		n, err := encodeSamples(&buf, s)
+		require.NoError(t, err)
+		assert.Equal(t, len(expected), n)
+		assert.JSONEq(t, string(expected), buf.String())
+	}
+}
+
+func TestEncodeSamplesBiggerThanBuffer(t *testing.T) {
+	t.Parallel()
+
+	s := generateSamples(metrics.NewRegistry(), 10)
+	s[5].Metric = strings.Repeat("m", 3*encoderFlushSize)
+	expected, err := easyjson.Marshal(samples(s))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	n, err := encodeSamples(&buf, s)
+	require.NoError(t, err)
+	assert.Equal(t, len(expected), n)
+	assert.JSONEq(t, string(expected), buf.String())
+}
+
+func TestPushMetricRetry(t *testing.T) {
+	t.Parallel()
+
+	s := generateSamples(metrics.NewRegistry(), 1000)
+	expected, err := easyjson.Marshal(samples(s))
+	require.NoError(t, err)
+
+	var payloads [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, strconv.Itoa(len(expected)), r.Header.Get("X-Payload-Byte-Count"))
+		assert.Equal(t, "1000", r.Header.Get("X-Payload-Sample-Count"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, r.ContentLength, int64(len(body)))
+		g, err := gzip.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		payload, err := io.ReadAll(g) //nolint:gosec
+		require.NoError(t, err)
+		payloads = append(payloads, payload)
+
+		// the first push fails, so the payload is sent again for the retry
+		if len(payloads) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	logger := testutils.NewLogger(t)
+	client := cloudapi.NewClient(logger, "token", server.URL, "1.0", time.Second)
+	mc := NewMetricsClient(client, logger, server.URL, false)
+	require.NoError(t, mc.PushMetric("1", s))
+
+	require.Len(t, payloads, 2)
+	for _, payload := range payloads {
+		assert.JSONEq(t, string(expected), string(payload))
+	}
+}
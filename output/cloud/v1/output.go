@@ -13,7 +13,6 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"go.k6.io/k6/cloudapi"
-	"go.k6.io/k6/cloudapi/insights"
 	"go.k6.io/k6/errext"
 	"go.k6.io/k6/errext/exitcodes"
 	insightsOutput "go.k6.io/k6/output/cloud/insights"
@@ -41,6 +40,8 @@ type Output struct {
 	insightsClient            insightsOutput.Client
 	requestMetadatasCollector insightsOutput.RequestMetadatasCollector
 	requestMetadatasFlusher   insightsOutput.RequestMetadatasFlusher
+	requestMetadatasStats     *insightsOutput.Stats
+	requestMetadatasReady     <-chan struct{}
 
 	// TODO: optimize this
 	//
@@ -114,21 +115,27 @@ func (out *Output) Start() error {
 		if err != nil {
 			return err
 		}
-		out.requestMetadatasCollector = insightsOutput.NewCollector(testRunID)
+		bufferConfig, err := insightsOutput.NewBufferConfig(out.config)
+		if err != nil {
+			return err
+		}
+		out.requestMetadatasStats = &insightsOutput.Stats{}
+		collector := insightsOutput.NewBoundedCollector(testRunID, bufferConfig, out.requestMetadatasStats)
+		out.requestMetadatasCollector = collector
+		out.requestMetadatasReady = collector.BatchReady()
 
-		insightsClientConfig := insights.NewDefaultClientConfigForTestRun(
-			out.config.TracesHost.String,
-			out.config.Token.String,
-			testRunID,
-		)
-		insightsClient := insights.NewClient(insightsClientConfig)
+		insightsClient, err := insightsOutput.NewClient(out.config, testRunID)
+		if err != nil {
+			return err
+		}
 
 		if err := insightsClient.Dial(context.Background()); err != nil {
 			return err
 		}
 
 		out.insightsClient = insightsClient
-		out.requestMetadatasFlusher = insightsOutput.NewFlusher(insightsClient, out.requestMetadatasCollector)
+		out.requestMetadatasFlusher = insightsOutput.NewBatchingFlusher(
+			insightsClient, collector, bufferConfig.MaxBatchBytes, out.requestMetadatasStats)
 		out.runFlushRequestMetadatas()
 	}
 
@@ -171,6 +178,7 @@ func (out *Output) StopWithTestError(testErr error) error {
 		if err := out.insightsClient.Close(); err != nil {
 			out.logger.WithError(err).Error("Failed to close the insights client")
 		}
+		out.requestMetadatasStats.LogSummary(out.logger)
 	}
 	return nil
 }
@@ -533,6 +541,8 @@ func (out *Output) runFlushRequestMetadatas() {
 					return
 				case <-t.C:
 					out.flushRequestMetadatas()
+				case <-out.requestMetadatasReady:
+					out.flushRequestMetadatas()
 				}
 			}
 		}()
@@ -549,7 +559,5 @@ func (out *Output) flushRequestMetadatas() {
 		return
 	}
 
-	out.logger.WithField("t", time.Since(start)).Debug("Successfully flushed buffered trace samples to the cloud")
+	out.requestMetadatasStats.LogFlush(out.logger.WithField("t", time.Since(start)))
 }
-
-const expectedGzipRatio = 6 // based on test it is around 6.8, but we don't need to be that accurate
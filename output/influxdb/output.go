@@ -29,6 +29,10 @@ const (
 	Bool
 )
 
+// annotationsMeasurement is the measurement of the annotations, with their
+// text in the text field, for using them as Grafana annotations.
+const annotationsMeasurement = "annotations"
+
 // Output is the influxdb Output struct
 type Output struct {
 	output.SampleBuffer
@@ -116,6 +120,21 @@ func (o *Output) batchFromSamples(containers []metrics.SampleContainer) (client.
 	}
 	cache := map[*metrics.TagSet]cacheItem{}
 	for _, container := range containers {
+		if annotation, ok := container.(metrics.Annotation); ok {
+			var p *client.Point
+			p, err = client.NewPoint(
+				annotationsMeasurement,
+				annotation.Tags,
+				map[string]interface{}{"text": annotation.Text},
+				annotation.Time,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't make point from annotation: %w", err)
+			}
+			batch.AddPoint(p)
+			continue
+		}
+
 		samples := container.GetSamples()
 		for _, sample := range samples {
 			var tags map[string]string
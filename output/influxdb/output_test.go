@@ -193,6 +193,29 @@ func TestOutputFlushMetricsConcurrency(t *testing.T) {
 	assert.Equal(t, 4, int(atomic.LoadInt32(&requests)))
 }
 
+func TestBatchFromSamplesAnnotation(t *testing.T) {
+	t.Parallel()
+
+	o, err := newOutput(output.Params{Logger: testutils.NewLogger(t)})
+	require.NoError(t, err)
+
+	batch, err := o.batchFromSamples([]metrics.SampleContainer{metrics.Annotation{
+		Time: time.Unix(1337, 0),
+		Text: "DB failover started",
+		Tags: map[string]string{"team": "db"},
+	}})
+	require.NoError(t, err)
+	require.Len(t, batch.Points(), 1)
+
+	p := batch.Points()[0]
+	assert.Equal(t, "annotations", p.Name())
+	assert.Equal(t, map[string]string{"team": "db"}, p.Tags())
+	fields, err := p.Fields()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"text": "DB failover started"}, fields)
+	assert.Equal(t, time.Unix(1337, 0), p.Time())
+}
+
 func TestExtractTagsToValues(t *testing.T) {
 	t.Parallel()
 	o, err := newOutput(output.Params{
@@ -125,6 +125,11 @@ func (o *Output) flushMetrics() {
 	var count int
 	jw := new(jwriter.Writer)
 	for _, sc := range samples {
+		if annotation, ok := sc.(metrics.Annotation); ok {
+			wrapAnnotation(annotation).MarshalEasyJSON(jw)
+			jw.RawByte('\n')
+			continue
+		}
 		samples := sc.GetSamples()
 		count += len(samples)
 		for _, sample := range samples {
@@ -206,6 +206,29 @@ func TestJsonOutputFileGzipped(t *testing.T) {
 	assert.NoError(t, file.Close())
 }
 
+func TestJsonOutputAnnotation(t *testing.T) {
+	t.Parallel()
+
+	stdout := new(bytes.Buffer)
+	out, err := New(output.Params{
+		Logger: testutils.NewLogger(t),
+		StdOut: stdout,
+	})
+	require.NoError(t, err)
+	require.NoError(t, out.Start())
+
+	out.AddMetricSamples([]metrics.SampleContainer{metrics.Annotation{
+		Time: time.Date(2021, time.February, 24, 13, 37, 10, 0, time.UTC),
+		Text: "DB failover started",
+		Tags: map[string]string{"team": "db"},
+	}})
+	require.NoError(t, out.Stop())
+
+	getValidator(t, []string{
+		`{"type":"Annotation","data":{"time":"2021-02-24T13:37:10Z","text":"DB failover started","tags":{"team":"db"}}}`,
+	})(stdout)
+}
+
 func TestWrapSampleWithSamplePointer(t *testing.T) {
 	t.Parallel()
 	out := wrapSample(metrics.Sample{
@@ -46,3 +46,23 @@ type metricEnvelope struct {
 	} `json:"data"`
 	Metric string `json:"metric"`
 }
+
+//easyjson:json
+type annotationEnvelope struct {
+	Type string `json:"type"`
+	Data struct {
+		Time time.Time         `json:"time"`
+		Text string            `json:"text"`
+		Tags map[string]string `json:"tags,omitempty"`
+	} `json:"data"`
+}
+
+// wrapAnnotation is used to package an annotation in a line of its own type,
+// which is ignored by the consumers of the metric samples.
+func wrapAnnotation(annotation metrics.Annotation) annotationEnvelope {
+	a := annotationEnvelope{Type: "Annotation"}
+	a.Data.Time = annotation.Time
+	a.Data.Text = annotation.Text
+	a.Data.Tags = annotation.Tags
+	return a
+}